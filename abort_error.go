@@ -0,0 +1,53 @@
+package chain
+
+import (
+	"errors"
+	"fmt"
+)
+
+// AbortError is an error aborting the pipeline with a machine-readable Code and a Reason,
+// optionally wrapping the error which caused it.
+// An action failing with an AbortError makes the pipeline take Abort, even though it directs Error.
+// Pipelines return the errors of their actions as they are, so the AbortError of an action
+// in a nested pipeline is still found by errors.As on the error of the outermost pipeline.
+type AbortError struct {
+	Code   string
+	Reason string
+	Err    error
+}
+
+// NewAbortError creates an AbortError with the code and the reason.
+func NewAbortError(code, reason string) *AbortError {
+	return &AbortError{Code: code, Reason: reason}
+}
+
+func (e *AbortError) Error() string {
+	message := fmt.Sprintf("aborted with `%s`: %s", e.Code, e.Reason)
+	if e.Err != nil {
+		message += ": " + e.Err.Error()
+	}
+	return message
+}
+
+func (e *AbortError) Unwrap() error { return e.Err }
+
+// Direction makes the pipeline take Abort on an AbortError, as a DirectedError.
+func (e *AbortError) Direction() string { return Abort }
+
+// IsAbort reports whether the error wraps an AbortError.
+func IsAbort(err error) bool {
+	var abortErr *AbortError
+	return errors.As(err, &abortErr)
+}
+
+// abortCodeOf returns the code of the AbortError the error wraps, or an empty string if none.
+func abortCodeOf(err error) string {
+	if err == nil {
+		return ""
+	}
+	var abortErr *AbortError
+	if errors.As(err, &abortErr) {
+		return abortErr.Code
+	}
+	return ""
+}
@@ -0,0 +1,55 @@
+package chain
+
+import (
+	"context"
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestAbortError(t *testing.T) {
+	ctx := context.Background()
+	cause := errors.New("card expired")
+	declineCard := NewSimpleAction("DeclineCard", func(_ context.Context, input int) (int, error) {
+		abortErr := NewAbortError("card_declined", "payment was declined")
+		abortErr.Err = cause
+		return input, abortErr
+	})
+
+	t.Run("abort direction on error", func(t *testing.T) {
+		refund := &DirectingAction{name: "Refund"}
+		notify := &DirectingAction{name: "Notify"}
+		pipeline := NewPipeline("Payment", declineCard, refund, notify)
+		pipeline.SetRunPlan(declineCard, ActionPlan[int]{Error: refund, Abort: notify})
+
+		result := pipeline.RunWithTrace(ctx, 1)
+
+		assert.Equal(t, Abort, result.Trace.Steps[0].Direction)
+		assert.Equal(t, "Notify", result.Trace.Steps[1].Action)
+		assert.Equal(t, "card_declined", result.AbortCode)
+		assert.EqualError(t, result.Err, "aborted with `card_declined`: payment was declined: card expired")
+		assert.ErrorIs(t, result.Err, cause)
+	})
+
+	t.Run("preserved across nested pipelines", func(t *testing.T) {
+		inner := NewPipeline("Inner", declineCard)
+		middle := NewPipeline("Middle", Action[int](inner))
+		outer := NewPipeline("Outer", Action[int](middle), &SetTen{})
+
+		result := outer.RunWithTrace(ctx, 1)
+
+		assert.True(t, IsAbort(result.Err))
+		var abortErr *AbortError
+		assert.True(t, errors.As(result.Err, &abortErr))
+		assert.Equal(t, "card_declined", abortErr.Code)
+		assert.Equal(t, "payment was declined", abortErr.Reason)
+		assert.Equal(t, Abort, result.Direction)
+		assert.Len(t, result.Trace.Steps, 1)
+	})
+
+	t.Run("other errors", func(t *testing.T) {
+		assert.False(t, IsAbort(errors.New("plain")))
+		assert.False(t, IsAbort(nil))
+		assert.Empty(t, abortCodeOf(errors.New("plain")))
+	})
+}
@@ -18,6 +18,8 @@ type Action[T any] interface {
 // It signals that no further actions will be executed.
 //
 // Use in ActionPlan to clearly indicate termination intent.
+// As the nil Action, it is the same value on every call without allocating,
+// so comparing actions with it by == is the contract to rely on.
 func Terminate[T any]() Action[T] {
 	return nil
 }
@@ -0,0 +1,26 @@
+package chain
+
+import (
+	"github.com/stretchr/testify/assert"
+	"sync"
+	"testing"
+)
+
+func TestTerminate(t *testing.T) {
+	terminates := make([]Action[int], 8)
+	var wg sync.WaitGroup
+	for i := range terminates {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			terminates[i] = Terminate[int]()
+		}(i)
+	}
+	wg.Wait()
+
+	for _, terminate := range terminates {
+		assert.True(t, terminate == Terminate[int]())
+	}
+	assert.False(t, Action[int](&SetTen{}) == Terminate[int]())
+	assert.Zero(t, testing.AllocsPerRun(10, func() { _ = Terminate[string]() }))
+}
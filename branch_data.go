@@ -0,0 +1,51 @@
+package chain
+
+import (
+	"context"
+	"errors"
+)
+
+// branchHandoff carries branch data between a step and the next one.
+// It is given to each step on the runFrame of its ctx, holding the data received from
+// the previous step, and the data attached by the step itself.
+type branchHandoff struct {
+	received any
+	attached any
+}
+
+func handoffOf(ctx context.Context) *branchHandoff {
+	frame, _ := ctx.Value(runFrameKey).(*runFrame)
+	if frame == nil {
+		return nil
+	}
+	return &frame.handoff
+}
+
+// WithBranchData attaches data to the direction the running action directs, for the data only
+// the next action cares about, such as the suggested delay for a "Retryable" branch,
+// without adding it to the payload. Only the next action receives the data with BranchData,
+// and the data is dropped when the run terminates. Attaching again replaces the data.
+// When the next action is a nested pipeline, the first action of it receives the data.
+//
+// It should be called with ctx given to Run, before the action returns.
+// An error is returned when ctx does not belong to a step of a pipeline.
+func WithBranchData(ctx context.Context, data any) error {
+	handoff := handoffOf(ctx)
+	if handoff == nil {
+		return errors.New("cannot attach branch data outside of pipeline")
+	}
+	handoff.attached = data
+	return nil
+}
+
+// BranchData reads the data attached by the previous action with WithBranchData from ctx given to Run.
+// It returns false when the previous action attached no data, or the data is not a V.
+func BranchData[V any](ctx context.Context) (V, bool) {
+	handoff := handoffOf(ctx)
+	if handoff == nil {
+		var zero V
+		return zero, false
+	}
+	data, isV := handoff.received.(V)
+	return data, isV
+}
@@ -0,0 +1,73 @@
+package chain
+
+import (
+	"context"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestBranchData(t *testing.T) {
+	ctx := context.Background()
+	newAttaching := func(data any) BranchAction[int] {
+		return NewSimpleBranchAction("Attaching",
+			func(ctx context.Context, input int) (int, error) {
+				return input, WithBranchData(ctx, data)
+			},
+			[]string{"retryable"},
+			func(context.Context, int) (string, error) { return "retryable", nil },
+		)
+	}
+	newReading := func(name string) Action[int] {
+		return NewSimpleAction(name, func(ctx context.Context, input int) (int, error) {
+			if delay, exists := BranchData[int](ctx); exists {
+				return input + delay, nil
+			}
+			return input, nil
+		})
+	}
+
+	t.Run("hands data to next action only", func(t *testing.T) {
+		attaching, reading, after := newAttaching(30), newReading("Reading"), newReading("After")
+		pipeline := NewPipeline("Pipeline", attaching, reading, after)
+		pipeline.SetRunPlan(attaching, ActionPlan[int]{"retryable": reading})
+
+		result := pipeline.RunWithTrace(ctx, 1)
+
+		assert.NoError(t, result.Err)
+		assert.Equal(t, 31, result.Output)
+		assert.Equal(t, []StepOutcome{
+			{Action: "Attaching", Direction: "retryable", BranchData: 30},
+			{Action: "Reading", Direction: Success},
+			{Action: "After", Direction: Success},
+		}, result.Trace.Steps)
+	})
+
+	t.Run("ignores data of other types", func(t *testing.T) {
+		attaching, reading := newAttaching("30s"), newReading("Reading")
+		pipeline := NewPipeline("Pipeline", attaching, reading)
+		pipeline.SetRunPlan(attaching, ActionPlan[int]{"retryable": reading})
+
+		output, err := pipeline.Run(ctx, 1)
+
+		assert.NoError(t, err)
+		assert.Equal(t, 1, output)
+	})
+
+	t.Run("hands data to first action of nested pipeline", func(t *testing.T) {
+		attaching := newAttaching(30)
+		nested := NewPipeline("Nested", newReading("Reading"))
+		pipeline := NewPipeline("Pipeline", attaching, nested)
+		pipeline.SetRunPlan(attaching, ActionPlan[int]{"retryable": nested})
+
+		output, err := pipeline.Run(ctx, 1)
+
+		assert.NoError(t, err)
+		assert.Equal(t, 31, output)
+	})
+
+	t.Run("fails outside of pipeline", func(t *testing.T) {
+		assert.EqualError(t, WithBranchData(ctx, 30), "cannot attach branch data outside of pipeline")
+		_, exists := BranchData[int](ctx)
+		assert.False(t, exists)
+	})
+}
@@ -0,0 +1,52 @@
+package chain
+
+import (
+	"context"
+	"errors"
+)
+
+// CanceledError is the error of a run stopped by the cancellation of its ctx, telling the run
+// was told to stop rather than failed. Err reports the cancellation, wrapping context.Canceled or
+// context.DeadlineExceeded, and Cause is the cause of the cancellation given by context.Cause.
+// errors.Is sees through both of them.
+//
+// The pipeline aborts with a CanceledError when ctx is done before the next action,
+// or when an action fails with the error of ctx. As a DirectedError directing Abort,
+// nested pipelines stopped by the cancellation make their parents abort as well.
+type CanceledError struct {
+	Err   error
+	Cause error
+}
+
+func (e *CanceledError) Error() string {
+	if e.Cause == nil || errors.Is(e.Err, e.Cause) {
+		return e.Err.Error()
+	}
+	return e.Err.Error() + ": " + e.Cause.Error()
+}
+
+func (e *CanceledError) Unwrap() []error {
+	if e.Cause == nil {
+		return []error{e.Err}
+	}
+	return []error{e.Err, e.Cause}
+}
+
+// Direction makes the pipeline take Abort on a CanceledError, as a DirectedError.
+func (e *CanceledError) Direction() string { return Abort }
+
+// canceledErrorOf wraps err caused by the cancellation of ctx in a CanceledError,
+// unless it already wraps one.
+func canceledErrorOf(ctx context.Context, err error) error {
+	var canceledErr *CanceledError
+	if errors.As(err, &canceledErr) {
+		return err
+	}
+	return &CanceledError{Err: err, Cause: context.Cause(ctx)}
+}
+
+// isCanceledBy reports whether err is caused by the cancellation of ctx.
+func isCanceledBy(ctx context.Context, err error) bool {
+	ctxErr := ctx.Err()
+	return ctxErr != nil && errors.Is(err, ctxErr)
+}
@@ -0,0 +1,78 @@
+package chain
+
+import (
+	"context"
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestCanceledError(t *testing.T) {
+	errUserLeft := errors.New("user left")
+	newCanceling := func(cancel context.CancelCauseFunc, returnsCtxErr bool) Action[int] {
+		return NewSimpleAction("Canceling", func(ctx context.Context, input int) (int, error) {
+			cancel(errUserLeft)
+			if returnsCtxErr {
+				return input, ctx.Err()
+			}
+			return input + 1, nil
+		})
+	}
+
+	t.Run("aborts between steps with cause", func(t *testing.T) {
+		ctx, cancel := context.WithCancelCause(context.Background())
+		pipeline := NewPipeline("Pipeline", newCanceling(cancel, false), &SetTen{})
+
+		output, direction, err := pipeline.run(ctx, 1)
+
+		assert.ErrorIs(t, err, context.Canceled)
+		assert.ErrorIs(t, err, errUserLeft)
+		assert.EqualError(t, err, "context canceled: user left")
+		var canceledErr *CanceledError
+		assert.True(t, errors.As(err, &canceledErr))
+		assert.Equal(t, errUserLeft, canceledErr.Cause)
+		assert.Equal(t, Abort, direction)
+		assert.Equal(t, 2, output)
+	})
+
+	t.Run("aborts on error of ctx from action", func(t *testing.T) {
+		ctx, cancel := context.WithCancelCause(context.Background())
+		canceling, fallback := newCanceling(cancel, true), &SetTen{}
+		pipeline := NewPipeline("Pipeline", canceling, fallback)
+		pipeline.SetRunPlan(canceling, ActionPlan[int]{Error: fallback})
+
+		result := pipeline.RunWithTrace(ctx, 1)
+
+		assert.ErrorIs(t, result.Err, context.Canceled)
+		assert.ErrorIs(t, result.Err, errUserLeft)
+		assert.Equal(t, Abort, result.Direction)
+		assert.Len(t, result.Trace.Steps, 1)
+		assert.Equal(t, Abort, result.Trace.Steps[0].Direction)
+	})
+
+	t.Run("carries cause out of nested pipelines", func(t *testing.T) {
+		ctx, cancel := context.WithCancelCause(context.Background())
+		inner := NewPipeline("Inner", newCanceling(cancel, false), &SetTen{})
+		fallback := &SetTen{}
+		outer := NewPipeline("Outer", inner, fallback)
+		outer.SetRunPlan(inner, ActionPlan[int]{Error: fallback})
+
+		result := outer.RunWithTrace(ctx, 1)
+
+		assert.ErrorIs(t, result.Err, errUserLeft)
+		assert.EqualError(t, result.Err, "context canceled: user left")
+		assert.Equal(t, Abort, result.Direction)
+		assert.Equal(t, []StepOutcome{{Action: "Inner", Direction: Abort, Err: result.Trace.Steps[0].Err, Class: ErrorClassPermanent}}, result.Trace.Steps)
+	})
+
+	t.Run("deadline without cause", func(t *testing.T) {
+		ctx, cancel := context.WithTimeout(context.Background(), 0)
+		defer cancel()
+		pipeline := NewPipeline("Pipeline", &SetTen{})
+
+		_, err := pipeline.Run(ctx, 1)
+
+		assert.ErrorIs(t, err, context.DeadlineExceeded)
+		assert.EqualError(t, err, "context deadline exceeded")
+	})
+}
@@ -0,0 +1,137 @@
+// Package chaintest provides helpers for benchmarking pipelines, for comparing the designs of pipelines
+// such as nesting them or flattening them, and for watching the cost of the engine itself
+// with the reference pipelines.
+package chaintest
+
+import (
+	"context"
+	"fmt"
+	"github.com/JSYoo5B/chain"
+	"runtime"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// BenchOption customizes the benchmarks of Bench.
+type BenchOption func(*benchConfig)
+
+type benchConfig struct {
+	parallelism []int
+}
+
+// WithParallelism makes Bench run the parallel benchmarks with each number of goroutines,
+// instead of GOMAXPROCS goroutines.
+func WithParallelism(goroutines ...int) BenchOption {
+	return func(c *benchConfig) { c.parallelism = goroutines }
+}
+
+// Bench runs the standard benchmarks of the pipeline as sub-benchmarks of b, with the inputs made by input
+// for each iteration, reporting allocations on all of them:
+//
+//   - Sequential runs the pipeline one run after another.
+//   - Parallel-N runs the pipeline on N goroutines at once.
+//   - Direct calls the actions the pipeline runs for the first input in the same order, without the engine.
+//   - Overhead runs the pipeline and calls the actions directly in turn, reporting the time the engine adds
+//     to each step as overhead-ns/step, and the allocations it adds to each run as overhead-allocs/run.
+//
+// Member pipelines are called as they are by Direct, so the overhead only covers the pipeline itself.
+func Bench[T any](b *testing.B, p *chain.Pipeline[T], input func(i int) T, opts ...BenchOption) {
+	config := benchConfig{parallelism: []int{runtime.GOMAXPROCS(0)}}
+	for _, opt := range opts {
+		opt(&config)
+	}
+
+	ctx := context.Background()
+	steps, err := stepsOf(ctx, p, input(0))
+	if err != nil {
+		b.Fatal(err)
+	}
+
+	b.Run("Sequential", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			_, _ = p.Run(ctx, input(i))
+		}
+	})
+
+	for _, goroutines := range config.parallelism {
+		b.Run(fmt.Sprintf("Parallel-%d", goroutines), func(b *testing.B) {
+			b.ReportAllocs()
+			runInParallel(b.N, goroutines, func(i int) {
+				_, _ = p.Run(ctx, input(i))
+			})
+		})
+	}
+
+	b.Run("Direct", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			callDirectly(ctx, steps, input(i))
+		}
+	})
+
+	b.Run("Overhead", func(b *testing.B) {
+		var engine, direct time.Duration
+		for i := 0; i < b.N; i++ {
+			in := input(i)
+			started := time.Now()
+			_, _ = p.Run(ctx, in)
+			engine += time.Since(started)
+
+			started = time.Now()
+			callDirectly(ctx, steps, in)
+			direct += time.Since(started)
+		}
+
+		if len(steps) > 0 {
+			b.ReportMetric(float64(engine-direct)/float64(b.N*len(steps)), "overhead-ns/step")
+		}
+		in := input(0)
+		engineAllocs := testing.AllocsPerRun(100, func() { _, _ = p.Run(ctx, in) })
+		directAllocs := testing.AllocsPerRun(100, func() { callDirectly(ctx, steps, in) })
+		b.ReportMetric(engineAllocs-directAllocs, "overhead-allocs/run")
+	})
+}
+
+// stepsOf finds the actions a run of the pipeline with the input takes, in order.
+func stepsOf[T any](ctx context.Context, p *chain.Pipeline[T], input T) ([]chain.Action[T], error) {
+	result := p.RunWithTrace(ctx, input)
+	steps := make([]chain.Action[T], 0, len(result.Trace.Steps))
+	for _, step := range result.Trace.Steps {
+		action, exists := p.ActionByName(step.Action)
+		if !exists {
+			return nil, fmt.Errorf("`%s` is not a member of `%s`", step.Action, p.Name())
+		}
+		steps = append(steps, action)
+	}
+	return steps, nil
+}
+
+// callDirectly calls the actions in order as the engine does, passing the output of each to the next.
+func callDirectly[T any](ctx context.Context, steps []chain.Action[T], input T) {
+	for _, action := range steps {
+		output, err := action.Run(ctx, input)
+		if branchAction, isBranchAction := action.(chain.BranchAction[T]); isBranchAction && err == nil {
+			_, _ = branchAction.NextDirection(ctx, output)
+		}
+		input = output
+	}
+}
+
+// runInParallel calls fn n times in total on the goroutines.
+func runInParallel(n, goroutines int, fn func(i int)) {
+	var next atomic.Int64
+	var wg sync.WaitGroup
+	for g := 0; g < goroutines; g++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := int(next.Add(1)) - 1; i < n; i = int(next.Add(1)) - 1 {
+				fn(i)
+			}
+		}()
+	}
+	wg.Wait()
+}
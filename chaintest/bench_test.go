@@ -0,0 +1,74 @@
+package chaintest
+
+import (
+	"context"
+	"github.com/JSYoo5B/chain"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestReferencePipelines(t *testing.T) {
+	ctx := context.Background()
+	testCases := map[string]struct {
+		pipeline *chain.Pipeline[int]
+		input    int
+		output   int
+		steps    int
+	}{
+		"linear":         {pipeline: Linear5(), input: 0, output: 5, steps: 5},
+		"branching even": {pipeline: Branching10(), input: 0, output: 4, steps: 5},
+		"branching odd":  {pipeline: Branching10(), input: 1, output: 6, steps: 6},
+		"nested even":    {pipeline: Nested3x3(), input: 0, output: 9, steps: 3},
+		"nested odd":     {pipeline: Nested3x3(), input: 1, output: 10, steps: 3},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			result := tc.pipeline.RunWithTrace(ctx, tc.input)
+
+			assert.NoError(t, result.Err)
+			assert.Equal(t, tc.output, result.Output)
+			assert.Len(t, result.Trace.Steps, tc.steps)
+			assert.NoError(t, tc.pipeline.ValidateGraph())
+		})
+	}
+}
+
+func TestCallDirectly(t *testing.T) {
+	ctx := context.Background()
+	pipeline := Branching10()
+
+	steps, err := stepsOf(ctx, pipeline, 1)
+	assert.NoError(t, err)
+	assert.Len(t, steps, 6)
+	assert.Equal(t, "Parity", steps[0].Name())
+
+	var outputs []int
+	tapped := append(steps[:len(steps):len(steps)], chain.NewSimpleAction("Tap", func(_ context.Context, input int) (int, error) {
+		outputs = append(outputs, input)
+		return input, nil
+	}))
+	callDirectly(ctx, tapped, 1)
+	assert.Equal(t, []int{6}, outputs)
+}
+
+func TestRunInParallel(t *testing.T) {
+	calls := make([]int, 100)
+
+	runInParallel(len(calls), 4, func(i int) { calls[i]++ })
+
+	for i, count := range calls {
+		assert.Equal(t, 1, count, "call %d", i)
+	}
+}
+
+// Benchmarks of the reference pipelines, for watching the cost of the engine release to release.
+func BenchmarkReference(b *testing.B) {
+	logrus.SetLevel(logrus.InfoLevel)
+	input := func(i int) int { return i }
+
+	b.Run("Linear5", func(b *testing.B) { Bench(b, Linear5(), input) })
+	b.Run("Branching10", func(b *testing.B) { Bench(b, Branching10(), input) })
+	b.Run("Nested3x3", func(b *testing.B) { Bench(b, Nested3x3(), input) })
+}
@@ -0,0 +1,52 @@
+package chaintest
+
+import (
+	"context"
+	"fmt"
+	"github.com/JSYoo5B/chain"
+)
+
+// Linear5 makes the reference pipeline running 5 actions in a row, each adding 1 to the input.
+func Linear5() *chain.Pipeline[int] {
+	return chain.NewPipeline("Linear5", increments("Linear5", 5)...)
+}
+
+// Branching10 makes the reference pipeline of 10 actions, where the first one branches
+// to a row of 4 actions for even inputs, and to another row of 5 actions for odd inputs.
+func Branching10() *chain.Pipeline[int] {
+	parity := chain.NewSimpleBranchAction[int]("Parity", nil, []string{"even", "odd"},
+		func(_ context.Context, output int) (string, error) {
+			if output%2 == 0 {
+				return "even", nil
+			}
+			return "odd", nil
+		})
+	even, odd := increments("Even", 4), increments("Odd", 5)
+
+	members := append(append([]chain.Action[int]{parity}, even...), odd...)
+	pipeline := chain.NewPipeline("Branching10", members...)
+	pipeline.SetRunPlan(parity, chain.ActionPlan[int]{"even": even[0], "odd": odd[0]})
+	pipeline.SetRunPlan(even[len(even)-1], chain.TerminationPlan[int]())
+	return pipeline
+}
+
+// Nested3x3 makes the reference pipeline running 3 nested pipelines in a row,
+// each running 3 actions in a row adding 1 to the input.
+func Nested3x3() *chain.Pipeline[int] {
+	nested := make([]chain.Action[int], 3)
+	for i := range nested {
+		name := fmt.Sprintf("Nested%d", i+1)
+		nested[i] = chain.NewPipeline(name, increments(name, 3)...)
+	}
+	return chain.NewPipeline("Nested3x3", nested...)
+}
+
+func increments(prefix string, n int) []chain.Action[int] {
+	actions := make([]chain.Action[int], n)
+	for i := range actions {
+		actions[i] = chain.NewSimpleAction(fmt.Sprintf("%s-%d", prefix, i+1), func(_ context.Context, input int) (int, error) {
+			return input + 1, nil
+		})
+	}
+	return actions
+}
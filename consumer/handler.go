@@ -0,0 +1,124 @@
+// Package consumer adapts pipelines to consume messages from a queue.
+// A Handler decodes each message, runs it through a chain.Pipeline,
+// and settles the message as its AckPolicy decides on the outcome of the run.
+// It does not depend on any queue client, which is adapted by implementing Message.
+package consumer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/JSYoo5B/chain"
+	"github.com/sirupsen/logrus"
+)
+
+// Decision is how a message is settled after being handled.
+type Decision int
+
+const (
+	// Pending is a message not settled yet.
+	Pending Decision = iota
+	// Ack acknowledges the message as processed.
+	Ack
+	// Requeue returns the message to the queue, to be handled again.
+	Requeue
+	// DeadLetter sends the message to the dead letter queue.
+	DeadLetter
+)
+
+func (d Decision) String() string {
+	switch d {
+	case Pending:
+		return "pending"
+	case Ack:
+		return "ack"
+	case Requeue:
+		return "requeue"
+	case DeadLetter:
+		return "dead letter"
+	default:
+		return fmt.Sprintf("Decision(%d)", int(d))
+	}
+}
+
+// AckPolicy decides how to settle a message from the direction the pipeline terminated with,
+// and the error of the run. Messages failed to decode are given to the policy with chain.Abort
+// and a *DecodeError, as the pipeline never ran for them.
+type AckPolicy func(direction string, err error) Decision
+
+// DefaultAckPolicy acknowledges messages the pipeline ran without an error,
+// sends the ones failed to decode or aborted to the dead letter queue,
+// and requeues the rest to be retried, including the ones stopped by the cancellation of ctx
+// with a *chain.CanceledError, as they were told to stop rather than failed.
+func DefaultAckPolicy(direction string, err error) Decision {
+	var canceledErr *chain.CanceledError
+	switch {
+	case err == nil:
+		return Ack
+	case errors.As(err, &canceledErr):
+		return Requeue
+	case direction == chain.Abort:
+		return DeadLetter
+	default:
+		return Requeue
+	}
+}
+
+// DecodeError reports a message which could not be decoded for the pipeline.
+type DecodeError struct {
+	Err error
+}
+
+func (e *DecodeError) Error() string { return "decoding message: " + e.Err.Error() }
+
+func (e *DecodeError) Unwrap() error { return e.Err }
+
+// Handler creates a function handling a message by decoding it with decode,
+// running it through the pipeline, and settling it as the policy decides.
+// The function is meant to be called from the receiving loop of the queue client,
+// and returns the error of settling the message.
+//
+// A panic on decode is handled as a failure to decode. Panics on the actions of the pipeline
+// are recovered by the pipeline, and given to the policy with chain.Abort.
+// When the policy is nil, DefaultAckPolicy is used.
+func Handler[T any](p *chain.Pipeline[T], decode func(msg Message) (T, error), policy AckPolicy) func(ctx context.Context, msg Message) error {
+	if policy == nil {
+		policy = DefaultAckPolicy
+	}
+
+	return func(ctx context.Context, msg Message) error {
+		var (
+			direction string
+			err       error
+		)
+		if input, decodeErr := safeDecode(decode, msg); decodeErr != nil {
+			direction, err = chain.Abort, &DecodeError{Err: decodeErr}
+		} else {
+			result := p.RunWithTrace(ctx, input)
+			direction, err = result.Direction, result.Err
+		}
+
+		decision := policy(direction, err)
+		logrus.Debugf("%s: Settling message as %s, ended with `%s`", p.Name(), decision, direction)
+		switch decision {
+		case Ack:
+			return msg.Ack()
+		case Requeue:
+			return msg.Nack(true)
+		case DeadLetter:
+			return msg.Nack(false)
+		default:
+			return fmt.Errorf("policy decided unknown decision %s", decision)
+		}
+	}
+}
+
+func safeDecode[T any](decode func(msg Message) (T, error), msg Message) (input T, err error) {
+	defer func() {
+		if panicErr := recover(); panicErr != nil {
+			err = fmt.Errorf("panic occurred on decoding, caused by %v", panicErr)
+		}
+	}()
+
+	return decode(msg)
+}
@@ -0,0 +1,88 @@
+package consumer
+
+import (
+	"context"
+	"errors"
+	"github.com/JSYoo5B/chain"
+	"github.com/stretchr/testify/assert"
+	"strconv"
+	"testing"
+)
+
+func TestHandler(t *testing.T) {
+	halve := chain.NewSimpleAction("Halve", func(_ context.Context, input int) (int, error) {
+		switch {
+		case input < 0:
+			panic("negative input")
+		case input%2 != 0:
+			return input, errors.New("odd input")
+		}
+		return input / 2, nil
+	})
+	pipeline := chain.NewPipeline("Halving", halve)
+	decode := func(msg Message) (int, error) {
+		if string(msg.Body()) == "panic" {
+			panic("broken decoder")
+		}
+		return strconv.Atoi(string(msg.Body()))
+	}
+
+	type testCase struct {
+		body     string
+		decision Decision
+	}
+	testCases := map[string]testCase{
+		"acknowledge success":         {body: "4", decision: Ack},
+		"requeue error":               {body: "3", decision: Requeue},
+		"dead letter panic on action": {body: "-2", decision: DeadLetter},
+		"dead letter decode failure":  {body: "four", decision: DeadLetter},
+		"dead letter decode panic":    {body: "panic", decision: DeadLetter},
+	}
+	handle := Handler(pipeline, decode, nil)
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			msg := NewInMemoryMessage([]byte(tc.body))
+
+			err := handle(context.Background(), msg)
+
+			assert.NoError(t, err)
+			assert.Equal(t, tc.decision, msg.Decision())
+		})
+	}
+
+	t.Run("custom policy", func(t *testing.T) {
+		var decodeErr *DecodeError
+		policy := func(direction string, err error) Decision {
+			if errors.As(err, &decodeErr) {
+				return Ack
+			}
+			return DefaultAckPolicy(direction, err)
+		}
+		msg := NewInMemoryMessage([]byte("four"))
+
+		err := Handler(pipeline, decode, policy)(context.Background(), msg)
+
+		assert.NoError(t, err)
+		assert.Equal(t, Ack, msg.Decision())
+		assert.EqualError(t, decodeErr, `decoding message: strconv.Atoi: parsing "four": invalid syntax`)
+	})
+
+	t.Run("requeue cancellation", func(t *testing.T) {
+		ctx, cancel := context.WithCancelCause(context.Background())
+		cancel(errors.New("shutting down"))
+		msg := NewInMemoryMessage([]byte("4"))
+
+		err := handle(ctx, msg)
+
+		assert.NoError(t, err)
+		assert.Equal(t, Requeue, msg.Decision())
+	})
+
+	t.Run("settling twice", func(t *testing.T) {
+		msg := NewInMemoryMessage([]byte("4"))
+
+		assert.NoError(t, msg.Ack())
+		assert.EqualError(t, msg.Nack(true), "message is already settled")
+		assert.Equal(t, Ack, msg.Decision())
+	})
+}
@@ -0,0 +1,63 @@
+package consumer
+
+import (
+	"errors"
+	"sync"
+)
+
+// Message is a message received from a queue, adapted from the client of the queue in use,
+// such as SQS or Kafka.
+type Message interface {
+	// Body returns the payload of the message.
+	Body() []byte
+	// Ack acknowledges the message as processed.
+	Ack() error
+	// Nack rejects the message, returning it to the queue when requeue is set,
+	// or sending it to the dead letter queue otherwise.
+	Nack(requeue bool) error
+}
+
+// InMemoryMessage is a Message kept in memory, which records how it was settled.
+// It is meant for testing handlers without a queue.
+type InMemoryMessage struct {
+	body []byte
+
+	mu       sync.Mutex
+	decision Decision
+}
+
+// NewInMemoryMessage creates a pending InMemoryMessage with the body.
+func NewInMemoryMessage(body []byte) *InMemoryMessage {
+	return &InMemoryMessage{body: body}
+}
+
+// Body returns the body given on creation.
+func (m *InMemoryMessage) Body() []byte { return m.body }
+
+// Ack records the message as acknowledged.
+func (m *InMemoryMessage) Ack() error { return m.settle(Ack) }
+
+// Nack records the message as requeued or dead-lettered.
+func (m *InMemoryMessage) Nack(requeue bool) error {
+	if requeue {
+		return m.settle(Requeue)
+	}
+	return m.settle(DeadLetter)
+}
+
+// Decision reports how the message was settled, or Pending if it is not yet.
+func (m *InMemoryMessage) Decision() Decision {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.decision
+}
+
+func (m *InMemoryMessage) settle(decision Decision) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.decision != Pending {
+		return errors.New("message is already settled")
+	}
+	m.decision = decision
+	return nil
+}
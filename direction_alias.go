@@ -0,0 +1,121 @@
+package chain
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+)
+
+// AliasDirection makes the pipeline treat the direction `from` as `to`, for composing actions
+// spelling the same concept differently, such as `NotFound` and `Missing`.
+// When an action directs `from`, the next action is selected by the plan of `to`, and `to` is
+// recorded on stats and traces, keeping `from` as the OriginalDirection of the StepOutcome.
+//
+// Plans of the actions directing `from` can plan `to` instead, and existing plans which do not
+// plan `to` yet continue to the target planned for `from`.
+//
+// An error is returned when `from` is Success, Error or Abort, when either is empty or both are the same,
+// or when `from` is already aliased. Chains of aliases are rejected instead of being resolved,
+// so `to` cannot be aliased itself, and `from` cannot be a target of other aliases.
+func (p *Pipeline[T]) AliasDirection(from, to string) error {
+	switch {
+	case from == "" || to == "":
+		return errors.New("cannot alias empty direction")
+	case from == Success || from == Error || from == Abort:
+		return fmt.Errorf("cannot alias reserved direction `%s`", from)
+	case from == to:
+		return fmt.Errorf("cannot alias direction `%s` to itself", from)
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if target, exists := p.directionAliases[from]; exists {
+		return fmt.Errorf("`%s` is already aliased to `%s`", from, target)
+	} else if target, exists := p.directionAliases[to]; exists {
+		return fmt.Errorf("cannot alias to `%s`, which is aliased to `%s`", to, target)
+	}
+	for source, target := range p.directionAliases {
+		if target == from {
+			return fmt.Errorf("cannot alias `%s`, which `%s` is aliased to", from, source)
+		}
+	}
+
+	// Copy on write, so running does not need to hold the lock
+	aliases := make(map[string]string, len(p.directionAliases)+1)
+	for source, target := range p.directionAliases {
+		aliases[source] = target
+	}
+	aliases[from] = to
+	p.directionAliases = aliases
+
+	for _, action := range p.members {
+		plan := p.planOfLocked(action)
+		nextAction, plansFrom := plan[from]
+		if _, plansTo := plan[to]; !plansFrom || plansTo {
+			continue
+		}
+		plan = clonePlan(plan)
+		plan[to] = nextAction
+		p.runPlans[action] = plan
+		p.compiled.Store(nil)
+		if source, exists := p.planSources[action][from]; exists {
+			sources := make(map[string]PlanSource, len(p.planSources[action])+1)
+			for plannedDirection, plannedSource := range p.planSources[action] {
+				sources[plannedDirection] = plannedSource
+			}
+			sources[to] = source
+			p.planSources[action] = sources
+		}
+	}
+
+	return nil
+}
+
+// aliases returns the aliases of directions, which must not be modified.
+func (p *Pipeline[T]) aliases() map[string]string {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	return p.directionAliases
+}
+
+// plannedDirectionsOf lists the directions the plan of the action can have,
+// which are the directions of directionsOf followed by the targets of their aliases.
+func (p *Pipeline[T]) plannedDirectionsOf(action Action[T]) []string {
+	return aliasedDirectionsOf(action, p.aliases())
+}
+
+// aliasedDirectionsOf lists the directions as plannedDirectionsOf does with the aliases.
+func aliasedDirectionsOf[T any](action Action[T], aliases map[string]string) []string {
+	directions := directionsOf(action)
+	var targets []string
+	for _, direction := range directions {
+		if target, exists := aliases[direction]; exists && !contains(directions, target) && !contains(targets, target) {
+			targets = append(targets, target)
+		}
+	}
+	sort.Strings(targets)
+	return append(directions, targets...)
+}
+
+// withoutAliasTargets returns the plan without the directions planned only as targets of the aliases,
+// which AliasDirection adds to the plans, for taking the plan into a pipeline without the aliases.
+// Directions in supportedDirections are kept, and the plan is returned as it is when none is dropped.
+func withoutAliasTargets[V any](plan map[string]V, aliases map[string]string, supportedDirections []string) map[string]V {
+	var dropped []string
+	for _, target := range aliases {
+		if _, planned := plan[target]; planned && !contains(supportedDirections, target) && !contains(dropped, target) {
+			dropped = append(dropped, target)
+		}
+	}
+	if len(dropped) == 0 {
+		return plan
+	}
+
+	copied := make(map[string]V, len(plan))
+	for direction, next := range plan {
+		if !contains(dropped, direction) {
+			copied[direction] = next
+		}
+	}
+	return copied
+}
@@ -0,0 +1,96 @@
+package chain
+
+import (
+	"context"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestPipeline_AliasDirection(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("plans aliased direction", func(t *testing.T) {
+		libA := &RoutingAction{name: "LibA", directions: []string{"NotFound"}}
+		handle := &DirectingAction{name: "Handle"}
+		pipeline := NewPipeline("Pipeline", libA, handle)
+		assert.NoError(t, pipeline.AliasDirection("NotFound", "Missing"))
+		pipeline.SetRunPlan(libA, ActionPlan[int]{"Missing": handle})
+
+		result := pipeline.RunWithTrace(ctx, 1)
+
+		assert.NoError(t, result.Err)
+		assert.Equal(t, []StepOutcome{
+			{Action: "LibA", Direction: "Missing", OriginalDirection: "NotFound"},
+			{Action: "Handle", Direction: Success},
+		}, result.Trace.Steps)
+		assert.Equal(t, map[string]int64{"Missing": 1}, pipeline.Stats().Steps()["LibA"])
+		assert.Equal(t, []string{"Missing", "NotFound", Abort, Error, Success}, pipeline.ListDirections()["LibA"])
+	})
+
+	t.Run("keeps existing plans", func(t *testing.T) {
+		libA := &RoutingAction{name: "LibA", directions: []string{"NotFound"}}
+		libB := &RoutingAction{name: "LibB", directions: []string{"Missing"}}
+		handle := &DirectingAction{name: "Handle"}
+		pipeline := NewPipeline("Pipeline", libA, libB, handle)
+		pipeline.SetRunPlan(libA, ActionPlan[int]{"NotFound": handle, Success: libB})
+		pipeline.SetRunPlan(libB, ActionPlan[int]{"Missing": handle})
+		assert.NoError(t, pipeline.AliasDirection("NotFound", "Missing"))
+
+		result := pipeline.RunWithTrace(ctx, 1)
+
+		assert.NoError(t, result.Err)
+		assert.Equal(t, []string{"LibA", "Handle"}, []string{result.Trace.Steps[0].Action, result.Trace.Steps[1].Action})
+		edges, err := pipeline.Successors("LibA")
+		assert.NoError(t, err)
+		assert.Contains(t, edges, Edge{FromName: "LibA", Direction: "Missing", ToName: "Handle"})
+	})
+
+	t.Run("rejects invalid aliases", func(t *testing.T) {
+		pipeline := NewPipeline("Pipeline", &DirectingAction{name: "action1"})
+		assert.NoError(t, pipeline.AliasDirection("NotFound", "Missing"))
+
+		assert.EqualError(t, pipeline.AliasDirection(Error, "Failed"), "cannot alias reserved direction `error`")
+		assert.EqualError(t, pipeline.AliasDirection("", "Missing"), "cannot alias empty direction")
+		assert.EqualError(t, pipeline.AliasDirection("Gone", "Gone"), "cannot alias direction `Gone` to itself")
+		assert.EqualError(t, pipeline.AliasDirection("NotFound", "Gone"), "`NotFound` is already aliased to `Missing`")
+	})
+
+	t.Run("rejects chains", func(t *testing.T) {
+		pipeline := NewPipeline("Pipeline", &DirectingAction{name: "action1"})
+		assert.NoError(t, pipeline.AliasDirection("NotFound", "Missing"))
+
+		assert.EqualError(t, pipeline.AliasDirection("Gone", "NotFound"), "cannot alias to `NotFound`, which is aliased to `Missing`")
+		assert.EqualError(t, pipeline.AliasDirection("Missing", "Absent"), "cannot alias `Missing`, which `NotFound` is aliased to")
+	})
+	t.Run("plans copied to other pipelines", func(t *testing.T) {
+		route := &RoutingAction{name: "Route", directions: []string{"NotFound"}}
+		handle := &DirectingAction{name: "Handle"}
+		done := &DirectingAction{name: "Done"}
+		newPipeline := func() *Pipeline[int] {
+			pipeline := NewPipeline("Pipeline", route, handle, done)
+			pipeline.SetRunPlan(route, ActionPlan[int]{"NotFound": handle, Success: handle})
+			return pipeline
+		}
+		aliased := newPipeline()
+		assert.NoError(t, aliased.AliasDirection("NotFound", "Missing"))
+
+		slice, err := aliased.Slice(route, done, "Slice")
+		assert.NoError(t, err)
+		result := slice.RunWithTrace(ctx, 1)
+		assert.NoError(t, result.Err)
+		assert.Equal(t, StepOutcome{Action: "Route", Direction: "Missing", OriginalDirection: "NotFound"}, result.Trace.Steps[0])
+
+		copied := newPipeline()
+		assert.NoError(t, copied.CopyPlanFrom(aliased))
+		assert.NotContains(t, copied.ListDirections()["Route"], "Missing")
+
+		merged := NewPipeline("Pipeline", route, handle, done)
+		assert.NoError(t, merged.MergeRunPlans(aliased))
+		assert.NotContains(t, merged.ListDirections()["Route"], "Missing")
+
+		restored := newPipeline()
+		assert.NoError(t, RestoreFromSnapshot(restored, aliased.Snapshot()))
+		assert.NotContains(t, restored.ListDirections()["Route"], "Missing")
+		assert.Equal(t, "Handle", restored.Snapshot().Plans["Route"]["NotFound"])
+	})
+}
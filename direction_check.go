@@ -0,0 +1,42 @@
+package chain
+
+import "fmt"
+
+// ErrUndeclaredDirection is the error of an action directing a direction it does not declare,
+// which is neither Success, Error nor Abort, nor one of the Directions of a BranchAction.
+type ErrUndeclaredDirection struct {
+	Action    string
+	Direction string
+}
+
+func (e *ErrUndeclaredDirection) Error() string {
+	return fmt.Sprintf("`%s` directs undeclared direction `%s`", e.Action, e.Direction)
+}
+
+// EnableDirectionCheck makes the pipeline check the direction each member action directs,
+// and returns the pipeline itself for chaining. An action directing a direction it does not declare,
+// such as a misspelled one, is treated as failed with an *ErrUndeclaredDirection,
+// so the run follows its Error plan instead of ending as SetUnknownDirectionPolicy configures.
+// DisableDirectionCheck stops the check.
+func (p *Pipeline[T]) EnableDirectionCheck() *Pipeline[T] {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.directionCheck = true
+	return p
+}
+
+// DisableDirectionCheck stops the check started by EnableDirectionCheck, and returns the pipeline itself.
+func (p *Pipeline[T]) DisableDirectionCheck() *Pipeline[T] {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.directionCheck = false
+	return p
+}
+
+// checkDirection converts an undeclared direction of the action into Error with an *ErrUndeclaredDirection.
+func checkDirection[T any](action Action[T], direction string, err error) (string, error) {
+	if contains(directionsOf(action), direction) {
+		return direction, err
+	}
+	return Error, &ErrUndeclaredDirection{Action: action.Name(), Direction: direction}
+}
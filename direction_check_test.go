@@ -0,0 +1,67 @@
+package chain
+
+import (
+	"context"
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestPipeline_EnableDirectionCheck(t *testing.T) {
+	ctx := context.Background()
+	newPipeline := func() *Pipeline[int] {
+		stray := &StrayAction{name: "Stray", direction: "succes"}
+		setTen := &SetTen{}
+		pipeline := NewPipeline("Pipeline", stray, setTen)
+		pipeline.SetRunPlan(stray, ActionPlan[int]{
+			Success: Terminate[int](),
+			Error:   setTen,
+			Abort:   Terminate[int](),
+		})
+		return pipeline
+	}
+
+	t.Run("follows error plan on undeclared direction", func(t *testing.T) {
+		pipeline := newPipeline().EnableDirectionCheck()
+
+		result := pipeline.RunWithTrace(ctx, 1)
+
+		var undeclaredErr *ErrUndeclaredDirection
+		assert.True(t, errors.As(result.Err, &undeclaredErr))
+		assert.EqualError(t, result.Err, "`Stray` directs undeclared direction `succes`")
+		assert.Equal(t, Error, result.Direction)
+		assert.Equal(t, 10, result.Output)
+		assert.Equal(t, []StepOutcome{
+			{Action: "Stray", Direction: Error, Err: undeclaredErr},
+			{Action: "SetTen", Direction: Success},
+		}, result.Trace.Steps)
+	})
+
+	t.Run("passes declared directions", func(t *testing.T) {
+		collatz := NewCollatz("Collatz")
+		collatz.EnableDirectionCheck()
+
+		output, err := collatz.Run(ctx, 5)
+
+		assert.NoError(t, err)
+		assert.Equal(t, 16, output)
+	})
+
+	t.Run("disabled by default", func(t *testing.T) {
+		pipeline := newPipeline()
+
+		_, err := pipeline.Run(ctx, 1)
+
+		var unknownErr *ErrUnknownDirection
+		assert.True(t, errors.As(err, &unknownErr))
+	})
+
+	t.Run("disables check", func(t *testing.T) {
+		pipeline := newPipeline().EnableDirectionCheck().DisableDirectionCheck()
+
+		_, err := pipeline.Run(ctx, 1)
+
+		var unknownErr *ErrUnknownDirection
+		assert.True(t, errors.As(err, &unknownErr))
+	})
+}
@@ -0,0 +1,158 @@
+package chain
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+)
+
+// ErrDraining is the error of a run rejected by a Drainer or a drained Pipeline, which no longer starts new runs.
+var ErrDraining = errors.New("pipeline is draining")
+
+// ErrShuttingDown is the cause of the cancellation of runs aborted by Drain,
+// telling them apart from runs cancelled by their callers.
+var ErrShuttingDown = errors.New("shutting down")
+
+// Drainer tracks the runs started with RunDrained, for shutting down workers gracefully:
+// once draining, new runs are rejected with ErrDraining, and Drain waits for the runs in flight.
+// A Drainer is safe for concurrent use, and can track runs of pipelines of any type.
+type Drainer struct {
+	mu       sync.Mutex
+	draining bool
+	runs     map[int]context.CancelCauseFunc
+	nextRun  int
+	idle     chan struct{}
+}
+
+// NewDrainer creates a Drainer, which starts draining once ctx is done.
+// Giving the ctx of signal.NotifyContext stops starting new runs on the signals such as SIGTERM,
+// while Drain is called to wait for the runs in flight.
+func NewDrainer(ctx context.Context) *Drainer {
+	d := &Drainer{
+		runs: map[int]context.CancelCauseFunc{},
+		idle: make(chan struct{}),
+	}
+	context.AfterFunc(ctx, d.stopAccepting)
+	return d
+}
+
+// RunDrained runs the pipeline as a run tracked by the drainer, reporting the output,
+// the direction the run ended with, and the error. When the drainer is draining, the input
+// is reported with Abort and ErrDraining without running any actions.
+// The run is aborted with a CanceledError caused by ErrShuttingDown when Drain forces it to stop.
+func RunDrained[T any](d *Drainer, p *Pipeline[T], ctx context.Context, input T) (output T, direction string, err error) {
+	runCtx, finish, err := d.track(ctx)
+	if err != nil {
+		return input, Abort, err
+	}
+	defer finish()
+
+	return p.runFallingBack(runCtx, input)
+}
+
+// Drain stops starting new runs, and waits for the runs in flight to finish.
+// When ctx is done before they finish, such as by a drain timeout, the runs are cancelled with
+// ErrShuttingDown as the cause, which makes them abort before their next actions,
+// and Drain returns an error once they have returned. Actions ignoring their ctx delay the return.
+func (d *Drainer) Drain(ctx context.Context) error {
+	d.stopAccepting()
+
+	select {
+	case <-d.idle:
+		return nil
+	case <-ctx.Done():
+	}
+
+	d.mu.Lock()
+	aborted := len(d.runs)
+	for _, cancel := range d.runs {
+		cancel(ErrShuttingDown)
+	}
+	d.mu.Unlock()
+
+	<-d.idle
+	if aborted == 0 {
+		return nil
+	}
+	return fmt.Errorf("aborted %d runs in flight: %w", aborted, context.Cause(ctx))
+}
+
+func (d *Drainer) stopAccepting() {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if !d.draining {
+		d.draining = true
+		d.closeIfIdle()
+	}
+}
+
+// track registers a new run on ctx, returning the ctx to run with and the function to call when the run finishes.
+func (d *Drainer) track(ctx context.Context) (context.Context, func(), error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if d.draining {
+		return nil, nil, ErrDraining
+	}
+
+	runCtx, cancel := context.WithCancelCause(ctx)
+	id := d.nextRun
+	d.nextRun++
+	d.runs[id] = cancel
+
+	return runCtx, func() {
+		cancel(nil)
+		d.mu.Lock()
+		defer d.mu.Unlock()
+		delete(d.runs, id)
+		d.closeIfIdle()
+	}, nil
+}
+
+// closeIfIdle closes idle when draining with no runs in flight, where d.mu should be held.
+func (d *Drainer) closeIfIdle() {
+	if d.draining && len(d.runs) == 0 {
+		select {
+		case <-d.idle:
+		default:
+			close(d.idle)
+		}
+	}
+}
+
+// Drain waits for the runs of the pipeline in progress to finish, such as on shutting down a service,
+// and rejects the runs started afterward, which report the input with Abort and ErrDraining
+// without running any actions. Runs of nested pipelines are tracked by the nested pipelines themselves.
+//
+// When ctx is done before the runs finish, Drain returns the cause of ctx without waiting any longer,
+// while the pipeline still rejects new runs. Unlike Drainer, the runs in progress are not cancelled.
+func (p *Pipeline[T]) Drain(ctx context.Context) error {
+	p.drainMu.Lock()
+	p.draining = true
+	p.drainMu.Unlock()
+
+	finished := make(chan struct{})
+	go func() {
+		p.inflight.Wait()
+		close(finished)
+	}()
+
+	select {
+	case <-finished:
+		return nil
+	case <-ctx.Done():
+		return context.Cause(ctx)
+	}
+}
+
+// startRun counts a run starting unless the pipeline is draining, reporting whether it may start.
+// Runs counted should call p.inflight.Done on their ends.
+func (p *Pipeline[T]) startRun() bool {
+	p.drainMu.RLock()
+	defer p.drainMu.RUnlock()
+	if p.draining {
+		return false
+	}
+	p.inflight.Add(1)
+	return true
+}
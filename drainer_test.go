@@ -0,0 +1,154 @@
+package chain
+
+import (
+	"context"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func TestDrainer(t *testing.T) {
+	ctx := context.Background()
+	newBlocking := func() (*Pipeline[int], chan struct{}, chan struct{}) {
+		started, release := make(chan struct{}), make(chan struct{})
+		blocking := NewSimpleAction("Blocking", func(_ context.Context, input int) (int, error) {
+			close(started)
+			<-release
+			return input + 1, nil
+		})
+		return NewPipeline("Pipeline", blocking, &SetTen{}), started, release
+	}
+	type result struct {
+		output    int
+		direction string
+		err       error
+	}
+	runInBackground := func(d *Drainer, p *Pipeline[int]) <-chan result {
+		resultCh := make(chan result, 1)
+		go func() {
+			var r result
+			r.output, r.direction, r.err = RunDrained(d, p, ctx, 1)
+			resultCh <- r
+		}()
+		return resultCh
+	}
+
+	t.Run("waits for runs in flight", func(t *testing.T) {
+		drainer := NewDrainer(ctx)
+		pipeline, started, release := newBlocking()
+		resultCh := runInBackground(drainer, pipeline)
+		<-started
+
+		drained := make(chan error, 1)
+		go func() { drained <- drainer.Drain(ctx) }()
+		time.Sleep(10 * time.Millisecond)
+		_, direction, err := RunDrained(drainer, NewPipeline("Other", &SetTen{}), ctx, 1)
+		close(release)
+
+		assert.ErrorIs(t, err, ErrDraining)
+		assert.Equal(t, Abort, direction)
+		assert.NoError(t, <-drained)
+		r := <-resultCh
+		assert.NoError(t, r.err)
+		assert.Equal(t, 10, r.output)
+	})
+
+	t.Run("aborts runs after timeout", func(t *testing.T) {
+		drainer := NewDrainer(ctx)
+		pipeline, started, release := newBlocking()
+		resultCh := runInBackground(drainer, pipeline)
+		<-started
+		timeout, cancel := context.WithTimeout(ctx, 10*time.Millisecond)
+		defer cancel()
+
+		drained := make(chan error, 1)
+		go func() { drained <- drainer.Drain(timeout) }()
+		<-timeout.Done()
+		time.Sleep(10 * time.Millisecond)
+		close(release)
+
+		assert.EqualError(t, <-drained, "aborted 1 runs in flight: context deadline exceeded")
+		r := <-resultCh
+		assert.ErrorIs(t, r.err, ErrShuttingDown)
+		assert.Equal(t, Abort, r.direction)
+		assert.Equal(t, 2, r.output)
+	})
+
+	t.Run("stops accepting on signal", func(t *testing.T) {
+		signaled, signal := context.WithCancel(ctx)
+		drainer := NewDrainer(signaled)
+		pipeline := NewPipeline("Pipeline", &SetTen{})
+
+		output, _, err := RunDrained(drainer, pipeline, ctx, 1)
+		assert.NoError(t, err)
+		assert.Equal(t, 10, output)
+
+		signal()
+		assert.Eventually(t, func() bool {
+			_, _, err = RunDrained(drainer, pipeline, ctx, 1)
+			return err == ErrDraining
+		}, time.Second, time.Millisecond)
+		assert.NoError(t, drainer.Drain(ctx))
+	})
+}
+
+func TestPipeline_Drain(t *testing.T) {
+	ctx := context.Background()
+	newBlocking := func() (*Pipeline[int], chan struct{}, chan struct{}) {
+		started, release := make(chan struct{}), make(chan struct{})
+		blocking := NewSimpleAction("Blocking", func(_ context.Context, input int) (int, error) {
+			close(started)
+			<-release
+			return input + 1, nil
+		})
+		return NewPipeline("Pipeline", blocking, &SetTen{}), started, release
+	}
+
+	t.Run("waits for runs in progress", func(t *testing.T) {
+		pipeline, started, release := newBlocking()
+		outputCh := make(chan int, 1)
+		go func() {
+			output, _ := pipeline.Run(ctx, 1)
+			outputCh <- output
+		}()
+		<-started
+
+		drained := make(chan error, 1)
+		go func() { drained <- pipeline.Drain(ctx) }()
+		time.Sleep(10 * time.Millisecond)
+		select {
+		case <-drained:
+			t.Fatal("drained before the run finished")
+		default:
+		}
+		close(release)
+
+		assert.NoError(t, <-drained)
+		assert.Equal(t, 10, <-outputCh)
+	})
+
+	t.Run("rejects runs after draining", func(t *testing.T) {
+		collatz := NewCollatz("Collatz")
+		assert.NoError(t, collatz.Drain(ctx))
+
+		output, err := collatz.Run(ctx, 5)
+		assert.ErrorIs(t, err, ErrDraining)
+		assert.Equal(t, 5, output)
+		output, err = collatz.RunAt(collatz.OnOdd, ctx, 5)
+		assert.ErrorIs(t, err, ErrDraining)
+		assert.Equal(t, 5, output)
+	})
+
+	t.Run("returns on ctx done", func(t *testing.T) {
+		pipeline, started, release := newBlocking()
+		defer close(release)
+		go func() { _, _ = pipeline.Run(ctx, 1) }()
+		<-started
+		timeout, cancel := context.WithTimeout(ctx, 10*time.Millisecond)
+		defer cancel()
+
+		assert.ErrorIs(t, pipeline.Drain(timeout), context.DeadlineExceeded)
+		_, err := pipeline.Run(ctx, 1)
+		assert.ErrorIs(t, err, ErrDraining)
+	})
+}
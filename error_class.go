@@ -0,0 +1,94 @@
+package chain
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/sirupsen/logrus"
+	"net"
+)
+
+// ErrorClass tells whether an error of an action is worth trying again,
+// separating transient infrastructure failures from permanent failures of the work itself.
+type ErrorClass int
+
+const (
+	// ErrorClassUnknown is the class of errors the classifier cannot tell. Such errors are retried.
+	ErrorClassUnknown ErrorClass = iota
+	// ErrorClassTransient is the class of errors that may not happen again, such as timeouts. Such errors are retried.
+	ErrorClassTransient
+	// ErrorClassPermanent is the class of errors that happen again on the same input,
+	// such as rejected requests. Such errors are not retried.
+	ErrorClassPermanent
+)
+
+func (c ErrorClass) String() string {
+	switch c {
+	case ErrorClassUnknown:
+		return "ErrorClassUnknown"
+	case ErrorClassTransient:
+		return "ErrorClassTransient"
+	case ErrorClassPermanent:
+		return "ErrorClassPermanent"
+	default:
+		return fmt.Sprintf("ErrorClass(%d)", int(c))
+	}
+}
+
+// ClassifyError is the default classifier of pipelines. It classifies context.DeadlineExceeded
+// and timeouts of net.Error as transient, and context.Canceled as permanent,
+// as the caller gave up on the work. Other errors are unknown.
+func ClassifyError(err error) ErrorClass {
+	var netErr net.Error
+	switch {
+	case err == nil:
+		return ErrorClassUnknown
+	case errors.Is(err, context.DeadlineExceeded):
+		return ErrorClassTransient
+	case errors.Is(err, context.Canceled):
+		return ErrorClassPermanent
+	case errors.As(err, &netErr) && netErr.Timeout():
+		return ErrorClassTransient
+	default:
+		return ErrorClassUnknown
+	}
+}
+
+// SetErrorClassifier changes how the errors of the member actions are classified, which is
+// ClassifyError by default, and returns the pipeline itself for chaining. Setting nil restores the default.
+// The classes are recorded on each step of the trace, counted by EnableMetrics,
+// and stop Retry from retrying errors classified as permanent.
+//
+// Classes never change the directions of the actions, which are decided first, including
+// those of DirectedErrors. So an error directing Abort is never retried even when it is transient.
+// A panic in classify is recovered, and the error is classified as unknown.
+func (p *Pipeline[T]) SetErrorClassifier(classify func(error) ErrorClass) *Pipeline[T] {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.errorClassifier = classify
+	return p
+}
+
+func (p *Pipeline[T]) classifier() func(error) ErrorClass {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	if p.errorClassifier == nil {
+		return ClassifyError
+	}
+	return p.errorClassifier
+}
+
+// classifyError classifies err with classify, where no error has no class.
+func classifyError(classify func(error) ErrorClass, err error) (class ErrorClass) {
+	if err == nil {
+		return ErrorClassUnknown
+	}
+	defer func() {
+		if panicErr := recover(); panicErr != nil {
+			logrus.Errorf("panic occurred on classifying %s, caused by %s", err, panicErr)
+			class = ErrorClassUnknown
+		}
+	}()
+
+	return classify(err)
+}
@@ -0,0 +1,103 @@
+package chain
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestClassifyError(t *testing.T) {
+	testCases := map[string]struct {
+		err      error
+		expected ErrorClass
+	}{
+		"no error":          {err: nil, expected: ErrorClassUnknown},
+		"deadline exceeded": {err: fmt.Errorf("calling: %w", context.DeadlineExceeded), expected: ErrorClassTransient},
+		"canceled":          {err: context.Canceled, expected: ErrorClassPermanent},
+		"network timeout":   {err: &timeoutError{timeout: true}, expected: ErrorClassTransient},
+		"network failure":   {err: &timeoutError{timeout: false}, expected: ErrorClassUnknown},
+		"other error":       {err: errors.New("rejected"), expected: ErrorClassUnknown},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			assert.Equal(t, tc.expected, ClassifyError(tc.err))
+		})
+	}
+}
+
+func TestPipeline_SetErrorClassifier(t *testing.T) {
+	ctx := context.Background()
+	errRejected := errors.New("rejected")
+	classify := func(err error) ErrorClass {
+		if errors.Is(err, errRejected) {
+			return ErrorClassPermanent
+		}
+		return ErrorClassTransient
+	}
+
+	t.Run("records classes on trace", func(t *testing.T) {
+		pipeline := NewPipeline("Pipeline", &SetTen{}, &ErrorMaker{message: "failing"}).SetErrorClassifier(classify)
+
+		result := pipeline.RunWithTrace(ctx, 1)
+
+		assert.Equal(t, []StepOutcome{
+			{Action: "SetTen", Direction: Success},
+			{Action: "failing", Direction: Error, Err: result.Trace.Steps[1].Err, Class: ErrorClassTransient},
+		}, result.Trace.Steps)
+	})
+
+	t.Run("stops retrying permanent errors", func(t *testing.T) {
+		attempts := 0
+		rejecting := NewSimpleAction("Rejecting", func(_ context.Context, input int) (int, error) {
+			attempts++
+			return input, errRejected
+		})
+		pipeline := NewPipeline("Pipeline", rejecting).SetErrorClassifier(classify)
+		assert.NoError(t, pipeline.Retry(rejecting, 2))
+
+		_, err := pipeline.Run(ctx, 1)
+
+		assert.ErrorIs(t, err, errRejected)
+		assert.Equal(t, 1, attempts)
+	})
+
+	t.Run("retries transient errors", func(t *testing.T) {
+		flaky := &FlakyAction{failures: 2}
+		pipeline := NewPipeline("Pipeline", Action[int](flaky)).SetErrorClassifier(classify)
+		assert.NoError(t, pipeline.Retry(flaky, 2))
+
+		_, err := pipeline.Run(ctx, 1)
+
+		assert.NoError(t, err)
+		assert.Equal(t, 3, flaky.attempts)
+	})
+
+	t.Run("restores default classifier", func(t *testing.T) {
+		pipeline := NewPipeline("Pipeline", &ErrorMaker{message: "failing"}).SetErrorClassifier(classify).SetErrorClassifier(nil)
+
+		result := pipeline.RunWithTrace(ctx, 1)
+
+		assert.Equal(t, ErrorClassUnknown, result.Trace.Steps[0].Class)
+	})
+
+	t.Run("recovers panicking classifier", func(t *testing.T) {
+		pipeline := NewPipeline("Pipeline", &ErrorMaker{message: "failing"}).
+			SetErrorClassifier(func(error) ErrorClass { panic("broken classifier") })
+
+		result := pipeline.RunWithTrace(ctx, 1)
+
+		assert.EqualError(t, result.Err, "failing")
+		assert.Equal(t, ErrorClassUnknown, result.Trace.Steps[0].Class)
+	})
+}
+
+type timeoutError struct {
+	timeout bool
+}
+
+func (e *timeoutError) Error() string   { return "network failure" }
+func (e *timeoutError) Timeout() bool   { return e.timeout }
+func (e *timeoutError) Temporary() bool { return false }
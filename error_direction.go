@@ -0,0 +1,37 @@
+package chain
+
+import (
+	"errors"
+	"github.com/sirupsen/logrus"
+)
+
+// DirectedError is an error carrying the direction the pipeline should take,
+// keeping the branching logic next to the definition of the error:
+//
+//	type OutOfStockErr struct{ Item string }
+//
+//	func (e OutOfStockErr) Error() string     { return e.Item + " is out of stock" }
+//	func (OutOfStockErr) Direction() string { return "OutOfStock" }
+//
+// When an action fails with an error wrapping a DirectedError, the pipeline selects the next
+// action by its Direction instead of Error, provided the action supports the direction,
+// as a BranchAction declares it with Directions. Otherwise, Error is used as usual.
+type DirectedError interface {
+	error
+	Direction() string
+}
+
+// errorDirection finds the direction carried by the error of the action,
+// falling back to Error when the error carries none, or the action does not support it.
+func errorDirection[T any](action Action[T], err error) string {
+	var directed DirectedError
+	if !errors.As(err, &directed) {
+		return Error
+	}
+	direction := directed.Direction()
+	if !contains(directionsOf(action), direction) {
+		logrus.Warnf("%s: error directs `%s` which is not supported, falling back to `%s`", action.Name(), direction, Error)
+		return Error
+	}
+	return direction
+}
@@ -0,0 +1,94 @@
+package chain
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+type OutOfStockErr struct{ item string }
+
+func (e OutOfStockErr) Error() string   { return e.item + " is out of stock" }
+func (OutOfStockErr) Direction() string { return "OutOfStock" }
+
+func TestDirectedError(t *testing.T) {
+	ctx := context.Background()
+	newCheckout := func(reserve Action[int]) *Pipeline[int] {
+		backorder := &DirectingAction{name: "Backorder"}
+		refund := &DirectingAction{name: "Refund"}
+		pipeline := NewPipeline("Checkout", reserve, backorder, refund)
+		plan := ActionPlan[int]{Error: refund}
+		if isBranchAction[int](reserve) {
+			plan["OutOfStock"] = backorder
+		}
+		pipeline.SetRunPlan(reserve, plan)
+		pipeline.SetRunPlan(backorder, TerminationPlan[int]())
+		return pipeline
+	}
+	stepsOf := func(result RunResult[int]) []string {
+		var steps []string
+		for _, step := range result.Trace.Steps {
+			steps = append(steps, step.Action+"/"+step.Direction)
+		}
+		return steps
+	}
+
+	t.Run("route by direction of error", func(t *testing.T) {
+		reserve := &ReserveAction{runErr: fmt.Errorf("reserving: %w", OutOfStockErr{item: "apple"})}
+		pipeline := newCheckout(reserve)
+
+		result := pipeline.RunWithTrace(ctx, 1)
+
+		assert.Equal(t, []string{"Reserve/OutOfStock", "Backorder/success"}, stepsOf(result))
+		var outOfStock OutOfStockErr
+		assert.True(t, errors.As(result.Err, &outOfStock))
+	})
+
+	t.Run("route by direction of NextDirection error", func(t *testing.T) {
+		reserve := &ReserveAction{directionErr: OutOfStockErr{item: "apple"}}
+		pipeline := newCheckout(reserve)
+
+		result := pipeline.RunWithTrace(ctx, 1)
+
+		assert.Equal(t, []string{"Reserve/OutOfStock", "Backorder/success"}, stepsOf(result))
+	})
+
+	t.Run("fall back to error on unsupported direction", func(t *testing.T) {
+		reserve := NewSimpleAction("Reserve", func(_ context.Context, input int) (int, error) {
+			return input, OutOfStockErr{item: "apple"}
+		})
+		pipeline := newCheckout(reserve)
+
+		result := pipeline.RunWithTrace(ctx, 1)
+
+		assert.Equal(t, []string{"Reserve/error", "Refund/success"}, stepsOf(result))
+	})
+
+	t.Run("plain errors direct error", func(t *testing.T) {
+		reserve := &ReserveAction{runErr: errors.New("plain")}
+		pipeline := newCheckout(reserve)
+
+		result := pipeline.RunWithTrace(ctx, 1)
+
+		assert.Equal(t, []string{"Reserve/error", "Refund/success"}, stepsOf(result))
+	})
+}
+
+type ReserveAction struct {
+	runErr       error
+	directionErr error
+}
+
+func (*ReserveAction) Name() string         { return "Reserve" }
+func (*ReserveAction) Directions() []string { return []string{"OutOfStock"} }
+func (r *ReserveAction) Run(_ context.Context, input int) (int, error) {
+	return input, r.runErr
+}
+func (r *ReserveAction) NextDirection(_ context.Context, _ int) (string, error) {
+	if r.directionErr != nil {
+		return "", r.directionErr
+	}
+	return Success, nil
+}
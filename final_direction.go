@@ -0,0 +1,49 @@
+package chain
+
+import "fmt"
+
+// FinalDirectionPolicy decides the direction a run ends with,
+// when an action returned an error during the run.
+type FinalDirectionPolicy int
+
+const (
+	// LastError ends the run with Error whenever any action returned an error during the run,
+	// unless the run aborted. The error returned joins the errors of the actions, as StepError describes.
+	// This is the default policy.
+	LastError FinalDirectionPolicy = iota
+	// LastAction ends the run with the direction of the last action, even though an action
+	// returned an error during the run. The error returned still joins the errors of the actions.
+	LastAction
+	// ClearedOnRecovery clears the error once a later action runs without an error,
+	// so a run recovered by its plan ends with the direction of the last action and no error.
+	ClearedOnRecovery
+)
+
+func (f FinalDirectionPolicy) String() string {
+	switch f {
+	case LastError:
+		return "LastError"
+	case LastAction:
+		return "LastAction"
+	case ClearedOnRecovery:
+		return "ClearedOnRecovery"
+	default:
+		return fmt.Sprintf("FinalDirectionPolicy(%d)", int(f))
+	}
+}
+
+// SetFinalDirectionPolicy changes how the direction of runs is decided after an action returned
+// an error, which is LastError by default. The change takes effect from the next run.
+//
+// An error is returned for an unknown policy.
+func (p *Pipeline[T]) SetFinalDirectionPolicy(policy FinalDirectionPolicy) error {
+	if policy < LastError || policy > ClearedOnRecovery {
+		return fmt.Errorf("unknown final direction policy %s", policy)
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.finalDirectionPolicy = policy
+
+	return nil
+}
@@ -0,0 +1,57 @@
+package chain
+
+import (
+	"context"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestPipeline_SetFinalDirectionPolicy(t *testing.T) {
+	// failing -(error)-> recover -(success)-> terminate
+	newRecovering := func() *Pipeline[int] {
+		failing := &ErrorMaker{message: "failing"}
+		recovering := &SetTen{}
+		pipeline := NewPipeline[int]("Recovering", failing, recovering)
+		pipeline.SetRunPlan(failing, ActionPlan[int]{Error: recovering})
+		return pipeline
+	}
+
+	type testCase struct {
+		policy    FinalDirectionPolicy
+		direction string
+		errMsg    string
+	}
+	testCases := map[string]testCase{
+		"last error":          {policy: LastError, direction: Error, errMsg: "failing"},
+		"last action":         {policy: LastAction, direction: Success, errMsg: "failing"},
+		"cleared on recovery": {policy: ClearedOnRecovery, direction: Success},
+	}
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			pipeline := newRecovering()
+			assert.NoError(t, pipeline.SetFinalDirectionPolicy(tc.policy))
+
+			result := pipeline.RunWithTrace(context.Background(), 1)
+
+			assert.Equal(t, 10, result.Output)
+			assert.Equal(t, tc.direction, result.Direction)
+			if tc.errMsg == "" {
+				assert.NoError(t, result.Err)
+			} else {
+				assert.EqualError(t, result.Err, tc.errMsg)
+			}
+		})
+	}
+
+	t.Run("default is last error", func(t *testing.T) {
+		result := newRecovering().RunWithTrace(context.Background(), 1)
+
+		assert.Equal(t, Error, result.Direction)
+	})
+
+	t.Run("unknown policy", func(t *testing.T) {
+		err := newRecovering().SetFinalDirectionPolicy(FinalDirectionPolicy(5))
+
+		assert.EqualError(t, err, "unknown final direction policy FinalDirectionPolicy(5)")
+	})
+}
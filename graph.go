@@ -0,0 +1,295 @@
+package chain
+
+import (
+	"container/heap"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Edge is a single entry of the plans in a Graph, telling that the action named FromName
+// continues to the action named ToName when it directs Direction.
+// Edges leading to termination have Terminates set, with an empty ToName.
+type Edge struct {
+	FromName   string
+	Direction  string
+	ToName     string
+	Terminates bool
+}
+
+// Graph is a snapshot of the plans of a Pipeline, referring to the actions by their names.
+// It is taken by Pipeline.Graph, and does not change along with the pipeline afterward.
+// Member pipelines are single nodes of the graph, as they are for running.
+//
+// Actions are identified by their names, so member actions should have unique names
+// for the graph to be accurate.
+type Graph struct {
+	nodes        []string
+	edges        []Edge
+	successors   map[string][]Edge
+	predecessors map[string][]Edge
+}
+
+// Graph takes a snapshot of the plans of the pipeline for analysis.
+// It is safe to call while the pipeline is running.
+func (p *Pipeline[T]) Graph() Graph {
+	g := Graph{
+		nodes:        make([]string, 0, len(p.members)),
+		successors:   map[string][]Edge{},
+		predecessors: map[string][]Edge{},
+	}
+
+	terminate := Terminate[T]()
+	for _, action := range p.members {
+		g.nodes = append(g.nodes, action.Name())
+
+		plan := p.planOf(action)
+		for _, direction := range p.plannedDirectionsOf(action) {
+			nextAction, exists := plan[direction]
+			if !exists {
+				continue
+			}
+			edge := Edge{FromName: action.Name(), Direction: direction, Terminates: true}
+			if nextAction != terminate {
+				edge.ToName, edge.Terminates = nextAction.Name(), false
+			}
+
+			g.edges = append(g.edges, edge)
+			g.successors[edge.FromName] = append(g.successors[edge.FromName], edge)
+			if !edge.Terminates {
+				g.predecessors[edge.ToName] = append(g.predecessors[edge.ToName], edge)
+			}
+		}
+	}
+
+	return g
+}
+
+// Nodes lists the names of the member actions, in the order they were given to the constructor.
+func (g Graph) Nodes() []string {
+	return append([]string(nil), g.nodes...)
+}
+
+// Edges lists every edge of the plans, including the ones leading to termination.
+// Edges are ordered by the members they start from, then by their directions
+// with Success, Error and Abort first.
+func (g Graph) Edges() []Edge {
+	return append([]Edge(nil), g.edges...)
+}
+
+// Successors lists the edges starting from the named action, including the ones leading to termination.
+func (g Graph) Successors(name string) []Edge {
+	return append([]Edge(nil), g.successors[name]...)
+}
+
+// Predecessors lists the edges leading to the named action.
+func (g Graph) Predecessors(name string) []Edge {
+	return append([]Edge(nil), g.predecessors[name]...)
+}
+
+// Successors lists the edges starting from the named member action, including the ones leading
+// to termination. Member pipelines are single nodes, so edges inside them are not included.
+// An error is returned when no member action has the name.
+func (p *Pipeline[T]) Successors(name string) ([]Edge, error) {
+	g := p.Graph()
+	if !contains(g.nodes, name) {
+		return nil, fmt.Errorf("`%s` is not a member of this pipeline", name)
+	}
+	return g.Successors(name), nil
+}
+
+// Predecessors lists the edges leading to the named member action, telling which actions can
+// route into it and on which directions. Member pipelines are single nodes, so edges inside them
+// are not included. An error is returned when no member action has the name.
+func (p *Pipeline[T]) Predecessors(name string) ([]Edge, error) {
+	g := p.Graph()
+	if !contains(g.nodes, name) {
+		return nil, fmt.Errorf("`%s` is not a member of this pipeline", name)
+	}
+	return g.Predecessors(name), nil
+}
+
+// Dependencies maps the name of each action to the names of the actions routing into it,
+// which is the inverse of the adjacency of the plans. The names are listed once each,
+// in the order of the members, and actions nothing routes into map to an empty list.
+func (g Graph) Dependencies() map[string][]string {
+	order := make(map[string]int, len(g.nodes))
+	for i, name := range g.nodes {
+		if _, exists := order[name]; !exists {
+			order[name] = i
+		}
+	}
+
+	dependencies := make(map[string][]string, len(g.nodes))
+	for _, name := range g.nodes {
+		names := []string{}
+		for _, edge := range g.predecessors[name] {
+			if !contains(names, edge.FromName) {
+				names = append(names, edge.FromName)
+			}
+		}
+		sort.Slice(names, func(i, j int) bool { return order[names[i]] < order[names[j]] })
+		dependencies[name] = names
+	}
+	return dependencies
+}
+
+// ActionDependencies maps the name of each member action to the names of the actions which can
+// run right before it, as Graph.Dependencies does, for understanding the flow of data
+// through the pipeline and documenting it.
+func (p *Pipeline[T]) ActionDependencies() map[string][]string {
+	return p.Graph().Dependencies()
+}
+
+// CycleError reports the actions forming cycles in a graph which was expected to be acyclic.
+// Each cycle lists the names of its members, in the order of the members of the pipeline.
+type CycleError struct {
+	Cycles [][]string
+}
+
+func (e *CycleError) Error() string {
+	cycles := make([]string, len(e.Cycles))
+	for i, cycle := range e.Cycles {
+		cycles[i] = "[`" + strings.Join(cycle, "`, `") + "`]"
+	}
+	return fmt.Sprintf("cycle detected: %s", strings.Join(cycles, ", "))
+}
+
+// TopoSort orders the names of the actions so that every action comes before the actions it
+// continues to. Among the actions whose predecessors are all sorted, the one given to the
+// constructor first comes first.
+// When the graph has cycles, a *CycleError listing the members of each cycle is returned.
+func (g Graph) TopoSort() ([]string, error) {
+	order := make(map[string]int, len(g.nodes))
+	for i, name := range g.nodes {
+		if _, exists := order[name]; !exists {
+			order[name] = i
+		}
+	}
+
+	inDegree := make(map[string]int, len(order))
+	for _, edge := range g.edges {
+		if !edge.Terminates {
+			inDegree[edge.ToName]++
+		}
+	}
+
+	// The actions ready to be sorted are kept by their orders, so the first one is taken in logarithmic time
+	ready := &orderHeap{}
+	for name, i := range order {
+		if inDegree[name] == 0 {
+			*ready = append(*ready, i)
+		}
+	}
+	heap.Init(ready)
+	var sorted []string
+	for ready.Len() > 0 {
+		name := g.nodes[heap.Pop(ready).(int)]
+		sorted = append(sorted, name)
+
+		for _, edge := range g.successors[name] {
+			if edge.Terminates {
+				continue
+			}
+			inDegree[edge.ToName]--
+			if inDegree[edge.ToName] == 0 {
+				heap.Push(ready, order[edge.ToName])
+			}
+		}
+	}
+
+	if len(sorted) < len(order) {
+		return nil, &CycleError{Cycles: g.cycles()}
+	}
+	return sorted, nil
+}
+
+// orderHeap is a min-heap of the positions of the actions given to the constructor, for container/heap.
+type orderHeap []int
+
+func (h orderHeap) Len() int           { return len(h) }
+func (h orderHeap) Less(i, j int) bool { return h[i] < h[j] }
+func (h orderHeap) Swap(i, j int)      { h[i], h[j] = h[j], h[i] }
+func (h *orderHeap) Push(x any)        { *h = append(*h, x.(int)) }
+func (h *orderHeap) Pop() any {
+	last := (*h)[len(*h)-1]
+	*h = (*h)[:len(*h)-1]
+	return last
+}
+
+// cycles finds the strongly connected components with more than a single action.
+func (g Graph) cycles() [][]string {
+	order := make(map[string]int, len(g.nodes))
+	for i, name := range g.nodes {
+		if _, exists := order[name]; !exists {
+			order[name] = i
+		}
+	}
+
+	cycles := stronglyConnected(g.nodes, func(name string) []string {
+		var names []string
+		for _, edge := range g.successors[name] {
+			if !edge.Terminates {
+				names = append(names, edge.ToName)
+			}
+		}
+		return names
+	})
+	for _, component := range cycles {
+		sort.Slice(component, func(i, j int) bool { return order[component[i]] < order[component[j]] })
+	}
+	sort.Slice(cycles, func(i, j int) bool { return order[cycles[i][0]] < order[cycles[j][0]] })
+	return cycles
+}
+
+// stronglyConnected finds the strongly connected components with more than a single node,
+// using Tarjan's algorithm.
+func stronglyConnected[K comparable](nodes []K, successors func(K) []K) [][]K {
+	var (
+		index      = 0
+		indexes    = map[K]int{}
+		lowLinks   = map[K]int{}
+		onStack    = map[K]bool{}
+		stack      []K
+		components [][]K
+		connect    func(node K)
+	)
+	connect = func(node K) {
+		indexes[node], lowLinks[node] = index, index
+		index++
+		stack = append(stack, node)
+		onStack[node] = true
+
+		for _, next := range successors(node) {
+			if _, visited := indexes[next]; !visited {
+				connect(next)
+				lowLinks[node] = min(lowLinks[node], lowLinks[next])
+			} else if onStack[next] {
+				lowLinks[node] = min(lowLinks[node], indexes[next])
+			}
+		}
+
+		if lowLinks[node] == indexes[node] {
+			var component []K
+			for {
+				member := stack[len(stack)-1]
+				stack = stack[:len(stack)-1]
+				onStack[member] = false
+				component = append(component, member)
+				if member == node {
+					break
+				}
+			}
+			if len(component) > 1 {
+				components = append(components, component)
+			}
+		}
+	}
+	for _, node := range nodes {
+		if _, visited := indexes[node]; !visited {
+			connect(node)
+		}
+	}
+
+	return components
+}
@@ -0,0 +1,152 @@
+package chain
+
+import (
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"sync"
+	"testing"
+)
+
+func TestPipeline_Graph(t *testing.T) {
+	t.Run("edges of branching plans", func(t *testing.T) {
+		collatz := NewCollatz("Collatz")
+
+		graph := collatz.Graph()
+
+		assert.Equal(t, []string{"CheckNext", "OnEven", "OnOdd"}, graph.Nodes())
+		assert.Equal(t, []Edge{
+			{FromName: "CheckNext", Direction: Success, Terminates: true},
+			{FromName: "CheckNext", Direction: Error, Terminates: true},
+			{FromName: "CheckNext", Direction: Abort, Terminates: true},
+			{FromName: "CheckNext", Direction: "even", ToName: "OnEven"},
+			{FromName: "CheckNext", Direction: "odd", ToName: "OnOdd"},
+			{FromName: "OnEven", Direction: Success, Terminates: true},
+			{FromName: "OnEven", Direction: Error, Terminates: true},
+			{FromName: "OnEven", Direction: Abort, Terminates: true},
+			{FromName: "OnOdd", Direction: Success, Terminates: true},
+			{FromName: "OnOdd", Direction: Error, Terminates: true},
+			{FromName: "OnOdd", Direction: Abort, Terminates: true},
+		}, graph.Edges())
+		assert.Equal(t, []Edge{{FromName: "CheckNext", Direction: "odd", ToName: "OnOdd"}}, graph.Predecessors("OnOdd"))
+		assert.Len(t, graph.Successors("CheckNext"), 5)
+		assert.Empty(t, graph.Predecessors("CheckNext"))
+		assert.Empty(t, graph.Successors("Unknown"))
+	})
+
+	t.Run("snapshot is not affected by later changes", func(t *testing.T) {
+		action1, action2 := &DirectingAction{name: "action1"}, &DirectingAction{name: "action2"}
+		pipeline := NewPipeline("Pipeline", action1, action2)
+		graph := pipeline.Graph()
+
+		pipeline.SetRunPlan(action1, TerminationPlan[int]())
+
+		assert.Equal(t, "action2", graph.Successors("action1")[0].ToName)
+	})
+
+	t.Run("topological sort", func(t *testing.T) {
+		action1 := &DirectingAction{name: "action1"}
+		action2 := &DirectingAction{name: "action2"}
+		action3 := &DirectingAction{name: "action3"}
+		action4 := &DirectingAction{name: "action4"}
+		pipeline := NewPipeline("Pipeline", action4, action3, action2, action1)
+		// action4 -> action2 -> action1, action3 -> action1
+		pipeline.SetRunPlan(action4, SuccessOnlyPlan(action2))
+		pipeline.SetRunPlan(action3, SuccessOnlyPlan(action1))
+		pipeline.SetRunPlan(action2, SuccessOnlyPlan(action1))
+		pipeline.SetRunPlan(action1, TerminationPlan[int]())
+
+		sorted, err := pipeline.Graph().TopoSort()
+
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"action4", "action3", "action2", "action1"}, sorted)
+	})
+
+	t.Run("topological sort of cyclic graph", func(t *testing.T) {
+		action1 := &DirectingAction{name: "action1"}
+		action2 := &DirectingAction{name: "action2"}
+		action3 := &DirectingAction{name: "action3"}
+		action4 := &DirectingAction{name: "action4"}
+		pipeline := NewPipeline("Pipeline", action1, action2, action3, action4)
+		// action1 -> (action2 <-> action3) -> action4
+		pipeline.SetRunPlan(action1, SuccessOnlyPlan(action2))
+		pipeline.SetRunPlan(action2, SuccessOnlyPlan(action3))
+		pipeline.SetRunPlan(action3, DefaultPlan(action4, action2))
+
+		sorted, err := pipeline.Graph().TopoSort()
+
+		assert.Nil(t, sorted)
+		var cycleErr *CycleError
+		assert.True(t, errors.As(err, &cycleErr))
+		assert.Equal(t, [][]string{{"action2", "action3"}}, cycleErr.Cycles)
+		assert.EqualError(t, err, "cycle detected: [`action2`, `action3`]")
+	})
+
+	t.Run("safe while running", func(t *testing.T) {
+		collatz := NewCollatz("Collatz")
+
+		var wg sync.WaitGroup
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := 0; i < 100; i++ {
+				assert.NoError(t, collatz.Divert(collatz.OnOdd, Success, collatz.OnEven))
+				assert.NoError(t, collatz.Divert(collatz.OnOdd, Success, Terminate[int]()))
+			}
+		}()
+		for i := 0; i < 100; i++ {
+			_ = collatz.Graph()
+		}
+		wg.Wait()
+	})
+}
+
+func TestPipeline_PredecessorsAndSuccessors(t *testing.T) {
+	validate := &DirectingAction{name: "Validate"}
+	charge := &DirectingAction{name: "ChargeCard"}
+	refund := &DirectingAction{name: "Refund"}
+	inner := NewPipeline("Inner", &DirectingAction{name: "innerAction"})
+	pipeline := NewPipeline("Checkout", validate, Action[int](inner), charge, refund)
+	pipeline.SetRunPlan(validate, ActionPlan[int]{Success: inner, Error: charge})
+	pipeline.SetRunPlan(charge, ActionPlan[int]{Error: refund})
+
+	predecessors, err := pipeline.Predecessors("ChargeCard")
+	assert.NoError(t, err)
+	assert.Equal(t, []Edge{
+		{FromName: "Validate", Direction: Error, ToName: "ChargeCard"},
+		{FromName: "Inner", Direction: Success, ToName: "ChargeCard"},
+	}, predecessors)
+
+	successors, err := pipeline.Successors("ChargeCard")
+	assert.NoError(t, err)
+	assert.Equal(t, []Edge{
+		{FromName: "ChargeCard", Direction: Success, Terminates: true},
+		{FromName: "ChargeCard", Direction: Error, ToName: "Refund"},
+		{FromName: "ChargeCard", Direction: Abort, Terminates: true},
+	}, successors)
+
+	successors, err = pipeline.Successors("Inner")
+	assert.NoError(t, err)
+	assert.Equal(t, "ChargeCard", successors[0].ToName)
+
+	_, err = pipeline.Predecessors("innerAction")
+	assert.EqualError(t, err, "`innerAction` is not a member of this pipeline")
+	_, err = pipeline.Successors("Unknown")
+	assert.EqualError(t, err, "`Unknown` is not a member of this pipeline")
+}
+
+func TestPipeline_ActionDependencies(t *testing.T) {
+	validate := &DirectingAction{name: "Validate"}
+	charge := &DirectingAction{name: "ChargeCard"}
+	refund := &DirectingAction{name: "Refund"}
+	notify := &DirectingAction{name: "Notify"}
+	pipeline := NewPipeline("Checkout", validate, charge, refund, notify)
+	pipeline.SetRunPlan(validate, ActionPlan[int]{Success: charge, Error: notify, Abort: notify})
+	pipeline.SetRunPlan(charge, ActionPlan[int]{Success: notify, Error: refund})
+
+	assert.Equal(t, map[string][]string{
+		"Validate":   {},
+		"ChargeCard": {"Validate"},
+		"Refund":     {"ChargeCard"},
+		"Notify":     {"Validate", "ChargeCard", "Refund"},
+	}, pipeline.ActionDependencies())
+}
@@ -0,0 +1,84 @@
+package chain
+
+import (
+	"context"
+	"github.com/sirupsen/logrus"
+	"time"
+)
+
+// NewLoggingAction decorates the action to log the start and the end of each run of it,
+// with the time it took and the error it returned, as a simple text-based trace.
+// When the action is a BranchAction, the decorated one is also a BranchAction logging the direction.
+func NewLoggingAction[T any](action Action[T]) Action[T] {
+	logging := loggingAction[T]{action: action}
+	if branchAction, isBranch := action.(BranchAction[T]); isBranch {
+		return &loggingBranchAction[T]{loggingAction: logging, branchAction: branchAction}
+	}
+	return &logging
+}
+
+type loggingAction[T any] struct {
+	action Action[T]
+}
+
+func (l loggingAction[T]) Name() string { return l.action.Name() }
+func (l loggingAction[T]) Run(ctx context.Context, input T) (output T, err error) {
+	started := traceStart(l.action.Name())
+	output, err = l.action.Run(ctx, input)
+	traceRun(l.action.Name(), started, err)
+	return output, err
+}
+
+type loggingBranchAction[T any] struct {
+	loggingAction[T]
+	branchAction BranchAction[T]
+}
+
+func (l loggingBranchAction[T]) Directions() []string { return l.branchAction.Directions() }
+func (l loggingBranchAction[T]) NextDirection(ctx context.Context, output T) (direction string, err error) {
+	direction, err = l.branchAction.NextDirection(ctx, output)
+	traceDirection(l.action.Name(), direction, err)
+	return direction, err
+}
+
+// traceStart logs the start of a run of the action named name, reporting when it started.
+func traceStart(name string) time.Time {
+	logrus.Infof("%s: Start running", name)
+	return time.Now()
+}
+
+// traceRun logs the end of a run of the action named name started at started.
+func traceRun(name string, started time.Time, err error) {
+	if err != nil {
+		logrus.Infof("%s: Failed in %s, caused by %s", name, time.Since(started), err)
+	} else {
+		logrus.Infof("%s: Finished in %s", name, time.Since(started))
+	}
+}
+
+// traceDirection logs the direction the action named name selected.
+func traceDirection(name, direction string, err error) {
+	if err != nil {
+		logrus.Infof("%s: Failed to select direction, caused by %s", name, err)
+	} else {
+		logrus.Infof("%s: Directing `%s`", name, direction)
+	}
+}
+
+// EnableTracing makes the pipeline log each run of its member actions as NewLoggingAction does,
+// and returns the pipeline itself for chaining. The runs log the steps themselves without decorating
+// the members, so the members and their plans stay the same. DisableTracing stops the logging.
+func (p *Pipeline[T]) EnableTracing() *Pipeline[T] {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.tracing = true
+	return p
+}
+
+// DisableTracing stops the logging started by EnableTracing, and returns the pipeline itself.
+func (p *Pipeline[T]) DisableTracing() *Pipeline[T] {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.tracing = false
+	return p
+}
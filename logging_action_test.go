@@ -0,0 +1,59 @@
+package chain
+
+import (
+	"context"
+	"github.com/sirupsen/logrus"
+	"github.com/sirupsen/logrus/hooks/test"
+	"github.com/stretchr/testify/assert"
+	"strings"
+	"testing"
+)
+
+func TestPipeline_EnableTracing(t *testing.T) {
+	hook := test.NewGlobal()
+	defer logrus.StandardLogger().ReplaceHooks(logrus.LevelHooks{})
+	tracedMessages := func() []string {
+		var messages []string
+		for _, entry := range hook.AllEntries() {
+			if entry.Level == logrus.InfoLevel {
+				messages = append(messages, strings.SplitN(entry.Message, " in ", 2)[0])
+			}
+		}
+		hook.Reset()
+		return messages
+	}
+	collatz := NewCollatz("Collatz")
+
+	assert.Same(t, collatz.Pipeline, collatz.EnableTracing())
+	output, err := collatz.Run(context.Background(), 5)
+
+	assert.NoError(t, err)
+	assert.Equal(t, 16, output)
+	assert.Equal(t, []string{
+		"CheckNext: Start running",
+		"CheckNext: Finished",
+		"CheckNext: Directing `odd`",
+		"OnOdd: Start running",
+		"OnOdd: Finished",
+	}, tracedMessages())
+	assert.Len(t, collatz.Graph().Nodes(), 3)
+
+	collatz.DisableTracing()
+	_, err = collatz.Run(context.Background(), 5)
+
+	assert.NoError(t, err)
+	assert.Empty(t, tracedMessages())
+}
+
+func TestNewLoggingAction(t *testing.T) {
+	logging := NewLoggingAction[int](&ErrorMaker{message: "failure"})
+	_, isBranch := logging.(BranchAction[int])
+	assert.False(t, isBranch)
+	_, err := logging.Run(context.Background(), 1)
+	assert.EqualError(t, err, "failure")
+
+	branch := NewLoggingAction[int](&RoutingAction{name: "Route", directions: []string{"left"}})
+	branchAction, isBranch := branch.(BranchAction[int])
+	assert.True(t, isBranch)
+	assert.Equal(t, []string{"left"}, branchAction.Directions())
+}
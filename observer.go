@@ -0,0 +1,83 @@
+package chain
+
+import (
+	"context"
+	"github.com/sirupsen/logrus"
+	"time"
+)
+
+// Observer receives the events of the runs of a pipeline, combining the concerns of observing runs
+// such as tracing, metrics and logging into a single attachment point registered by ObserveWith.
+type Observer[T any] interface {
+	// OnPipelineStart is called before the first action of each run.
+	OnPipelineStart(ctx context.Context, pipelineName string, input T)
+	// OnActionStart is called right before each action runs.
+	OnActionStart(ctx context.Context, actionName string, input T)
+	// OnActionEnd is called right after each action returns, with the direction it directed
+	// after aliases are applied, and the time it took.
+	OnActionEnd(ctx context.Context, actionName string, output T, direction string, err error, elapsed time.Duration)
+	// OnPipelineEnd is called once at the end of each run, with the result the run returns.
+	OnPipelineEnd(ctx context.Context, pipelineName string, output T, direction string, err error)
+}
+
+// CompositeObserver combines multiple observers into one, delivering each event to all of them
+// in order. A panic on an observer is recovered and logged, without affecting the others.
+type CompositeObserver[T any] []Observer[T]
+
+// OnPipelineStart delivers the event to each observer.
+func (c CompositeObserver[T]) OnPipelineStart(ctx context.Context, pipelineName string, input T) {
+	for _, observer := range c {
+		callObserver(pipelineName, func() { observer.OnPipelineStart(ctx, pipelineName, input) })
+	}
+}
+
+// OnActionStart delivers the event to each observer.
+func (c CompositeObserver[T]) OnActionStart(ctx context.Context, actionName string, input T) {
+	for _, observer := range c {
+		callObserver(actionName, func() { observer.OnActionStart(ctx, actionName, input) })
+	}
+}
+
+// OnActionEnd delivers the event to each observer.
+func (c CompositeObserver[T]) OnActionEnd(ctx context.Context, actionName string, output T, direction string, err error, elapsed time.Duration) {
+	for _, observer := range c {
+		callObserver(actionName, func() { observer.OnActionEnd(ctx, actionName, output, direction, err, elapsed) })
+	}
+}
+
+// OnPipelineEnd delivers the event to each observer.
+func (c CompositeObserver[T]) OnPipelineEnd(ctx context.Context, pipelineName string, output T, direction string, err error) {
+	for _, observer := range c {
+		callObserver(pipelineName, func() { observer.OnPipelineEnd(ctx, pipelineName, output, direction, err) })
+	}
+}
+
+// ObserveWith registers the observer to receive the events of each run of the pipeline,
+// and returns the pipeline itself to allow chaining right after the constructor.
+// Observers are called in the order they were registered, as a CompositeObserver does,
+// and OnPipelineEnd is called after the hooks of OnTerminate. Nested pipelines deliver
+// the events of their own runs to their own observers.
+// A panic on an observer is recovered and logged, without affecting the run.
+func (p *Pipeline[T]) ObserveWith(observer Observer[T]) *Pipeline[T] {
+	if observer == nil {
+		return p
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	// Copy on write, so observing does not need to hold the lock
+	observers := make(CompositeObserver[T], len(p.observers), len(p.observers)+1)
+	copy(observers, p.observers)
+	p.observers = append(observers, observer)
+	return p
+}
+
+func callObserver(name string, fn func()) {
+	defer func() {
+		if panicErr := recover(); panicErr != nil {
+			logrus.Errorf("%s: panic occurred on observer, caused by %s", name, panicErr)
+		}
+	}()
+
+	fn()
+}
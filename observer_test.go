@@ -0,0 +1,114 @@
+package chain
+
+import (
+	"context"
+	"fmt"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+// recordingObserver records the events it receives as strings.
+type recordingObserver struct {
+	events []string
+}
+
+func (o *recordingObserver) OnPipelineStart(_ context.Context, pipelineName string, input int) {
+	o.events = append(o.events, fmt.Sprintf("start %s with %d", pipelineName, input))
+}
+
+func (o *recordingObserver) OnActionStart(_ context.Context, actionName string, input int) {
+	o.events = append(o.events, fmt.Sprintf("run %s with %d", actionName, input))
+}
+
+func (o *recordingObserver) OnActionEnd(_ context.Context, actionName string, output int, direction string, err error, _ time.Duration) {
+	o.events = append(o.events, fmt.Sprintf("end %s with %d directing %s (%v)", actionName, output, direction, err))
+}
+
+func (o *recordingObserver) OnPipelineEnd(_ context.Context, pipelineName string, output int, direction string, err error) {
+	o.events = append(o.events, fmt.Sprintf("end %s with %d directing %s (%v)", pipelineName, output, direction, err))
+}
+
+// panickingObserver panics on every event.
+type panickingObserver struct{}
+
+func (panickingObserver) OnPipelineStart(context.Context, string, int) { panic("broken observer") }
+func (panickingObserver) OnActionStart(context.Context, string, int)   { panic("broken observer") }
+func (panickingObserver) OnActionEnd(context.Context, string, int, string, error, time.Duration) {
+	panic("broken observer")
+}
+func (panickingObserver) OnPipelineEnd(context.Context, string, int, string, error) {
+	panic("broken observer")
+}
+
+func TestPipeline_ObserveWith(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("receives events of run", func(t *testing.T) {
+		observer := &recordingObserver{}
+		collatz := NewCollatz("Collatz")
+		assert.Same(t, collatz.Pipeline, collatz.ObserveWith(observer))
+
+		_, err := collatz.Run(ctx, 5)
+
+		assert.NoError(t, err)
+		assert.Equal(t, []string{
+			"start Collatz with 5",
+			"run CheckNext with 5",
+			"end CheckNext with 5 directing odd (<nil>)",
+			"run OnOdd with 5",
+			"end OnOdd with 16 directing success (<nil>)",
+			"end Collatz with 16 directing success (<nil>)",
+		}, observer.events)
+	})
+
+	t.Run("receives events of nested pipelines registered on them", func(t *testing.T) {
+		outerObserver, innerObserver := &recordingObserver{}, &recordingObserver{}
+		inner := NewPipeline("Inner", &ErrorMaker{message: "error1"}).ObserveWith(innerObserver)
+		outer := NewPipeline("Outer", inner).ObserveWith(outerObserver)
+
+		_, _ = outer.Run(ctx, 1)
+
+		assert.Equal(t, []string{
+			"start Outer with 1",
+			"run Inner with 1",
+			"end Inner with 1 directing error (error1)",
+			"end Outer with 1 directing error (error1)",
+		}, outerObserver.events)
+		assert.Equal(t, []string{
+			"start Inner with 1",
+			"run error1 with 1",
+			"end error1 with 1 directing error (error1)",
+			"end Inner with 1 directing error (error1)",
+		}, innerObserver.events)
+	})
+
+	t.Run("recovers panics of observers", func(t *testing.T) {
+		observer := &recordingObserver{}
+		collatz := NewCollatz("Collatz")
+		collatz.ObserveWith(CompositeObserver[int]{panickingObserver{}, observer})
+		collatz.ObserveWith(panickingObserver{})
+
+		output, err := collatz.Run(ctx, 5)
+
+		assert.NoError(t, err)
+		assert.Equal(t, 16, output)
+		assert.Len(t, observer.events, 6)
+	})
+}
+
+func TestCompositeObserver(t *testing.T) {
+	first, second := &recordingObserver{}, &recordingObserver{}
+	pipeline := NewPipeline("Pipeline", &SetTen{}).ObserveWith(CompositeObserver[int]{first, second})
+
+	_, _ = pipeline.Run(context.Background(), 1)
+
+	expected := []string{
+		"start Pipeline with 1",
+		"run SetTen with 1",
+		"end SetTen with 10 directing success (<nil>)",
+		"end Pipeline with 10 directing success (<nil>)",
+	}
+	assert.Equal(t, expected, first.events)
+	assert.Equal(t, expected, second.events)
+}
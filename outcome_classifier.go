@@ -0,0 +1,115 @@
+package chain
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+)
+
+// OutcomeClassifier maps the outcomes of runs to categories, such as the exit codes of processes
+// or the status codes of HTTP responses, so every caller of the pipeline agrees on them.
+// A new classifier maps Success to 0, Error to 1 and Abort to 2.
+//
+// The outcomes of runs with errors are mapped by the classes of their errors first,
+// and the outcomes not mapped by the classes are mapped by their directions.
+type OutcomeClassifier struct {
+	directions map[string]int
+	classes    map[ErrorClass]int
+}
+
+// NewOutcomeClassifier creates a classifier mapping Success to 0, Error to 1 and Abort to 2.
+func NewOutcomeClassifier() *OutcomeClassifier {
+	return &OutcomeClassifier{
+		directions: map[string]int{Success: 0, Error: 1, Abort: 2},
+		classes:    map[ErrorClass]int{},
+	}
+}
+
+// MapDirection maps runs ending with the direction to the category,
+// and returns the classifier itself for chaining.
+func (c *OutcomeClassifier) MapDirection(direction string, category int) *OutcomeClassifier {
+	c.directions[direction] = category
+	return c
+}
+
+// MapErrorClass maps runs ending with an error of the class to the category, regardless of
+// their directions, and returns the classifier itself for chaining.
+func (c *OutcomeClassifier) MapErrorClass(class ErrorClass, category int) *OutcomeClassifier {
+	c.classes[class] = category
+	return c
+}
+
+// ErrUnmappedOutcome is the error of classifying an outcome which the classifier maps to no category.
+type ErrUnmappedOutcome struct {
+	Direction string
+	Class     ErrorClass
+}
+
+func (e *ErrUnmappedOutcome) Error() string {
+	return fmt.Sprintf("no category for outcome directing `%s` with %s", e.Direction, e.Class)
+}
+
+// Classify finds the category of the outcome. An *ErrUnmappedOutcome is returned
+// when the outcome is not mapped, rather than falling back to any category.
+func (c *OutcomeClassifier) Classify(direction string, err error, class ErrorClass) (int, error) {
+	if err != nil {
+		if category, exists := c.classes[class]; exists {
+			return category, nil
+		}
+	}
+	if category, exists := c.directions[direction]; exists {
+		return category, nil
+	}
+	return 0, &ErrUnmappedOutcome{Direction: direction, Class: class}
+}
+
+// SetOutcomeClassifier attaches the classifier to the pipeline, to be used by the Category of
+// the results of its runs. Setting nil restores the classifier of NewOutcomeClassifier.
+//
+// An error is returned without attaching the classifier when any custom direction planned to
+// terminate the pipeline is not mapped, as runs can end with it.
+func (p *Pipeline[T]) SetOutcomeClassifier(classifier *OutcomeClassifier) error {
+	if classifier != nil {
+		terminate := Terminate[T]()
+		for _, action := range p.members {
+			var unmapped []string
+			for direction, nextAction := range p.planOf(action) {
+				if _, mapped := classifier.directions[direction]; nextAction == terminate && !mapped {
+					unmapped = append(unmapped, direction)
+				}
+			}
+			if len(unmapped) > 0 {
+				sort.Strings(unmapped)
+				return fmt.Errorf("direction `%s` terminating from `%s` is not mapped", unmapped[0], action.Name())
+			}
+		}
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.outcomeClassifier = classifier
+	return nil
+}
+
+func (p *Pipeline[T]) currentOutcomeClassifier() *OutcomeClassifier {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	if p.outcomeClassifier == nil {
+		return defaultOutcomeClassifier
+	}
+	return p.outcomeClassifier
+}
+
+// defaultOutcomeClassifier classifies the results of the pipelines without their own classifiers.
+// It is shared by the results, so it must not be modified.
+var defaultOutcomeClassifier = NewOutcomeClassifier()
+
+// Category classifies the outcome of the run with the classifier of the pipeline set by
+// SetOutcomeClassifier, such as for the exit code of a batch driver.
+// An *ErrUnmappedOutcome is returned when the outcome is not mapped to any category.
+func (r RunResult[T]) Category() (int, error) {
+	if r.outcomeClassifier == nil {
+		return 0, errors.New("result was not made by a run")
+	}
+	return r.outcomeClassifier.Classify(r.Direction, r.Err, r.ErrorClass)
+}
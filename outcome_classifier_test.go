@@ -0,0 +1,64 @@
+package chain
+
+import (
+	"context"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestOutcomeClassifier(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("classifies directions by default", func(t *testing.T) {
+		collatz := NewCollatz("Collatz")
+		failing := NewPipeline("Pipeline", &ErrorMaker{message: "error1"})
+
+		category, err := collatz.RunWithTrace(ctx, 5).Category()
+		assert.NoError(t, err)
+		assert.Equal(t, 0, category)
+
+		category, err = failing.RunWithTrace(ctx, 5).Category()
+		assert.NoError(t, err)
+		assert.Equal(t, 1, category)
+
+		canceled, cancel := context.WithCancel(ctx)
+		cancel()
+		category, err = collatz.RunWithTrace(canceled, 5).Category()
+		assert.NoError(t, err)
+		assert.Equal(t, 2, category)
+	})
+
+	t.Run("classifies error classes before directions", func(t *testing.T) {
+		timingOut := NewSimpleAction("TimingOut", func(_ context.Context, input int) (int, error) {
+			return input, context.DeadlineExceeded
+		})
+		pipeline := NewPipeline("Pipeline", timingOut)
+		assert.NoError(t, pipeline.SetOutcomeClassifier(NewOutcomeClassifier().MapErrorClass(ErrorClassTransient, 75)))
+
+		result := <-pipeline.RunInBackground(ctx, 1)
+		category, err := result.Category()
+
+		assert.NoError(t, err)
+		assert.Equal(t, ErrorClassTransient, result.ErrorClass)
+		assert.Equal(t, 75, category)
+	})
+
+	t.Run("classifies custom directions", func(t *testing.T) {
+		routing := &RoutingAction{name: "Routing", directions: []string{"quarantine"}}
+		pipeline := NewPipeline("Pipeline", Action[int](routing))
+
+		assert.EqualError(t, pipeline.SetOutcomeClassifier(NewOutcomeClassifier()),
+			"direction `quarantine` terminating from `Routing` is not mapped")
+		assert.NoError(t, pipeline.SetOutcomeClassifier(NewOutcomeClassifier().MapDirection("quarantine", 3)))
+	})
+
+	t.Run("fails on unmapped outcomes", func(t *testing.T) {
+		classifier := NewOutcomeClassifier()
+
+		_, err := classifier.Classify("quarantine", nil, ErrorClassUnknown)
+
+		assert.EqualError(t, err, "no category for outcome directing `quarantine` with ErrorClassUnknown")
+		_, err = RunResult[int]{Direction: Success}.Category()
+		assert.EqualError(t, err, "result was not made by a run")
+	})
+}
@@ -6,6 +6,9 @@ import (
 	"fmt"
 	"github.com/sirupsen/logrus"
 	"runtime/debug"
+	"sync"
+	"sync/atomic"
+	"time"
 )
 
 // Pipeline represents a sequence of Actions that are executed in a structured flow.
@@ -18,9 +21,70 @@ import (
 // This allows Pipelines to be composed hierarchically, enabling more complex workflows by nesting
 // Pipelines within other Pipelines.
 type Pipeline[T any] struct {
-	name       string
-	runPlans   map[Action[T]]ActionPlan[T]
-	initAction Action[T]
+	name        string
+	runPlans    map[Action[T]]ActionPlan[T]
+	members     []Action[T]
+	memberIndex map[Action[T]]int
+	initAction  Action[T]
+
+	// planSources records how the directions of each action were planned,
+	// where directions not recorded are left on defaults
+	planSources map[Action[T]]map[string]PlanSource
+	retries     map[Action[T]]int
+	notifiers   []registeredNotifier[T]
+	// terminateHooks are called at the end of each run, registered by OnTerminate
+	terminateHooks []func(ctx context.Context, output T, direction string, err error)
+	// observers receive the events of each run, registered by ObserveWith
+	observers CompositeObserver[T]
+	// taps are the sinks of the outputs of the actions, added by TeeAction
+	taps map[Action[T]][]func(T) error
+
+	directionHooks   []registeredDirectionHook[T]
+	directionAliases map[string]string
+
+	// circularGuard is the number of repeats allowed by AddCircularGuard, or nil when not added
+	circularGuard *int
+	// fallback runs with the input of the runs ending with Error, or nil when not set
+	fallback *Pipeline[T]
+
+	// terminateAction is run in place of termination, or nil when not set by SetTerminateAction
+	terminateAction Action[T]
+	// errorClassifier classifies the errors of actions, or nil for ClassifyError
+	errorClassifier func(error) ErrorClass
+	stepInterval    time.Duration
+	postCondition   *postCondition[T]
+	// traceCapacity bounds the steps kept by traces, or 0 to keep them all
+	traceCapacity int
+
+	outcomeClassifier *OutcomeClassifier
+
+	finalDirectionPolicy   FinalDirectionPolicy
+	unknownDirectionPolicy UnknownDirectionPolicy
+	directionCheck         bool
+	tracing                bool
+	// frozen drops the default plans from runPlans, and makes runs take the fast path when allowed.
+	// Runs select the next actions from compiled, which is compiled from runPlans on demand,
+	// and cleared whenever runPlans change
+	frozen   bool
+	compiled atomic.Pointer[compiledPlans]
+
+	stats   *Stats
+	metrics *pipelineMetrics
+
+	annotations map[Action[T]]map[string]string
+
+	// mu guards the fields above except members and memberIndex, which never change after construction,
+	// as they can be changed while the pipeline is running
+	mu sync.RWMutex
+	// recorders pools the buffers recording traces, which are never shared between runs
+	recorders sync.Pool
+	// runnerPaths interns the names of the runners of frozen runs nested in other runs
+	runnerPaths runnerPaths
+	// inflight counts the runs in progress for Drain, where runs are added while holding drainMu
+	// for reading, so no run is added once draining
+	drainMu  sync.RWMutex
+	draining bool
+	inflight sync.WaitGroup
 }
 
 // NewPipeline creates a new Pipeline by taking a series of Actions as its members.
@@ -35,37 +99,31 @@ func NewPipeline[T any](name string, memberActions ...Action[T]) *Pipeline[T] {
 	}
 
 	p := &Pipeline[T]{
-		name:       name,
-		runPlans:   map[Action[T]]ActionPlan[T]{},
-		initAction: memberActions[0],
+		name:        name,
+		runPlans:    make(map[Action[T]]ActionPlan[T], len(memberActions)),
+		members:     append([]Action[T](nil), memberActions...),
+		memberIndex: make(map[Action[T]]int, len(memberActions)),
+		initAction:  memberActions[0],
+
+		planSources: map[Action[T]]map[string]PlanSource{},
+		frozen:      freezeNewPipelines,
 	}
+	p.stats = newStats(func() (string, string) { return p.Name(), p.fingerprint() }, p.memberNames)
 
 	terminate := Terminate[T]()
 	for i, action := range memberActions {
 		if action == terminate {
 			panic(errors.New("do not set terminate as a member"))
 		}
-		if _, exists := p.runPlans[action]; exists {
+		if _, exists := p.memberIndex[action]; exists {
 			panic(fmt.Errorf("duplicate action specified on actions argument %d", i+1))
 		}
+		p.memberIndex[action] = i
 
-		nextAction := terminate
-		if i+1 < len(memberActions) {
-			nextAction = memberActions[i+1]
+		// Frozen pipelines build the default plans on demand
+		if !p.frozen {
+			p.runPlans[action] = p.defaultPlan(i)
 		}
-
-		defaultPlan := ActionPlan[T]{}
-		availableDirections := []string{Success, Error, Abort}
-		if branchAction, isBranchAction := action.(BranchAction[T]); isBranchAction {
-			availableDirections = append(availableDirections, branchAction.Directions()...)
-		}
-		for _, direction := range availableDirections {
-			if _, exists := defaultPlan[direction]; !exists {
-				defaultPlan[direction] = terminate
-			}
-		}
-		defaultPlan[Success] = nextAction
-		p.runPlans[action] = defaultPlan
 	}
 
 	return p
@@ -91,61 +149,206 @@ func (p *Pipeline[T]) SetRunPlan(currentAction Action[T], plan ActionPlan[T]) {
 		panic(fmt.Errorf("`%s` is not a member of this pipeline", currentAction.Name()))
 	}
 
-	// When given plan is nil, make currentAction to terminate on any cases
-	if plan == nil {
-		plan = ActionPlan[T]{}
+	plan, sources, err := p.completePlan(currentAction, plan)
+	if err != nil {
+		panic(err)
 	}
 
+	p.mu.Lock()
+	p.runPlans[currentAction] = plan
+	p.planSources[currentAction] = sources
+	p.compiled.Store(nil)
+	p.mu.Unlock()
+}
+
+// SetRunPlanBulk replaces the plans of the given actions as SetRunPlan does for each of them,
+// all at once under a single lock, so concurrent runs never see the pipeline partially reconfigured.
+// It suits reconfiguring a live pipeline with multiple plans depending on each other.
+//
+// The plans are validated as a whole before any is applied, so an error is returned without
+// changing the pipeline when any action is not a member, or any plan is rejected by SetRunPlan.
+func (p *Pipeline[T]) SetRunPlanBulk(plans map[Action[T]]ActionPlan[T]) error {
+	return p.swapPlans(plans)
+}
+
+// completePlan prepares the plan given to SetRunPlan for currentAction to be stored,
+// along with the sources of its directions, validating it with members.
+func (p *Pipeline[T]) completePlan(currentAction Action[T], plan ActionPlan[T]) (ActionPlan[T], map[string]PlanSource, error) {
+	sources := make(map[string]PlanSource, len(plan))
+	for direction := range plan {
+		sources[direction] = PlanSetRunPlan
+	}
+
+	// Copy the given plan, so changing it afterward does not affect the pipeline.
+	// When given plan is nil, make currentAction to terminate on any cases
+	plan = clonePlan(plan)
+
 	// Set next action to terminate when allowed directions were not specified in plan
 	terminate := Terminate[T]()
-	availableDirections := []string{Success, Error, Abort}
-	if branchAction, isBranchAction := currentAction.(BranchAction[T]); isBranchAction {
-		availableDirections = append(availableDirections, branchAction.Directions()...)
-	}
-	for _, direction := range append(availableDirections) {
+	availableDirections := p.plannedDirectionsOf(currentAction)
+	for _, direction := range availableDirections {
 		if _, exists := plan[direction]; !exists {
 			plan[direction] = terminate
 		}
 	}
 
 	// Validate given plan with members
-	var err error
 	for direction, nextAction := range plan {
 		if nextAction == terminate {
 			continue
 		}
+		if err := p.validateEdge(currentAction, availableDirections, direction, nextAction); err != nil {
+			return nil, nil, err
+		}
+	}
 
-		// If the direction is not in currentAction's valid directions, panic
-		if !contains(availableDirections, direction) {
-			err = fmt.Errorf("`%s` does not support direction `%s`", currentAction.Name(), direction)
-		} else if !isMemberActionInPipeline(nextAction, p) {
-			err = fmt.Errorf("setting plan from `%s` directing `%s` to non-member `%s`", currentAction.Name(), direction, nextAction.Name())
-		} else if nextAction == currentAction {
-			err = fmt.Errorf("setting self loop plan with `%s` directing `%s`", currentAction.Name(), direction)
+	return plan, sources, nil
+}
+
+// swapPlans replaces the plans of the given actions at once, as SetRunPlan does for each of them,
+// so runs never see only a part of them replaced.
+// When any of the plans is invalid, an error is returned without replacing any.
+func (p *Pipeline[T]) swapPlans(plans map[Action[T]]ActionPlan[T]) error {
+	for action := range plans {
+		if action == nil {
+			return errors.New("cannot set plan for terminate")
+		} else if !isMemberActionInPipeline(action, p) {
+			return fmt.Errorf("`%s` is not a member of this pipeline", action.Name())
 		}
+	}
 
-		if err != nil {
-			panic(err)
+	completed := make(map[Action[T]]ActionPlan[T], len(plans))
+	sources := make(map[Action[T]]map[string]PlanSource, len(plans))
+	for _, action := range p.members {
+		plan, exists := plans[action]
+		if !exists {
+			continue
+		}
+		var err error
+		if completed[action], sources[action], err = p.completePlan(action, plan); err != nil {
+			return err
 		}
 	}
 
-	p.runPlans[currentAction] = plan
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	for action, plan := range completed {
+		p.runPlans[action] = plan
+		p.planSources[action] = sources[action]
+	}
+	p.compiled.Store(nil)
+
+	return nil
+}
+
+// validateEdge checks whether currentAction can direct to nextAction on the direction.
+func (p *Pipeline[T]) validateEdge(currentAction Action[T], availableDirections []string, direction string, nextAction Action[T]) error {
+	if !contains(availableDirections, direction) {
+		return fmt.Errorf("`%s` does not support direction `%s`", currentAction.Name(), direction)
+	} else if !isMemberActionInPipeline(nextAction, p) {
+		return fmt.Errorf("setting plan from `%s` directing `%s` to non-member `%s`", currentAction.Name(), direction, nextAction.Name())
+	} else if nextAction == currentAction {
+		return fmt.Errorf("setting self loop plan with `%s` directing `%s`", currentAction.Name(), direction)
+	}
+	return nil
+}
+
+// entryAction returns the action runs start with.
+func (p *Pipeline[T]) entryAction() Action[T] {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	return p.initAction
+}
+
+// planOf returns the current plan of the action, which must not be modified.
+func (p *Pipeline[T]) planOf(action Action[T]) ActionPlan[T] {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	return p.planOfLocked(action)
+}
+
+func clonePlan[T any](plan ActionPlan[T]) ActionPlan[T] {
+	cloned := make(ActionPlan[T], len(plan))
+	for direction, nextAction := range plan {
+		cloned[direction] = nextAction
+	}
+	return cloned
 }
 
 // Name provides the identifier of this Pipeline.
-func (p *Pipeline[T]) Name() string { return p.name }
+func (p *Pipeline[T]) Name() string {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	return p.name
+}
+
+// WithName changes the identifier of this Pipeline, and returns the pipeline itself
+// to allow chaining right after the constructor, such as NewPipeline("tmp", a, b).WithName("production").
+// The name can be changed while the pipeline is running, which takes effect from the next run.
+//
+// If the name is empty, a panic will occur.
+func (p *Pipeline[T]) WithName(name string) *Pipeline[T] {
+	if name == "" {
+		panic(errors.New("pipeline must have a name"))
+	}
+
+	p.mu.Lock()
+	p.name = name
+	p.mu.Unlock()
+
+	return p
+}
+
+// SetInitAction changes the action runs start with, which is the first member given to the
+// constructor by default, without reconstructing the pipeline. The change takes effect from the next run.
+//
+// An error is returned when the action is not a member of the pipeline.
+func (p *Pipeline[T]) SetInitAction(action Action[T]) error {
+	if action == nil {
+		return errors.New("cannot start with terminate")
+	} else if !isMemberActionInPipeline(action, p) {
+		return fmt.Errorf("`%s` is not a member of this pipeline", action.Name())
+	}
+
+	p.mu.Lock()
+	p.initAction = action
+	p.mu.Unlock()
+
+	return nil
+}
+
+// Contains reports whether the action is a member of this Pipeline.
+// Actions inside member pipelines are not members of this Pipeline, which DeepContains looks for.
+func (p *Pipeline[T]) Contains(action Action[T]) bool {
+	return isMemberActionInPipeline(action, p)
+}
+
+// DeepContains reports whether the action is a member of this Pipeline,
+// or of any pipeline nested in it as a member, at any depth.
+func (p *Pipeline[T]) DeepContains(action Action[T]) bool {
+	if p.Contains(action) {
+		return true
+	}
+	for _, member := range p.members {
+		if nested, isPipeline := member.(*Pipeline[T]); isPipeline && nested.DeepContains(action) {
+			return true
+		}
+	}
+	return false
+}
 
 // Run executes the Pipeline by running Actions in the order they were configured,
 // starting from the initAction, which is the first one of the memberActions provided
-// by the constructor such as NewPipeline.
+// by the constructor such as NewPipeline, unless changed by SetInitAction.
 // The actions are executed in order, passing the output of one action as input to the next.
 func (p *Pipeline[T]) Run(ctx context.Context, input T) (output T, err error) {
-	if len(p.runPlans) == 1 {
-		output, _, err = runAction(p.initAction, ctx, input)
-		return output, err
-	}
+	output, _, err = p.runFallingBack(ctx, input)
+	return output, err
+}
 
-	return p.RunAt(p.initAction, ctx, input)
+// run runs the pipeline as Run describes, additionally reporting the direction the run ended with.
+func (p *Pipeline[T]) run(ctx context.Context, input T) (output T, direction string, err error) {
+	return p.runAt(p.entryAction(), ctx, input, nil)
 }
 
 // RunAt starts the execution of the pipeline from a given Action (initAction).
@@ -153,73 +356,418 @@ func (p *Pipeline[T]) Run(ctx context.Context, input T) (output T, err error) {
 // If an action returns an error, the pipeline will proceed to the next action according to
 // the defined plan, potentially directing the flow to an action mapped for the Error direction.
 // The Abort direction, when encountered, will immediately halt the pipeline execution unless
-// the plan specifies otherwise.
-// If no action plan is found for a given direction,
-// the pipeline will terminate with the appropriate error.
+// the plan specifies otherwise. When ctx is done, the pipeline aborts before running the next action.
+// If no action plan is found for a given direction, the run is handled as
+// SetUnknownDirectionPolicy configures, aborting with an *ErrUnknownDirection by default.
 func (p *Pipeline[T]) RunAt(initAction Action[T], ctx context.Context, input T) (output T, lastErr error) {
+	output, direction, lastErr := p.runAt(initAction, ctx, input, nil)
+	output, _, lastErr = p.fallBack(ctx, input, output, direction, lastErr)
+	return output, lastErr
+}
+
+// runAt runs the pipeline as RunAt describes, additionally reporting the direction the run ended with.
+// When hooks are given, they observe each step of the run.
+func (p *Pipeline[T]) runAt(initAction Action[T], ctx context.Context, input T, hooks *runHooks[T]) (output T, direction string, lastErr error) {
+	// A single frame per run is the ctx of the steps, carrying the name of the runner and branch data,
+	// so the steps of a run do not allocate contexts of their own
+	config := p.runConfig()
+	parent, _ := ctx.Value(runFrameKey).(*runFrame)
+	frame := &runFrame{Context: ctx, parent: parent, name: config.name}
 	if !isMemberActionInPipeline(initAction, p) {
-		return input, errors.New("given initAction is not registered on constructor")
+		initErr, initName := errors.New("given initAction is not registered on constructor"), ""
+		if initAction != nil {
+			initName = initAction.Name()
+		}
+		return input, Error, newPipelineError(frame.runnerName(), initName, initErr, initErr)
+	}
+	if !p.startRun() {
+		return input, Abort, newPipelineError(frame.runnerName(), initAction.Name(), ErrDraining, ErrDraining)
 	}
+	defer p.inflight.Done()
 
-	runnerName := p.name
-	if parentName := ctx.Value(parentRunner); parentName != nil {
-		runnerName = parentName.(string) + "/" + p.name
+	if hooks == nil && p.fastPath(frame, &config) {
+		return p.runFast(frame, initAction, input, &config)
 	}
-	ctx = context.WithValue(ctx, parentRunner, runnerName)
+	ctx, directionHooks := directionHooksFor(frame, config.directionHooks)
+	debugging := logrus.IsLevelEnabled(logrus.DebugLevel)
 
 	var (
-		terminate     = Terminate[T]()
-		currentAction Action[T]
-		nextAction    Action[T]
-		direction     string
-		runErr        error
-		selectErr     error
+		terminate            = Terminate[T]()
+		finalizer            = config.finalizer
+		classify             = config.classify
+		stepInterval         = config.stepInterval
+		postCondition        = config.postCondition
+		guard                = newCircularGuard[T](config.circularGuard)
+		policy               = config.finalDirectionPolicy
+		unknownDirection     = config.unknownDirectionPolicy
+		checkDirections      = config.directionCheck
+		metrics              = config.metrics
+		observers            = config.observers
+		taps                 = config.taps
+		aliases              = config.directionAliases
+		currentAction        Action[T]
+		lastAction           string
+		nextAction           Action[T]
+		currentIndex         = p.memberIndex[initAction]
+		nextIndex            = terminated
+		runErr               error
+		recovered            any
+		stepErrs             []*StepError
+		failedAction         string
+		failedErr            error
+		selectErr            *ErrUnknownDirection
+		branchData           any
+		terminatingDirection string
 	)
-	logrus.Debugf("%s: Start running with `%s`", runnerName, initAction.Name())
-	for currentAction = initAction; currentAction != nil; currentAction = nextAction {
-		output, direction, runErr = runAction(currentAction, ctx, input)
-
-		nextAction, selectErr = selectNextAction(p.runPlans[currentAction], currentAction, direction)
-		if selectErr != nil {
-			logrus.Error(selectErr)
-			direction = Abort
-			lastErr = selectErr
+	if parent != nil {
+		branchData = parent.handoff.received
+	}
+	if config.frozen {
+		frame.paths = &p.runnerPaths
+	}
+	if debugging {
+		logrus.Debugf("%s: Start running with `%s`", frame.runnerName(), initAction.Name())
+	}
+	if observers != nil {
+		observers.OnPipelineStart(ctx, frame.name, input)
+	}
+	for currentAction = initAction; currentAction != nil; currentAction, currentIndex = nextAction, nextIndex {
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			canceledErr := canceledErrorOf(ctx, ctxErr)
+			if debugging {
+				logrus.Debugf("%s: Aborting before `%s`, caused by %s", frame.runnerName(), currentAction.Name(), canceledErr)
+			}
+			output, direction, lastErr = input, Abort, joinRunErrors(stepErrs, canceledErr)
+			failedAction, failedErr = currentAction.Name(), canceledErr
+			break
+		}
+		if hooks != nil && hooks.stopBefore == currentAction {
+			if debugging {
+				logrus.Debugf("%s: Stopping before `%s`", frame.runnerName(), currentAction.Name())
+			}
+			hooks.stopped = true
+			if output = input; direction == "" {
+				direction = Success
+			}
+			break
+		}
+		if guardErr := guard.visit(currentAction); guardErr != nil {
+			logrus.Error(guardErr)
+			output, direction, lastErr = input, Abort, joinRunErrors(stepErrs, guardErr)
+			failedAction, failedErr = currentAction.Name(), guardErr
 			break
 		}
 
-		nextActionName := "termination"
+		var started time.Time
+		if metrics != nil || observers != nil || (hooks != nil && hooks.profiler != nil) {
+			started = time.Now()
+		}
+		if observers != nil {
+			observers.OnActionStart(ctx, currentAction.Name(), input)
+		}
+		frame.handoff = branchHandoff{received: branchData}
+		if stub, isStubbed := hooks.stubOf(currentAction); isStubbed {
+			output, direction, runErr, recovered = stub.result()
+		} else {
+			output, direction, runErr, recovered = p.runMember(&config, currentAction, ctx, input)
+		}
+		if sinks, isTapped := taps[currentAction]; isTapped {
+			tee(sinks, currentAction.Name(), output)
+		}
+		if runErr != nil && isCanceledBy(ctx, runErr) {
+			direction, runErr = Abort, canceledErrorOf(ctx, runErr)
+		} else if checkDirections {
+			direction, runErr = checkDirection(currentAction, direction, runErr)
+		}
+		originalDirection := ""
+		if alias, exists := aliases[direction]; exists {
+			originalDirection, direction = direction, alias
+		}
+		class := classifyError(classify, runErr)
+		lastAction = currentAction.Name()
+		if currentIndex != terminated {
+			p.stats.recordMemberStep(currentIndex, direction)
+		} else {
+			p.stats.recordStep(currentAction.Name(), direction)
+		}
+		metrics.record(currentAction.Name(), direction, class, time.Since(started))
+		if observers != nil {
+			observers.OnActionEnd(ctx, currentAction.Name(), output, direction, runErr, time.Since(started))
+		}
+		if hooks != nil {
+			hooks.observe(StepOutcome{
+				Action:            currentAction.Name(),
+				Direction:         direction,
+				OriginalDirection: originalDirection,
+				Err:               runErr,
+				Class:             class,
+				BranchData:        frame.handoff.attached,
+			}, started)
+			if hooks.collected != nil {
+				*hooks.collected = append(*hooks.collected, StepResult[T]{
+					Action:    currentAction.Name(),
+					Input:     input,
+					Output:    output,
+					Direction: direction,
+					Err:       runErr,
+				})
+			}
+			if recovered != nil && hooks.panicHandler != nil {
+				output, direction, lastErr = hooks.handlePanic(recovered)
+				p.complete(&config, ctx, output, direction, lastErr)
+				return output, direction, lastErr
+			}
+		}
+
+		fireDirectionHooks(ctx, directionHooks, currentAction.Name(), direction, output)
+
+		if currentAction == finalizer {
+			nextAction, direction = terminate, terminatingDirection
+		} else if nextAction, nextIndex, selectErr = p.selectNext(currentAction, currentIndex, direction); selectErr != nil {
+			selectErr.Pipeline = frame.runnerName()
+			if unknownDirection == UnknownDirectionPanic {
+				p.terminate(&config, ctx, output, Abort, selectErr)
+				panic(selectErr)
+			} else if unknownDirection != UnknownDirectionTerminate {
+				logrus.Error(selectErr)
+				direction = Abort
+				lastErr = joinRunErrors(stepErrs, selectErr)
+				failedAction, failedErr = currentAction.Name(), selectErr
+				break
+			}
+			logrus.Warn(selectErr)
+		}
+		if nextAction == terminate && finalizer != nil && currentAction != finalizer {
+			nextAction, nextIndex, terminatingDirection = finalizer, terminated, direction
+		}
+
+		branchData = nil
 		if nextAction != terminate {
-			nextActionName = nextAction.Name()
+			branchData = frame.handoff.attached
+		}
+		if debugging {
+			nextActionName := "termination"
+			if nextAction != terminate {
+				nextActionName = nextAction.Name()
+			}
+			logrus.Debugf("%s: `%s` directs `%s`, selecting `%s`", frame.runnerName(), currentAction.Name(), direction, nextActionName)
 		}
-		logrus.Debugf("%s: `%s` directs `%s`, selecting `%s`", runnerName, currentAction.Name(), direction, nextActionName)
 
 		input = output
 		if runErr != nil {
-			lastErr = runErr
+			stepErrs = append(stepErrs, &StepError{Action: currentAction.Name(), Err: runErr})
+			lastErr = joinRunErrors(stepErrs, nil)
+			failedAction, failedErr = currentAction.Name(), runErr
+		} else if policy == ClearedOnRecovery && currentAction != finalizer {
+			stepErrs, lastErr = nil, nil
+		}
+		if stepInterval > 0 && nextAction != terminate {
+			waitInterval(ctx, stepInterval)
 		}
 	}
-	if lastErr != nil && direction != Abort {
-		direction = Error
+	if lastErr != nil {
+		lastErr = newPipelineError(frame.runnerName(), failedAction, failedErr, lastErr)
 	}
+	// Runs stopped before an action report the direction of the preceding one, as the run is not over
+	if stopped := hooks != nil && hooks.stopped; !stopped {
+		if lastErr != nil && direction != Abort && policy != LastAction {
+			direction = Error
+		}
+		direction, lastErr = postCondition.check(frame, output, direction, lastErr)
+		// Violations of successful runs are the only errors of the run, ending it at the last action
+		var pipelineErr *PipelineError
+		if lastErr != nil && !errors.As(lastErr, &pipelineErr) {
+			lastErr = newPipelineError(frame.runnerName(), lastAction, lastErr, lastErr)
+		}
+	}
+	p.complete(&config, ctx, output, direction, lastErr)
 
-	return output, lastErr
+	return output, direction, lastErr
 }
 
-const parentRunner = "PipelineParentRunner"
+// runConfig is the configuration of the pipeline a run follows, taken at once as the run starts,
+// so the steps of the run never lock the pipeline. The plans are not taken, as changes of them
+// take effect on the next step of the runs in progress, while the others take effect from the next run.
+type runConfig[T any] struct {
+	name           string
+	retries        map[Action[T]]int
+	notifiers      []registeredNotifier[T]
+	terminateHooks []func(ctx context.Context, output T, direction string, err error)
+	observers      CompositeObserver[T]
+	taps           map[Action[T]][]func(T) error
+
+	directionHooks   []registeredDirectionHook[T]
+	directionAliases map[string]string
+	circularGuard    *int
+
+	finalizer     Action[T]
+	classify      func(error) ErrorClass
+	stepInterval  time.Duration
+	postCondition *postCondition[T]
+
+	finalDirectionPolicy   FinalDirectionPolicy
+	unknownDirectionPolicy UnknownDirectionPolicy
+	directionCheck         bool
+	tracing                bool
+	frozen                 bool
+	// fast tells whether nothing but Stats watches the steps, as fastPath requires
+	fast bool
+
+	metrics *pipelineMetrics
+}
 
-func selectNextAction[T any](plan ActionPlan[T], currentAction Action[T], direction string) (nextAction Action[T], err error) {
-	var (
-		terminate = Terminate[T]()
-		exist     bool
-	)
-	if plan == nil {
-		return terminate, fmt.Errorf("no action plan found for `%s`", currentAction.Name())
+// runConfig takes the configuration for a run. The fields taken are never changed in place,
+// but replaced on changes, so the run can keep them without holding the lock.
+func (p *Pipeline[T]) runConfig() runConfig[T] {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	classify := p.errorClassifier
+	if classify == nil {
+		classify = ClassifyError
 	}
-	if nextAction, exist = plan[direction]; !exist {
-		return terminate, fmt.Errorf("no action plan from `%s` directing `%s`", currentAction.Name(), direction)
+	return runConfig[T]{
+		name:                   p.name,
+		retries:                p.retries,
+		notifiers:              p.notifiers,
+		terminateHooks:         p.terminateHooks,
+		observers:              p.observers,
+		taps:                   p.taps,
+		directionHooks:         p.directionHooks,
+		directionAliases:       p.directionAliases,
+		circularGuard:          p.circularGuard,
+		finalizer:              p.terminateAction,
+		classify:               classify,
+		stepInterval:           p.stepInterval,
+		postCondition:          p.postCondition,
+		finalDirectionPolicy:   p.finalDirectionPolicy,
+		unknownDirectionPolicy: p.unknownDirectionPolicy,
+		directionCheck:         p.directionCheck,
+		tracing:                p.tracing,
+		frozen:                 p.frozen,
+		fast:                   p.frozen && p.fullRunFeatureLocked() == "",
+		metrics:                p.metrics,
 	}
+}
 
-	return nextAction, nil
+const (
+	runFrameKey  = "PipelineRunFrame"
+	parentRunner = "PipelineParentRunner"
+)
+
+// runFrame is the ctx given to the steps of a run, telling nested pipelines the name of the runner,
+// prefixed by the names of its parents, and carrying branch data between the steps.
+// The name of the runner is only built when it is read, such as on errors and debug logs,
+// so runs nobody observes never concatenate the names of the nested pipelines.
+type runFrame struct {
+	context.Context
+	parent  *runFrame
+	name    string
+	handoff branchHandoff
+	// paths interns the name of the runner when given, as the names of frozen runs are
+	paths *runnerPaths
+
+	runnerOnce sync.Once
+	runner     string
+	// runnerValue is runner boxed once for Value, only when interned by paths
+	runnerValue any
+}
+
+// Value answers the frame itself for runFrameKey, and the name of the runner
+// for parentRunner as the runs used to put it on ctx.
+func (f *runFrame) Value(key any) any {
+	switch key {
+	case runFrameKey:
+		return f
+	case parentRunner:
+		if runner := f.runnerName(); f.runnerValue == nil {
+			return runner
+		}
+		return f.runnerValue
+	}
+	return f.Context.Value(key)
+}
+
+func (f *runFrame) runnerName() string {
+	f.runnerOnce.Do(func() {
+		parent := ""
+		if f.parent != nil {
+			parent = f.parent.runnerName()
+		}
+		if f.paths != nil {
+			f.runnerValue = f.paths.join(parent, f.name)
+			f.runner = f.runnerValue.(string)
+		} else if parent != "" {
+			f.runner = parent + "/" + f.name
+		} else {
+			f.runner = f.name
+		}
+	})
+	return f.runner
+}
+
+// runHooks observe the steps of a run, where each of them is optional.
+type runHooks[T any] struct {
+	// trace records the outcome of each step, keeping only the last traceCapacity steps when positive,
+	// where traceNext is the oldest step to be overwritten once the steps reach the capacity
+	trace         *RunTrace
+	traceCapacity int
+	traceNext     int
+	// profiler is called with the time each action took
+	profiler func(name string, elapsed time.Duration)
+	// panicHandler ends the run with its results when an action panics
+	panicHandler func(recovered any) (T, string, error)
+	// stopBefore ends the run before running the action, setting stopped
+	stopBefore Action[T]
+	stopped    bool
+	// stubs replace the results of the actions with their names
+	stubs map[string]ActionStub[T]
+	// collected records the input and the result of each step
+	collected *[]StepResult[T]
+}
+
+func (h *runHooks[T]) observe(step StepOutcome, started time.Time) {
+	if h.trace != nil && h.traceCapacity > 0 && len(h.trace.Steps) == h.traceCapacity {
+		h.trace.Steps[h.traceNext] = step
+		h.traceNext = (h.traceNext + 1) % h.traceCapacity
+		h.trace.Truncated = true
+	} else if h.trace != nil {
+		h.trace.Steps = append(h.trace.Steps, step)
+	}
+	if h.profiler != nil {
+		h.profiler(step.Action, time.Since(started))
+	}
+}
+
+// stubOf finds the stub replacing the result of the action.
+func (h *runHooks[T]) stubOf(action Action[T]) (ActionStub[T], bool) {
+	if h == nil || h.stubs == nil {
+		return ActionStub[T]{}, false
+	}
+	stub, isStubbed := h.stubs[action.Name()]
+	return stub, isStubbed
+}
+
+// handlePanic calls panicHandler with the value recovered from an action,
+// raising the panic again when panicHandler panics as well.
+func (h *runHooks[T]) handlePanic(recovered any) (output T, direction string, err error) {
+	defer func() {
+		if handlerPanic := recover(); handlerPanic != nil {
+			logrus.Errorf("panic occurred on handling panic, caused by %s", handlerPanic)
+			panic(recovered)
+		}
+	}()
+
+	return h.panicHandler(recovered)
+}
+
+// directionsOf lists every direction the action can produce, starting with
+// Success, Error and Abort, followed by the custom directions of a BranchAction.
+func directionsOf[T any](action Action[T]) []string {
+	directions := []string{Success, Error, Abort}
+	if branchAction, isBranchAction := action.(BranchAction[T]); isBranchAction {
+		directions = append(directions, branchAction.Directions()...)
+	}
+	return directions
 }
 
 func contains(directions []string, direction string) bool {
@@ -232,11 +780,13 @@ func contains(directions []string, direction string) bool {
 }
 
 func isMemberActionInPipeline[T any](action Action[T], p *Pipeline[T]) bool {
-	_, exists := p.runPlans[action]
+	_, exists := p.memberIndex[action]
 	return exists
 }
 
-func runAction[T any](action Action[T], ctx context.Context, input T) (output T, direction string, runError error) {
+// runAction runs the action, and selects the direction to continue, logging them as NewLoggingAction does when tracing.
+// A panic of the action is recovered and reported with Abort, along with the recovered value.
+func runAction[T any](action Action[T], ctx context.Context, input T, tracing bool) (output T, direction string, runError error, recovered any) {
 	// Wrap panic handling for safe running in pipeline
 	defer func() {
 		if panicErr := recover(); panicErr != nil {
@@ -245,25 +795,44 @@ func runAction[T any](action Action[T], ctx context.Context, input T) (output T,
 
 			output = input
 			direction = Abort
-			switch x := panicErr.(type) {
-			case string:
-				runError = errors.New(x)
-			case error:
-				runError = x
-			default:
-				runError = errors.New("unknown panic type")
-			}
+			runError = panicToError(panicErr)
+			recovered = panicErr
 		}
 	}()
 
+	var started time.Time
+	if tracing {
+		started = traceStart(action.Name())
+	}
 	output, runError = action.Run(ctx, input)
+	if tracing {
+		traceRun(action.Name(), started, runError)
+	}
 	if runError != nil {
-		return output, Error, runError
+		return output, errorDirection(action, runError), runError, nil
 	}
 	direction = Success
 	if branchAction, isBranchAction := action.(BranchAction[T]); isBranchAction {
 		direction, runError = branchAction.NextDirection(ctx, output)
+		if tracing {
+			traceDirection(action.Name(), direction, runError)
+		}
+		if runError != nil && (direction == Error || direction == "") {
+			direction = errorDirection(action, runError)
+		}
 	}
 
-	return output, direction, runError
+	return output, direction, runError, nil
+}
+
+// panicToError converts a recovered value into an error to be returned as a result of running.
+func panicToError(panicErr any) error {
+	switch x := panicErr.(type) {
+	case string:
+		return errors.New(x)
+	case error:
+		return x
+	default:
+		return errors.New("unknown panic type")
+	}
 }
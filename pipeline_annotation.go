@@ -0,0 +1,47 @@
+package chain
+
+import (
+	"errors"
+	"fmt"
+)
+
+// Annotate attaches a key-value metadata to a member action of the pipeline,
+// such as the owner of the action or its latency budget.
+// Annotations do not affect running, but are shown on exports like ExportMarkdown.
+// Annotating the same key again overwrites the previous value.
+//
+// An error is returned when the action is not a member of the pipeline, or the key is empty.
+func (p *Pipeline[T]) Annotate(action Action[T], key, value string) error {
+	if action == nil {
+		return errors.New("cannot annotate terminate")
+	} else if !isMemberActionInPipeline(action, p) {
+		return fmt.Errorf("`%s` is not a member of this pipeline", action.Name())
+	}
+	if key == "" {
+		return fmt.Errorf("annotation key for `%s` must not be empty", action.Name())
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.annotations == nil {
+		p.annotations = map[Action[T]]map[string]string{}
+	}
+	if p.annotations[action] == nil {
+		p.annotations[action] = map[string]string{}
+	}
+	p.annotations[action][key] = value
+
+	return nil
+}
+
+// Annotations returns a copy of the annotations attached to the action by Annotate.
+// It returns an empty map when the action has no annotations or is not a member.
+func (p *Pipeline[T]) Annotations(action Action[T]) map[string]string {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	annotations := make(map[string]string, len(p.annotations[action]))
+	for key, value := range p.annotations[action] {
+		annotations[key] = value
+	}
+	return annotations
+}
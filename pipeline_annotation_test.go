@@ -0,0 +1,74 @@
+package chain
+
+import (
+	"fmt"
+	"github.com/stretchr/testify/assert"
+	"sync"
+	"testing"
+)
+
+func TestPipeline_Annotate(t *testing.T) {
+	t.Run("annotate members", func(t *testing.T) {
+		action1, action2 := &DirectingAction{name: "action1"}, &DirectingAction{name: "action2"}
+		pipeline := NewPipeline("pipeline", action1, action2)
+
+		assert.NoError(t, pipeline.Annotate(action1, "owner", "payments-team"))
+		assert.NoError(t, pipeline.Annotate(action1, "sla_ms", "50"))
+		assert.NoError(t, pipeline.Annotate(action1, "sla_ms", "100"))
+
+		assert.Equal(t, map[string]string{"owner": "payments-team", "sla_ms": "100"}, pipeline.Annotations(action1))
+		assert.Empty(t, pipeline.Annotations(action2))
+	})
+
+	t.Run("returned annotations are copies", func(t *testing.T) {
+		action1 := &DirectingAction{name: "action1"}
+		pipeline := NewPipeline("pipeline", action1)
+		assert.NoError(t, pipeline.Annotate(action1, "owner", "payments-team"))
+
+		pipeline.Annotations(action1)["owner"] = "someone-else"
+
+		assert.Equal(t, "payments-team", pipeline.Annotations(action1)["owner"])
+	})
+
+	t.Run("invalid annotations", func(t *testing.T) {
+		member, nonMember := &DirectingAction{name: "member"}, &DirectingAction{name: "non-member"}
+		pipeline := NewPipeline("pipeline", member)
+
+		assert.EqualError(t, pipeline.Annotate(nonMember, "owner", "team"), "`non-member` is not a member of this pipeline")
+		assert.EqualError(t, pipeline.Annotate(Terminate[int](), "owner", "team"), "cannot annotate terminate")
+		assert.EqualError(t, pipeline.Annotate(member, "", "team"), "annotation key for `member` must not be empty")
+	})
+
+	t.Run("annotations are exported", func(t *testing.T) {
+		action1 := &DirectingAction{name: "action1"}
+		pipeline := NewPipeline("pipeline", action1)
+		assert.NoError(t, pipeline.Annotate(action1, "sla_ms", "100"))
+		assert.NoError(t, pipeline.Annotate(action1, "owner", "payments-team"))
+
+		assert.Contains(t, pipeline.ExportMarkdown(), "Action.\n\n- `owner`: payments-team\n- `sla_ms`: 100\n\n| Direction |")
+	})
+
+	t.Run("annotating while exporting", func(t *testing.T) {
+		action1 := &DirectingAction{name: "action1"}
+		pipeline := NewPipeline("pipeline", action1)
+
+		var wg sync.WaitGroup
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			for i := 0; i < 100; i++ {
+				assert.NoError(t, pipeline.Annotate(action1, fmt.Sprintf("key%d", i), "value"))
+			}
+		}()
+		go func() {
+			defer wg.Done()
+			for i := 0; i < 100; i++ {
+				pipeline.ExportMarkdown()
+				pipeline.MemoryEstimate()
+			}
+		}()
+		wg.Wait()
+
+		assert.Len(t, pipeline.Annotations(action1), 100)
+	})
+}
@@ -0,0 +1,62 @@
+package chain
+
+import (
+	"context"
+	"runtime"
+	"sync"
+)
+
+// BatchOption customizes how batch runs such as RunMap run the pipeline.
+type BatchOption func(*batchConfig)
+
+type batchConfig struct {
+	workers int
+}
+
+// MaxWorkers limits the number of runs in progress at once, which is GOMAXPROCS by default.
+// Values less than 1 are ignored.
+func MaxWorkers(n int) BatchOption {
+	return func(c *batchConfig) {
+		if n > 0 {
+			c.workers = n
+		}
+	}
+}
+
+// RunMap runs the pipeline for each value of inputs concurrently, labeling each run by its key,
+// and reports the outputs, the directions the runs ended with, and the errors keyed by the labels.
+// Every key of inputs is present in all of the results, where the error is nil for successful runs.
+func (p *Pipeline[T]) RunMap(ctx context.Context, inputs map[string]T, opts ...BatchOption) (outputs map[string]T, directions map[string]string, errs map[string]error) {
+	config := batchConfig{workers: runtime.GOMAXPROCS(0)}
+	for _, opt := range opts {
+		opt(&config)
+	}
+
+	outputs = make(map[string]T, len(inputs))
+	directions = make(map[string]string, len(inputs))
+	errs = make(map[string]error, len(inputs))
+
+	var (
+		mu      sync.Mutex
+		wg      sync.WaitGroup
+		workers = make(chan struct{}, config.workers)
+	)
+	for key, input := range inputs {
+		wg.Add(1)
+		workers <- struct{}{}
+		go func(key string, input T) {
+			defer func() {
+				<-workers
+				wg.Done()
+			}()
+
+			output, direction, err := p.runFallingBack(ctx, input)
+			mu.Lock()
+			outputs[key], directions[key], errs[key] = output, direction, err
+			mu.Unlock()
+		}(key, input)
+	}
+	wg.Wait()
+
+	return outputs, directions, errs
+}
@@ -0,0 +1,49 @@
+package chain
+
+import (
+	"context"
+	"github.com/stretchr/testify/assert"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestPipeline_RunMap(t *testing.T) {
+	t.Run("results keyed by labels", func(t *testing.T) {
+		collatz := NewCollatz("Collatz")
+
+		outputs, directions, errs := collatz.RunMap(context.Background(), map[string]int{"five": 5, "sixteen": 16, "zero": 0})
+
+		assert.Equal(t, map[string]int{"five": 16, "sixteen": 8, "zero": 0}, outputs)
+		assert.Equal(t, Success, directions["five"])
+		assert.Equal(t, Success, directions["sixteen"])
+		assert.NoError(t, errs["five"])
+		assert.Len(t, errs, 3)
+	})
+
+	t.Run("limit workers", func(t *testing.T) {
+		var running, maxRunning atomic.Int32
+		pipeline := NewPipeline("Pipeline", NewSimpleAction("Sleep", func(_ context.Context, input int) (int, error) {
+			current := running.Add(1)
+			defer running.Add(-1)
+			for {
+				observed := maxRunning.Load()
+				if current <= observed || maxRunning.CompareAndSwap(observed, current) {
+					break
+				}
+			}
+			time.Sleep(5 * time.Millisecond)
+			return input * 2, nil
+		}))
+		inputs := map[string]int{}
+		for _, key := range []string{"a", "b", "c", "d", "e", "f"} {
+			inputs[key] = len(key)
+		}
+
+		outputs, _, _ := pipeline.RunMap(context.Background(), inputs, MaxWorkers(2))
+
+		assert.Len(t, outputs, 6)
+		assert.Equal(t, 2, outputs["a"])
+		assert.LessOrEqual(t, maxRunning.Load(), int32(2))
+	})
+}
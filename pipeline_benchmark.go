@@ -0,0 +1,86 @@
+package chain
+
+import (
+	"context"
+	"math"
+	"sort"
+	"time"
+)
+
+// BenchmarkResult summarizes the wall-clock durations measured by RunBenchmark.
+type BenchmarkResult struct {
+	Min  time.Duration
+	Max  time.Duration
+	Mean time.Duration
+	P50  time.Duration
+	P95  time.Duration
+	P99  time.Duration
+}
+
+// RunBenchmark runs the pipeline n times with the same input and measures the duration of each run.
+// The first run is treated as a warm-up and discarded, so n should be at least 2 to get any measurement.
+// Outputs and errors of the runs are ignored; only the latency profile is reported.
+//
+// This is a lightweight in-process benchmark, meant to get a rough latency profile of a pipeline
+// without setting up `go test -bench`.
+func (p *Pipeline[T]) RunBenchmark(ctx context.Context, input T, n int) BenchmarkResult {
+	if n < 2 {
+		return BenchmarkResult{}
+	}
+
+	durations := make([]time.Duration, 0, n-1)
+	for i := 0; i < n; i++ {
+		start := time.Now()
+		_, _ = p.Run(ctx, input)
+		elapsed := time.Since(start)
+		if i == 0 {
+			continue
+		}
+		durations = append(durations, elapsed)
+	}
+
+	return summarizeDurations(durations)
+}
+
+func summarizeDurations(durations []time.Duration) BenchmarkResult {
+	if len(durations) == 0 {
+		return BenchmarkResult{}
+	}
+
+	sorted := append([]time.Duration(nil), durations...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+
+	var total time.Duration
+	for _, duration := range sorted {
+		total += duration
+	}
+
+	return BenchmarkResult{
+		Min:  sorted[0],
+		Max:  sorted[len(sorted)-1],
+		Mean: total / time.Duration(len(sorted)),
+		P50:  percentile(sorted, 50),
+		P95:  percentile(sorted, 95),
+		P99:  percentile(sorted, 99),
+	}
+}
+
+// percentile picks the value of given rank from sorted durations, using the nearest-rank method.
+func percentile(sorted []time.Duration, rank float64) time.Duration {
+	index := int(math.Ceil(rank/100*float64(len(sorted)))) - 1
+	if index < 0 {
+		index = 0
+	}
+	return sorted[index]
+}
+
+// Profile runs the pipeline as Run does, calling profiler with the name of each action and the
+// time it took right after the action finishes, for performance test harnesses which do not need
+// the full trace of RunWithTrace. Actions of member pipelines are profiled as a whole.
+//
+// The profiler is called synchronously, so it must be fast. Unlike actions,
+// a panic on the profiler is not recovered, and propagates to the caller.
+func (p *Pipeline[T]) Profile(ctx context.Context, input T, profiler func(name string, elapsed time.Duration)) (output T, err error) {
+	output, _, err = p.runAt(p.entryAction(), ctx, input, &runHooks[T]{profiler: profiler})
+	return output, err
+}
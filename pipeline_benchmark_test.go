@@ -0,0 +1,340 @@
+package chain
+
+import (
+	"context"
+	"fmt"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"io"
+	"os"
+	"runtime"
+	"testing"
+	"time"
+)
+
+func TestPipeline_RunBenchmark(t *testing.T) {
+	t.Run("measures runs except warm-up", func(t *testing.T) {
+		runs := 0
+		counter := NewSimpleAction("Counter", func(_ context.Context, input int) (int, error) {
+			runs++
+			return input, nil
+		})
+		pipeline := NewPipeline("Benchmark", counter)
+
+		result := pipeline.RunBenchmark(context.Background(), 1, 20)
+
+		assert.Equal(t, 20, runs)
+		assert.LessOrEqual(t, result.Min, result.P50)
+		assert.LessOrEqual(t, result.P50, result.P95)
+		assert.LessOrEqual(t, result.P95, result.P99)
+		assert.LessOrEqual(t, result.P99, result.Max)
+		assert.LessOrEqual(t, result.Min, result.Mean)
+		assert.LessOrEqual(t, result.Mean, result.Max)
+	})
+
+	t.Run("too few runs to measure", func(t *testing.T) {
+		pipeline := NewPipeline("Benchmark", &SetTen{})
+
+		assert.Equal(t, BenchmarkResult{}, pipeline.RunBenchmark(context.Background(), 1, 1))
+	})
+
+	t.Run("percentiles by nearest rank", func(t *testing.T) {
+		durations := make([]time.Duration, 0, 100)
+		for i := 100; i >= 1; i-- {
+			durations = append(durations, time.Duration(i)*time.Millisecond)
+		}
+
+		result := summarizeDurations(durations)
+
+		assert.Equal(t, BenchmarkResult{
+			Min:  1 * time.Millisecond,
+			Max:  100 * time.Millisecond,
+			Mean: 50500 * time.Microsecond,
+			P50:  50 * time.Millisecond,
+			P95:  95 * time.Millisecond,
+			P99:  99 * time.Millisecond,
+		}, result)
+	})
+}
+
+func TestPipeline_Profile(t *testing.T) {
+	t.Run("profile each action", func(t *testing.T) {
+		collatz := NewCollatz("Collatz")
+		var names []string
+
+		output, err := collatz.Profile(context.Background(), 5, func(name string, elapsed time.Duration) {
+			names = append(names, name)
+			assert.GreaterOrEqual(t, elapsed, time.Duration(0))
+		})
+
+		assert.NoError(t, err)
+		assert.Equal(t, 16, output)
+		assert.Equal(t, []string{"CheckNext", "OnOdd"}, names)
+	})
+
+	t.Run("panic on profiler propagates", func(t *testing.T) {
+		collatz := NewCollatz("Collatz")
+
+		assert.PanicsWithValue(t, "broken profiler", func() {
+			_, _ = collatz.Profile(context.Background(), 5, func(string, time.Duration) {
+				panic("broken profiler")
+			})
+		})
+	})
+}
+
+// Benchmarks of runs are measured with logging disabled, as pipelines run in production.
+func BenchmarkRun_Linear3(b *testing.B) {
+	logrus.SetLevel(logrus.InfoLevel)
+	pipeline := NewPipeline[int]("Linear", &DirectingAction{name: "action1"}, &DirectingAction{name: "action2"}, &SetTen{})
+	ctx := context.Background()
+
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		_, _ = pipeline.Run(ctx, i)
+	}
+}
+
+func BenchmarkRun_Branching(b *testing.B) {
+	logrus.SetLevel(logrus.InfoLevel)
+	collatz := NewCollatz("Collatz")
+	ctx := context.Background()
+
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		_, _ = collatz.Run(ctx, i)
+	}
+}
+
+func BenchmarkRun_Nested(b *testing.B) {
+	logrus.SetLevel(logrus.InfoLevel)
+	inner := NewPipeline[int]("Inner", &DirectingAction{name: "action2"}, &SetTen{})
+	outer := NewPipeline[int]("Outer", &DirectingAction{name: "action1"}, inner)
+	ctx := context.Background()
+
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		_, _ = outer.Run(ctx, i)
+	}
+}
+
+// A pipeline nested 3 levels deep, run as it is and as compiled by CompileFlat,
+// which should cost about the same as a flat pipeline running the same steps.
+func BenchmarkRun_Nested3Levels(b *testing.B) {
+	logrus.SetLevel(logrus.InfoLevel)
+	deep := NewPipeline[int]("Deep", &DirectingAction{name: "action3"}, &SetTen{})
+	middle := NewPipeline[int]("Middle", &DirectingAction{name: "action2"}, deep)
+	outer := NewPipeline[int]("Outer", &DirectingAction{name: "action1"}, middle)
+	flat := NewPipeline[int]("Flat", &DirectingAction{name: "action1"}, &DirectingAction{name: "action2"},
+		&DirectingAction{name: "action3"}, &SetTen{})
+	compiled, err := outer.CompileFlat()
+	if err != nil {
+		b.Fatal(err)
+	}
+	ctx := context.Background()
+
+	b.Run("nested", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			_, _ = outer.Run(ctx, i)
+		}
+	})
+	b.Run("compiled", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			_, _, _ = compiled(ctx, i)
+		}
+	})
+	b.Run("flat", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			_, _ = flat.Run(ctx, i)
+		}
+	})
+}
+
+// The names of nested runners read by an action of a pipeline nested 3 levels deep,
+// which frozen runs build only on the first run, leaving only the frames of the runs allocated.
+func BenchmarkRun_NestedRunnerNames(b *testing.B) {
+	logrus.SetLevel(logrus.InfoLevel)
+	readRunner := NewSimpleAction("ReadRunner", func(ctx context.Context, input int) (int, error) {
+		_ = ctx.Value(parentRunner)
+		return input, nil
+	})
+	ctx := context.Background()
+
+	for _, frozen := range []bool{false, true} {
+		deep := NewPipeline("Deep", readRunner)
+		middle := NewPipeline[int]("Middle", deep)
+		outer := NewPipeline[int]("Outer", middle)
+		if frozen {
+			deep.Freeze()
+			middle.Freeze()
+			outer.Freeze()
+		}
+		_, _ = outer.Run(ctx, 0)
+
+		b.Run(fmt.Sprintf("frozen=%t", frozen), func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				_, _ = outer.Run(ctx, i)
+			}
+		})
+	}
+}
+
+// The per-step debug logs must cost nothing unless debug logging is enabled,
+// while the logs themselves are discarded on both to compare the formatting only.
+func BenchmarkRun_DebugLogging(b *testing.B) {
+	collatz := NewCollatz("Collatz")
+	ctx := context.Background()
+	logrus.SetOutput(io.Discard)
+	b.Cleanup(func() {
+		logrus.SetOutput(os.Stderr)
+		logrus.SetLevel(logrus.InfoLevel)
+	})
+
+	for _, debug := range []bool{false, true} {
+		b.Run(fmt.Sprintf("debug=%t", debug), func(b *testing.B) {
+			logrus.SetLevel(logrus.InfoLevel)
+			if debug {
+				logrus.SetLevel(logrus.DebugLevel)
+			}
+
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				_, _ = collatz.Run(ctx, i)
+			}
+		})
+	}
+}
+
+func BenchmarkRun_Large(b *testing.B) {
+	logrus.SetLevel(logrus.InfoLevel)
+	ctx := context.Background()
+
+	for _, frozen := range []bool{false, true} {
+		b.Run(fmt.Sprintf("frozen=%t", frozen), func(b *testing.B) {
+			actions := make([]Action[int], 200)
+			for i := range actions {
+				actions[i] = &DirectingAction{name: fmt.Sprintf("action%d", i)}
+			}
+			pipeline := NewPipeline("Large", actions...)
+			if frozen {
+				pipeline.Freeze()
+			}
+
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				_, _ = pipeline.Run(ctx, i)
+			}
+		})
+	}
+}
+
+func BenchmarkRunWithSampling(b *testing.B) {
+	logrus.SetLevel(logrus.InfoLevel)
+	ctx := context.Background()
+
+	for _, sampleRate := range []float64{0, 1} {
+		b.Run(fmt.Sprintf("rate=%g", sampleRate), func(b *testing.B) {
+			pipeline := NewPipeline[int]("Linear", &DirectingAction{name: "action1"}, &DirectingAction{name: "action2"}, &SetTen{})
+
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				_ = pipeline.RunWithSampling(ctx, i, sampleRate)
+			}
+		})
+	}
+}
+
+// Pipelines generated from workflow definitions may have thousands of members.
+// Constructing 5000 linear members takes about 2ms, and validating them about 3.5ms.
+func BenchmarkNewPipeline_5000(b *testing.B) {
+	actions := make([]Action[int], 5000)
+	for i := range actions {
+		actions[i] = &DirectingAction{name: fmt.Sprintf("action%d", i)}
+	}
+
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		_ = NewPipeline("Large", actions...)
+	}
+}
+
+func BenchmarkValidateGraph_5000(b *testing.B) {
+	actions := make([]Action[int], 5000)
+	for i := range actions {
+		actions[i] = &DirectingAction{name: fmt.Sprintf("action%d", i)}
+	}
+	pipeline := NewPipeline("Large", actions...)
+	// Starting from the last member leaves all the others to the walks after the first one
+	if err := pipeline.SetInitAction(actions[len(actions)-1]); err != nil {
+		b.Fatal(err)
+	}
+
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		if err := pipeline.ValidateGraph(); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+// The memory held by the plans of a pipeline with 5000 members, as constructed and as frozen,
+// reported as the heap retained by each pipeline in retained-B/pipeline.
+func BenchmarkPlans_5000(b *testing.B) {
+	actions := make([]Action[int], 5000)
+	for i := range actions {
+		actions[i] = &DirectingAction{name: fmt.Sprintf("action%d", i)}
+	}
+	build := map[string]func() *Pipeline[int]{
+		"constructed": func() *Pipeline[int] { return NewPipeline("Large", actions...) },
+		"frozen":      func() *Pipeline[int] { return NewPipeline("Large", actions...).Freeze() },
+	}
+
+	for _, name := range []string{"constructed", "frozen"} {
+		b.Run(name, func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				_ = build[name]()
+			}
+			b.StopTimer()
+
+			var before, after runtime.MemStats
+			runtime.GC()
+			runtime.ReadMemStats(&before)
+			pipeline := build[name]()
+			runtime.GC()
+			runtime.ReadMemStats(&after)
+			b.ReportMetric(float64(int64(after.HeapAlloc)-int64(before.HeapAlloc)), "retained-B/pipeline")
+			runtime.KeepAlive(pipeline)
+		})
+	}
+}
+
+// ValidateGraph over a binary tree of 5000 branching actions, each directing `left` or `right`.
+func BenchmarkValidateGraph_Branching5000(b *testing.B) {
+	actions := make([]Action[int], 5000)
+	for i := range actions {
+		actions[i] = &RoutingAction{name: fmt.Sprintf("action%d", i), directions: []string{"left", "right"}}
+	}
+	pipeline := NewPipeline("Branching", actions...)
+	for i, action := range actions {
+		plan := ActionPlan[int]{}
+		if left := 2*i + 1; left < len(actions) {
+			plan["left"] = actions[left]
+		}
+		if right := 2*i + 2; right < len(actions) {
+			plan["right"] = actions[right]
+		}
+		pipeline.SetRunPlan(action, plan)
+	}
+
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		if err := pipeline.ValidateGraph(); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
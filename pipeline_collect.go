@@ -0,0 +1,57 @@
+package chain
+
+import (
+	"context"
+	"encoding/json"
+)
+
+// CollectedRun holds the result of a run of RunCollect with the input and the result of every step,
+// in the order they were run. It can be marshaled to JSON as it is, where the errors are
+// marshaled as their messages, for dumping runs while debugging.
+type CollectedRun[T any] struct {
+	Output    T
+	Direction string
+	Err       error
+	Steps     []StepResult[T]
+}
+
+// StepResult records a step of a run collected by RunCollect: the name of the action, the input
+// given to it, the output it returned, the direction it selected and the error it returned.
+// Member pipelines are recorded as a single step, as they are a single Action for their parent.
+type StepResult[T any] struct {
+	Action    string
+	Input     T
+	Output    T
+	Direction string
+	Err       error
+}
+
+// RunCollect runs the pipeline the same way as Run, collecting the input and the result of each step
+// on the returned CollectedRun, which is the entry point for debugging runs step by step.
+// The error of the run is returned as well as held by the CollectedRun.
+func (p *Pipeline[T]) RunCollect(ctx context.Context, input T) (CollectedRun[T], error) {
+	var collected CollectedRun[T]
+	collected.Output, collected.Direction, collected.Err = p.runAt(p.entryAction(), ctx, input, &runHooks[T]{collected: &collected.Steps})
+	return collected, collected.Err
+}
+
+// MarshalJSON marshals the run with the error as its message.
+func (c CollectedRun[T]) MarshalJSON() ([]byte, error) {
+	return json.Marshal(struct {
+		Output    T               `json:"output"`
+		Direction string          `json:"direction"`
+		Err       string          `json:"error,omitempty"`
+		Steps     []StepResult[T] `json:"steps"`
+	}{c.Output, c.Direction, errorMessage(c.Err), c.Steps})
+}
+
+// MarshalJSON marshals the step with the error as its message.
+func (s StepResult[T]) MarshalJSON() ([]byte, error) {
+	return json.Marshal(struct {
+		Action    string `json:"action"`
+		Input     T      `json:"input"`
+		Output    T      `json:"output"`
+		Direction string `json:"direction"`
+		Err       string `json:"error,omitempty"`
+	}{s.Action, s.Input, s.Output, s.Direction, errorMessage(s.Err)})
+}
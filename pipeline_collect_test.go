@@ -0,0 +1,60 @@
+package chain
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestPipeline_RunCollect(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("collects steps of run", func(t *testing.T) {
+		collatz := NewCollatz("Collatz")
+
+		collected, err := collatz.RunCollect(ctx, 5)
+
+		assert.NoError(t, err)
+		assert.Equal(t, CollectedRun[int]{
+			Output:    16,
+			Direction: Success,
+			Steps: []StepResult[int]{
+				{Action: "CheckNext", Input: 5, Output: 5, Direction: "odd"},
+				{Action: "OnOdd", Input: 5, Output: 16, Direction: Success},
+			},
+		}, collected)
+	})
+
+	t.Run("collects errors of steps", func(t *testing.T) {
+		setTen := &SetTen{}
+		pipeline := NewPipeline("Pipeline", &ErrorMaker{message: "error1"}, setTen)
+		pipeline.SetRunPlan(pipeline.members[0], ActionPlan[int]{Error: setTen})
+
+		collected, err := pipeline.RunCollect(ctx, 1)
+
+		assert.EqualError(t, err, "error1")
+		assert.Equal(t, err, collected.Err)
+		assert.Equal(t, Error, collected.Direction)
+		assert.Equal(t, []StepResult[int]{
+			{Action: "error1", Input: 1, Output: 1, Direction: Error, Err: errors.New("error1")},
+			{Action: "SetTen", Input: 1, Output: 10, Direction: Success},
+		}, collected.Steps)
+	})
+
+	t.Run("marshals to JSON", func(t *testing.T) {
+		pipeline := NewPipeline("Pipeline", &ErrorMaker{message: "error1"})
+
+		collected, _ := pipeline.RunCollect(ctx, 1)
+		encoded, err := json.Marshal(collected)
+
+		assert.NoError(t, err)
+		assert.JSONEq(t, `{
+			"output": 1,
+			"direction": "error",
+			"error": "error1",
+			"steps": [{"action": "error1", "input": 1, "output": 1, "direction": "error", "error": "error1"}]
+		}`, string(encoded))
+	})
+}
@@ -0,0 +1,113 @@
+package chain
+
+// Frozen pipelines keep only the plans of the members overridden from their default plans, such as by
+// SetRunPlan or Divert, as pipelines with thousands of members mostly leave their members on the
+// default plans, each costing a map of their own. The default plans are built on demand instead,
+// so the plans read by accessors such as planOf, Snapshot or PlanEntries stay the same.
+
+// planOfLocked returns the plan of the action as planOf does, where p.mu should be held.
+// For frozen pipelines, the default plan of a member without an overridden plan is built on demand.
+func (p *Pipeline[T]) planOfLocked(action Action[T]) ActionPlan[T] {
+	if plan, exists := p.runPlans[action]; exists || !p.frozen {
+		return plan
+	}
+	index, isMember := p.memberIndex[action]
+	if !isMember {
+		return nil
+	}
+	return p.defaultPlan(index)
+}
+
+// defaultPlan builds the plan the member at index is given on construction, continuing to the next
+// member on Success, and terminating on Error, Abort and the directions of a BranchAction.
+func (p *Pipeline[T]) defaultPlan(index int) ActionPlan[T] {
+	terminate := Terminate[T]()
+	nextAction := terminate
+	if index+1 < len(p.members) {
+		nextAction = p.members[index+1]
+	}
+
+	// Built without listing the directions, as pipelines generated from definitions
+	// may have thousands of members
+	plan := make(ActionPlan[T], 3)
+	plan[Error], plan[Abort] = terminate, terminate
+	if branchAction, isBranchAction := p.members[index].(BranchAction[T]); isBranchAction {
+		for _, direction := range branchAction.Directions() {
+			plan[direction] = terminate
+		}
+	}
+	plan[Success] = nextAction
+	return plan
+}
+
+// isDefaultPlan reports whether the plan is the same as the default plan of the member at index,
+// without building the default plan.
+func (p *Pipeline[T]) isDefaultPlan(index int, plan ActionPlan[T]) bool {
+	terminate := Terminate[T]()
+	nextAction := terminate
+	if index+1 < len(p.members) {
+		nextAction = p.members[index+1]
+	}
+	if successAction, exists := plan[Success]; !exists || successAction != nextAction {
+		return false
+	}
+
+	defaultDirections := 1
+	for _, direction := range []string{Error, Abort} {
+		if next, exists := plan[direction]; !exists || next != terminate {
+			return false
+		}
+		defaultDirections++
+	}
+	if branchAction, isBranchAction := p.members[index].(BranchAction[T]); isBranchAction {
+		for _, direction := range branchAction.Directions() {
+			if direction == Success || direction == Error || direction == Abort {
+				continue
+			}
+			if next, exists := plan[direction]; !exists || next != terminate {
+				return false
+			}
+			defaultDirections++
+		}
+	}
+	// Directions declared multiple times are counted multiple times, failing to compact them,
+	// which costs only memory
+	return len(plan) == defaultDirections
+}
+
+// compactPlans drops the plans of the members left on their default plans,
+// rebuilding runPlans as maps never shrink on deletion. p.mu should be held.
+func (p *Pipeline[T]) compactPlans() {
+	overridden := 0
+	for i, action := range p.members {
+		if plan, exists := p.runPlans[action]; exists && !p.isDefaultPlan(i, plan) {
+			overridden++
+		}
+	}
+
+	runPlans := make(map[Action[T]]ActionPlan[T], overridden)
+	for i, action := range p.members {
+		if plan, exists := p.runPlans[action]; exists && !p.isDefaultPlan(i, plan) {
+			runPlans[action] = plan
+		}
+	}
+	p.runPlans = runPlans
+}
+
+// selectDefault selects the next action of the member at index by the direction, following its default plan.
+func (p *Pipeline[T]) selectDefault(index int, direction string) (Action[T], int, *ErrUnknownDirection) {
+	action := p.members[index]
+	switch direction {
+	case Success:
+		if index+1 < len(p.members) {
+			return p.members[index+1], index + 1, nil
+		}
+		return Terminate[T](), terminated, nil
+	case Error, Abort:
+		return Terminate[T](), terminated, nil
+	}
+	if branchAction, isBranchAction := action.(BranchAction[T]); isBranchAction && contains(branchAction.Directions(), direction) {
+		return Terminate[T](), terminated, nil
+	}
+	return Terminate[T](), terminated, &ErrUnknownDirection{Action: action.Name(), Direction: direction}
+}
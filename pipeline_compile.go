@@ -0,0 +1,79 @@
+package chain
+
+import "context"
+
+// CompileToFunction returns a function running the pipeline, for APIs expecting a plain function
+// rather than an Action. The function reports the output, the direction the run ended with,
+// and the error, as RunIf does, falling back as the pipeline does.
+//
+// The function captures the plans of the pipeline at the time of compilation, so later changes
+// such as SetRunPlan or Divert do not affect it. Member pipelines are run as they are,
+// so changes on their plans still take effect.
+func (p *Pipeline[T]) CompileToFunction() func(context.Context, T) (T, string, error) {
+	compiled := p.clone()
+	return compiled.runFallingBack
+}
+
+// clone copies the pipeline with its configuration as of now, sharing the member actions.
+func (p *Pipeline[T]) clone() *Pipeline[T] {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	// Plans are replaced rather than modified on changes, so they can be shared
+	cloned := &Pipeline[T]{
+		name:        p.name,
+		runPlans:    make(map[Action[T]]ActionPlan[T], len(p.runPlans)),
+		members:     p.members,
+		memberIndex: p.memberIndex,
+		initAction:  p.initAction,
+		planSources: make(map[Action[T]]map[string]PlanSource, len(p.planSources)),
+		retries:     make(map[Action[T]]int, len(p.retries)),
+		notifiers:   p.notifiers,
+
+		terminateHooks: p.terminateHooks,
+		observers:      p.observers,
+		taps:           p.taps,
+
+		directionHooks:   p.directionHooks,
+		directionAliases: p.directionAliases,
+
+		circularGuard: p.circularGuard,
+		stats:         p.stats,
+		metrics:       p.metrics,
+
+		fallback:        p.fallback,
+		terminateAction: p.terminateAction,
+		errorClassifier: p.errorClassifier,
+		stepInterval:    p.stepInterval,
+		traceCapacity:   p.traceCapacity,
+		postCondition:   p.postCondition,
+
+		outcomeClassifier: p.outcomeClassifier,
+
+		finalDirectionPolicy:   p.finalDirectionPolicy,
+		unknownDirectionPolicy: p.unknownDirectionPolicy,
+		directionCheck:         p.directionCheck,
+		tracing:                p.tracing,
+		frozen:                 p.frozen,
+	}
+	for action, plan := range p.runPlans {
+		cloned.runPlans[action] = plan
+	}
+	for action, sources := range p.planSources {
+		cloned.planSources[action] = sources
+	}
+	for action, retries := range p.retries {
+		cloned.retries[action] = retries
+	}
+	if p.annotations != nil {
+		cloned.annotations = make(map[Action[T]]map[string]string, len(p.annotations))
+		for action, annotations := range p.annotations {
+			cloned.annotations[action] = make(map[string]string, len(annotations))
+			for key, value := range annotations {
+				cloned.annotations[action][key] = value
+			}
+		}
+	}
+
+	return cloned
+}
@@ -0,0 +1,25 @@
+package chain
+
+import (
+	"context"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestPipeline_CompileToFunction(t *testing.T) {
+	ctx := context.Background()
+	collatz := NewCollatz("Collatz")
+
+	run := collatz.CompileToFunction()
+	collatz.SetRunPlan(collatz.CheckNext, TerminationPlan[int]())
+	assert.NoError(t, collatz.Divert(collatz.OnOdd, Success, collatz.OnEven))
+
+	output, direction, err := run(ctx, 5)
+	assert.NoError(t, err)
+	assert.Equal(t, Success, direction)
+	assert.Equal(t, 16, output)
+
+	output, err = collatz.Run(ctx, 5)
+	assert.NoError(t, err)
+	assert.Equal(t, 5, output)
+}
@@ -0,0 +1,93 @@
+package chain
+
+import (
+	"context"
+	"github.com/sirupsen/logrus"
+)
+
+// AnyCustomDirection registers a hook with OnDirection for every custom direction,
+// which is any direction except Success, Error and Abort.
+const AnyCustomDirection = "*"
+
+// DirectionHookOption customizes a hook registered with OnDirection.
+type DirectionHookOption func(*directionHookConfig)
+
+type directionHookConfig struct {
+	propagate bool
+}
+
+// PropagateToNested makes a hook registered with OnDirection fire for the steps of
+// nested member pipelines as well, at any depth.
+func PropagateToNested() DirectionHookOption {
+	return func(c *directionHookConfig) { c.propagate = true }
+}
+
+type registeredDirectionHook[T any] struct {
+	direction string
+	fn        func(ctx context.Context, actionName string, payload T)
+	propagate bool
+}
+
+// OnDirection registers fn to be called whenever a step of a run directs the direction,
+// right before the next action is selected, with the name of the action and its output.
+// Registering AnyCustomDirection calls fn for every custom direction, such as paging
+// the on-call whenever any action routes to a quarantine.
+// Multiple hooks are called in the order they were registered.
+// A panic on a hook is recovered and logged, without affecting the run or the other hooks.
+func (p *Pipeline[T]) OnDirection(direction string, fn func(ctx context.Context, actionName string, payload T), opts ...DirectionHookOption) {
+	if fn == nil {
+		return
+	}
+	config := directionHookConfig{}
+	for _, opt := range opts {
+		opt(&config)
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	// Copy on write, so running does not need to hold the lock
+	hooks := make([]registeredDirectionHook[T], len(p.directionHooks), len(p.directionHooks)+1)
+	copy(hooks, p.directionHooks)
+	p.directionHooks = append(hooks, registeredDirectionHook[T]{direction: direction, fn: fn, propagate: config.propagate})
+}
+
+const propagatedDirectionHooks = "PipelineDirectionHooks"
+
+// directionHooksFor lists the hooks to fire for a run on ctx, which are the hooks of the pipeline
+// and the hooks propagated by its parents. The returned ctx propagates the hooks to nested pipelines.
+func directionHooksFor[T any](ctx context.Context, own []registeredDirectionHook[T]) (context.Context, []registeredDirectionHook[T]) {
+	inherited, _ := ctx.Value(propagatedDirectionHooks).([]registeredDirectionHook[T])
+	hooks := append(own[:len(own):len(own)], inherited...)
+
+	propagated := inherited
+	for _, hook := range own {
+		if hook.propagate {
+			propagated = append(propagated[:len(propagated):len(propagated)], hook)
+		}
+	}
+	if len(propagated) != len(inherited) {
+		ctx = context.WithValue(ctx, propagatedDirectionHooks, propagated)
+	}
+
+	return ctx, hooks
+}
+
+// fireDirectionHooks calls the hooks registered for the direction.
+func fireDirectionHooks[T any](ctx context.Context, hooks []registeredDirectionHook[T], actionName, direction string, payload T) {
+	isCustom := direction != Success && direction != Error && direction != Abort
+	for _, hook := range hooks {
+		if hook.direction == direction || (hook.direction == AnyCustomDirection && isCustom) {
+			callDirectionHook(ctx, hook, actionName, direction, payload)
+		}
+	}
+}
+
+func callDirectionHook[T any](ctx context.Context, hook registeredDirectionHook[T], actionName, direction string, payload T) {
+	defer func() {
+		if panicErr := recover(); panicErr != nil {
+			logrus.Errorf("%s: panic occurred on hook of `%s`, caused by %s", actionName, direction, panicErr)
+		}
+	}()
+
+	hook.fn(ctx, actionName, payload)
+}
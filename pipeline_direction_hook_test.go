@@ -0,0 +1,79 @@
+package chain
+
+import (
+	"context"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestPipeline_OnDirection(t *testing.T) {
+	ctx := context.Background()
+	type call struct {
+		tag     string
+		action  string
+		payload int
+	}
+	record := func(calls *[]call, tag string) func(context.Context, string, int) {
+		return func(_ context.Context, actionName string, payload int) {
+			*calls = append(*calls, call{tag: tag, action: actionName, payload: payload})
+		}
+	}
+
+	t.Run("fires on matching directions", func(t *testing.T) {
+		collatz := NewCollatz("Collatz")
+		var calls []call
+		collatz.OnDirection("even", record(&calls, "even"))
+		collatz.OnDirection("even", record(&calls, "even again"))
+		collatz.OnDirection(Success, record(&calls, "success"))
+
+		_, err := collatz.Run(ctx, 8)
+
+		assert.NoError(t, err)
+		assert.Equal(t, []call{
+			{tag: "even", action: "CheckNext", payload: 8},
+			{tag: "even again", action: "CheckNext", payload: 8},
+			{tag: "success", action: "OnEven", payload: 4},
+		}, calls)
+	})
+
+	t.Run("fires on any custom direction", func(t *testing.T) {
+		collatz := NewCollatz("Collatz")
+		var calls []call
+		collatz.OnDirection(AnyCustomDirection, record(&calls, "custom"))
+
+		_, _ = collatz.Run(ctx, 5)
+
+		assert.Equal(t, []call{{tag: "custom", action: "CheckNext", payload: 5}}, calls)
+	})
+
+	t.Run("contains panics", func(t *testing.T) {
+		collatz := NewCollatz("Collatz")
+		var calls []call
+		collatz.OnDirection("odd", func(context.Context, string, int) { panic("broken hook") })
+		collatz.OnDirection("odd", record(&calls, "odd"))
+
+		output, err := collatz.Run(ctx, 5)
+
+		assert.NoError(t, err)
+		assert.Equal(t, 16, output)
+		assert.Len(t, calls, 1)
+	})
+
+	t.Run("propagates to nested pipelines on request", func(t *testing.T) {
+		collatz := NewCollatz("Collatz")
+		outer := NewPipeline("Outer", collatz.Pipeline, &SetTen{})
+		var calls []call
+		outer.OnDirection("odd", record(&calls, "local"))
+		outer.OnDirection("odd", record(&calls, "propagated"), PropagateToNested())
+		outer.OnDirection(Success, record(&calls, "success"))
+
+		_, err := outer.Run(ctx, 5)
+
+		assert.NoError(t, err)
+		assert.Equal(t, []call{
+			{tag: "propagated", action: "CheckNext", payload: 5},
+			{tag: "success", action: "Collatz", payload: 16},
+			{tag: "success", action: "SetTen", payload: 10},
+		}, calls)
+	})
+}
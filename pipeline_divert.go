@@ -0,0 +1,45 @@
+package chain
+
+import (
+	"errors"
+	"fmt"
+)
+
+// Divert redirects a single direction of the action to newTarget, keeping the rest of its plan.
+// It is meant for shaping the traffic of a running pipeline, such as redirecting the Success of
+// an action to a canary action. Runs started after Divert returns follow the new target.
+//
+// Unlike SetRunPlan, invalid diversions are reported as an error instead of a panic:
+// the action and newTarget must be members (or newTarget may be Terminate),
+// the direction must be supported by the action, and the action must not direct to itself.
+func (p *Pipeline[T]) Divert(action Action[T], direction string, newTarget Action[T]) error {
+	if action == nil {
+		return errors.New("cannot divert plan of terminate")
+	} else if !isMemberActionInPipeline(action, p) {
+		return fmt.Errorf("`%s` is not a member of this pipeline", action.Name())
+	}
+
+	availableDirections := p.plannedDirectionsOf(action)
+	if newTarget == Terminate[T]() {
+		if !contains(availableDirections, direction) {
+			return fmt.Errorf("`%s` does not support direction `%s`", action.Name(), direction)
+		}
+	} else if err := p.validateEdge(action, availableDirections, direction, newTarget); err != nil {
+		return err
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	plan := clonePlan(p.planOfLocked(action))
+	plan[direction] = newTarget
+	p.runPlans[action] = plan
+	p.compiled.Store(nil)
+	sources := make(map[string]PlanSource, len(p.planSources[action])+1)
+	for plannedDirection, source := range p.planSources[action] {
+		sources[plannedDirection] = source
+	}
+	sources[direction] = PlanDivert
+	p.planSources[action] = sources
+
+	return nil
+}
@@ -0,0 +1,77 @@
+package chain
+
+import (
+	"context"
+	"github.com/stretchr/testify/assert"
+	"sync"
+	"testing"
+)
+
+func TestPipeline_Divert(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("divert success to canary", func(t *testing.T) {
+		first := NewSimpleAction("First", func(_ context.Context, input int) (int, error) { return input + 1, nil })
+		stable := NewSimpleAction("Stable", func(_ context.Context, input int) (int, error) { return input * 10, nil })
+		canary := NewSimpleAction("Canary", func(_ context.Context, input int) (int, error) { return input * 100, nil })
+		pipeline := NewPipeline("Pipeline", first, stable, canary)
+		pipeline.SetRunPlan(stable, TerminationPlan[int]())
+
+		output, _ := pipeline.Run(ctx, 1)
+		assert.Equal(t, 20, output)
+
+		assert.NoError(t, pipeline.Divert(first, Success, canary))
+		output, _ = pipeline.Run(ctx, 1)
+		assert.Equal(t, 200, output)
+
+		assert.NoError(t, pipeline.Divert(first, Success, Terminate[int]()))
+		output, _ = pipeline.Run(ctx, 1)
+		assert.Equal(t, 2, output)
+	})
+
+	t.Run("keeps other directions", func(t *testing.T) {
+		action1, action2, action3 := &DirectingAction{name: "action1"}, &DirectingAction{name: "action2"}, &DirectingAction{name: "action3"}
+		pipeline := NewPipeline("Pipeline", action1, action2, action3)
+		pipeline.SetRunPlan(action1, DefaultPlan(action2, action3))
+
+		assert.NoError(t, pipeline.Divert(action1, Success, action3))
+
+		assert.Equal(t, ActionPlan[int]{Success: action3, Error: action3, Abort: nil}, pipeline.planOf(action1))
+	})
+
+	t.Run("invalid diversions", func(t *testing.T) {
+		member1, member2, nonMember := &DirectingAction{name: "member1"}, &DirectingAction{name: "member2"}, &DirectingAction{name: "non-member"}
+		pipeline := NewPipeline("Pipeline", member1, member2)
+
+		assert.EqualError(t, pipeline.Divert(Terminate[int](), Success, member1), "cannot divert plan of terminate")
+		assert.EqualError(t, pipeline.Divert(nonMember, Success, member1), "`non-member` is not a member of this pipeline")
+		assert.EqualError(t, pipeline.Divert(member1, "unsupported", member2), "`member1` does not support direction `unsupported`")
+		assert.EqualError(t, pipeline.Divert(member1, "unsupported", Terminate[int]()), "`member1` does not support direction `unsupported`")
+		assert.EqualError(t, pipeline.Divert(member1, Success, nonMember), "setting plan from `member1` directing `success` to non-member `non-member`")
+		assert.EqualError(t, pipeline.Divert(member1, Success, member1), "setting self loop plan with `member1` directing `success`")
+	})
+
+	t.Run("divert while running", func(t *testing.T) {
+		collatz1, collatz2 := NewCollatz("collatz1"), NewCollatz("collatz2")
+		pipeline := NewPipeline("Pipeline", collatz1, collatz2)
+
+		var wg sync.WaitGroup
+		for i := 0; i < 4; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				for j := 0; j < 100; j++ {
+					_, _ = pipeline.Run(ctx, 5)
+				}
+			}()
+		}
+		for j := 0; j < 100; j++ {
+			target := Action[int](collatz2)
+			if j%2 == 0 {
+				target = Terminate[int]()
+			}
+			assert.NoError(t, pipeline.Divert(collatz1, Success, target))
+		}
+		wg.Wait()
+	})
+}
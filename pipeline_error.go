@@ -0,0 +1,43 @@
+package chain
+
+import "errors"
+
+// PipelineError is the error of a failed run, telling which action failed:
+//
+//	var pipelineErr *PipelineError
+//	if errors.As(err, &pipelineErr) {
+//		log.Printf("%s failed at %s", pipelineErr.Path, pipelineErr.Action)
+//	}
+//
+// Every error a run returns is a *PipelineError wrapping the errors of the run,
+// which keeps the message of the wrapped error as it is.
+// Action is the name of the action whose error ended the run, and Path is the name of
+// the pipeline running it, prefixed by the names of its parents when nested, such as `Outer/Inner`.
+// When the failed action is a nested pipeline, both are taken from the error of the nested pipeline,
+// so they always point to the innermost failed action.
+//
+// For the errors of the pipeline itself, such as cancellation of ctx or an *ErrUnknownDirection,
+// Action is the action the pipeline was about to run, or could not continue from.
+type PipelineError struct {
+	Path   string
+	Action string
+	Err    error
+}
+
+func (e *PipelineError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *PipelineError) Unwrap() error {
+	return e.Err
+}
+
+// newPipelineError wraps err of the run, which ended with failedErr from the action.
+func newPipelineError(path, action string, failedErr, err error) *PipelineError {
+	pipelineErr := &PipelineError{Path: path, Action: action, Err: err}
+	var nestedErr *PipelineError
+	if errors.As(failedErr, &nestedErr) {
+		pipelineErr.Path, pipelineErr.Action = nestedErr.Path, nestedErr.Action
+	}
+	return pipelineErr
+}
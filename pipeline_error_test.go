@@ -0,0 +1,131 @@
+package chain
+
+import (
+	"context"
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestPipelineError(t *testing.T) {
+	ctx := context.Background()
+	failedAt := func(t *testing.T, err error) *PipelineError {
+		var pipelineErr *PipelineError
+		assert.True(t, errors.As(err, &pipelineErr))
+		return pipelineErr
+	}
+
+	t.Run("action error", func(t *testing.T) {
+		pipeline := NewPipeline("Pipeline", &SetTen{}, &ErrorMaker{message: "failing"})
+
+		_, err := pipeline.Run(ctx, 1)
+
+		assert.EqualError(t, err, "failing")
+		assert.Equal(t, &PipelineError{Path: "Pipeline", Action: "failing", Err: errors.Unwrap(err)}, failedAt(t, err))
+	})
+
+	t.Run("nested three levels deep", func(t *testing.T) {
+		inner := NewPipeline("Inner", &SetTen{}, &ErrorMaker{message: "failing"})
+		middle := NewPipeline("Middle", &DirectingAction{name: "action1"}, inner)
+		outer := NewPipeline("Outer", middle, &SetTen{})
+
+		_, err := outer.Run(ctx, 1)
+
+		assert.EqualError(t, err, "failing")
+		pipelineErr := failedAt(t, err)
+		assert.Equal(t, "Outer/Middle/Inner", pipelineErr.Path)
+		assert.Equal(t, "failing", pipelineErr.Action)
+	})
+
+	t.Run("latest failure of nested pipelines", func(t *testing.T) {
+		inner := NewPipeline("Inner", &ErrorMaker{message: "inner failing"})
+		outerFailing := &ErrorMaker{message: "outer failing"}
+		outer := NewPipeline("Outer", inner, outerFailing)
+		outer.SetRunPlan(inner, ActionPlan[int]{Error: outerFailing})
+
+		_, err := outer.Run(ctx, 1)
+
+		pipelineErr := failedAt(t, err)
+		assert.Equal(t, "Outer", pipelineErr.Path)
+		assert.Equal(t, "outer failing", pipelineErr.Action)
+	})
+
+	t.Run("unknown direction", func(t *testing.T) {
+		inner := NewPipeline("Inner", &StrayAction{name: "Stray", direction: "sideways"})
+		outer := NewPipeline("Outer", inner)
+
+		_, err := outer.Run(ctx, 1)
+
+		var unknownErr *ErrUnknownDirection
+		assert.True(t, errors.As(err, &unknownErr))
+		pipelineErr := failedAt(t, err)
+		assert.Equal(t, "Outer/Inner", pipelineErr.Path)
+		assert.Equal(t, "Stray", pipelineErr.Action)
+	})
+
+	t.Run("circular guard", func(t *testing.T) {
+		action1, action2 := &DirectingAction{name: "action1"}, &DirectingAction{name: "action2"}
+		inner := NewPipeline("Inner", action1, action2)
+		inner.SetRunPlan(action2, SuccessOnlyPlan(action1))
+		assert.NoError(t, inner.AddCircularGuard(1))
+		outer := NewPipeline("Outer", inner)
+
+		_, err := outer.Run(ctx, 1)
+
+		assert.ErrorIs(t, err, ErrCircularExecution)
+		pipelineErr := failedAt(t, err)
+		assert.Equal(t, "Outer/Inner", pipelineErr.Path)
+		assert.Equal(t, "action1", pipelineErr.Action)
+	})
+
+	t.Run("cancelled context", func(t *testing.T) {
+		cancelCtx, cancel := context.WithCancel(ctx)
+		cancelling := NewSimpleAction("Cancelling", func(_ context.Context, input int) (int, error) {
+			cancel()
+			return input, nil
+		})
+		inner := NewPipeline("Inner", cancelling, &SetTen{})
+		outer := NewPipeline("Outer", &DirectingAction{name: "action1"}, inner)
+
+		_, err := outer.Run(cancelCtx, 1)
+
+		assert.ErrorIs(t, err, context.Canceled)
+		pipelineErr := failedAt(t, err)
+		assert.Equal(t, "Outer/Inner", pipelineErr.Path)
+		assert.Equal(t, "SetTen", pipelineErr.Action)
+	})
+
+	t.Run("post condition violation", func(t *testing.T) {
+		errViolated := errors.New("violated")
+		pipeline := NewPipeline("Pipeline", &SetTen{})
+		pipeline.SetPostCondition(func(int, string) error { return errViolated })
+
+		_, err := pipeline.Run(ctx, 1)
+
+		assert.ErrorIs(t, err, errViolated)
+		var violation *PostConditionError
+		assert.True(t, errors.As(err, &violation))
+		assert.Equal(t, &PipelineError{Path: "Pipeline", Action: "SetTen", Err: violation}, failedAt(t, err))
+	})
+
+	t.Run("non-member initAction", func(t *testing.T) {
+		pipeline := NewPipeline("Pipeline", &SetTen{})
+
+		_, err := pipeline.RunAt(&StrayAction{name: "Stray"}, ctx, 1)
+
+		assert.EqualError(t, err, "given initAction is not registered on constructor")
+		pipelineErr := failedAt(t, err)
+		assert.Equal(t, "Pipeline", pipelineErr.Path)
+		assert.Equal(t, "Stray", pipelineErr.Action)
+	})
+
+	t.Run("draining", func(t *testing.T) {
+		pipeline := NewPipeline("Pipeline", &SetTen{})
+		assert.NoError(t, pipeline.Drain(ctx))
+
+		_, err := pipeline.Run(ctx, 1)
+
+		assert.ErrorIs(t, err, ErrDraining)
+		assert.Equal(t, &PipelineError{Path: "Pipeline", Action: "SetTen", Err: ErrDraining}, failedAt(t, err))
+	})
+}
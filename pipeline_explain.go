@@ -0,0 +1,189 @@
+package chain
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"text/tabwriter"
+)
+
+// PlanSource tells how a direction of an action was planned.
+type PlanSource int
+
+const (
+	// PlanDefault is a direction left on the defaults, either by the constructor
+	// or by SetRunPlan for the directions not given in the plan.
+	PlanDefault PlanSource = iota
+	// PlanSetRunPlan is a direction given in the plan of SetRunPlan.
+	PlanSetRunPlan
+	// PlanDivert is a direction redirected by Divert.
+	PlanDivert
+	// PlanMerge is a direction taken from another pipeline by MergeRunPlans.
+	PlanMerge
+)
+
+func (s PlanSource) String() string {
+	switch s {
+	case PlanDefault:
+		return "default"
+	case PlanSetRunPlan:
+		return "SetRunPlan"
+	case PlanDivert:
+		return "Divert"
+	case PlanMerge:
+		return "MergeRunPlans"
+	default:
+		return fmt.Sprintf("PlanSource(%d)", int(s))
+	}
+}
+
+// PlanEntry describes where a direction of an action routes, and how it was planned.
+// Entries leading to termination have Terminates set, with an empty Target.
+type PlanEntry struct {
+	Action     string
+	Direction  string
+	Target     string
+	Terminates bool
+	Source     PlanSource
+}
+
+// PlanEntries lists every direction of every member action with its target and source,
+// ordered by the members, then by their directions with Success, Error and Abort first.
+func (p *Pipeline[T]) PlanEntries() []PlanEntry {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	var entries []PlanEntry
+	for _, action := range p.members {
+		entries = append(entries, p.planEntriesOf(action)...)
+	}
+
+	return entries
+}
+
+// planEntriesOf lists the directions of the action as PlanEntries does, where p.mu should be held.
+func (p *Pipeline[T]) planEntriesOf(action Action[T]) []PlanEntry {
+	terminate := Terminate[T]()
+	plan := p.planOfLocked(action)
+	var entries []PlanEntry
+	for _, direction := range aliasedDirectionsOf(action, p.directionAliases) {
+		nextAction, exists := plan[direction]
+		if !exists {
+			continue
+		}
+		entry := PlanEntry{
+			Action:     action.Name(),
+			Direction:  direction,
+			Terminates: true,
+			Source:     p.planSources[action][direction],
+		}
+		if nextAction != terminate {
+			entry.Target, entry.Terminates = nextAction.Name(), false
+		}
+		entries = append(entries, entry)
+	}
+
+	return entries
+}
+
+// ExplainPlans describes the plans of the pipeline for reviewing, telling which directions
+// were consciously planned and which are left on defaults, where surprises usually hide.
+// Each member is followed by its directions, their targets and how they were planned:
+//
+//	`CheckNext`
+//	  success  -> terminate  (default)
+//	  even     -> `OnEven`   (SetRunPlan)
+func (p *Pipeline[T]) ExplainPlans() string {
+	var sb strings.Builder
+	w := tabwriter.NewWriter(&sb, 0, 0, 2, ' ', 0)
+
+	previousAction := ""
+	for i, entry := range p.PlanEntries() {
+		if i == 0 || entry.Action != previousAction {
+			fmt.Fprintf(w, "`%s`\n", entry.Action)
+			previousAction = entry.Action
+		}
+		target := "terminate"
+		if !entry.Terminates {
+			target = "`" + entry.Target + "`"
+		}
+		fmt.Fprintf(w, "  %s\t-> %s\t(%s)\n", entry.Direction, target, entry.Source)
+	}
+	w.Flush()
+
+	return sb.String()
+}
+
+// InspectRunPlan describes the plan of the action as a table of its directions and the names of
+// the actions they route to, for printing in a REPL or logs while debugging.
+// Directions left to terminate by default are marked as `[default: terminate]`:
+//
+//	direction  next action
+//	success    [default: terminate]
+//	even       OnEven
+//
+// It panics when the action is not a member of the pipeline.
+func (p *Pipeline[T]) InspectRunPlan(action Action[T]) string {
+	if !isMemberActionInPipeline(action, p) {
+		panic(fmt.Errorf("`%s` is not a member of this pipeline", action.Name()))
+	}
+
+	p.mu.RLock()
+	entries := p.planEntriesOf(action)
+	p.mu.RUnlock()
+
+	var sb strings.Builder
+	w := tabwriter.NewWriter(&sb, 0, 0, 2, ' ', 0)
+	fmt.Fprint(w, "direction\tnext action\n")
+	for _, entry := range entries {
+		fmt.Fprintf(w, "%s\t%s\n", entry.Direction, inspectTarget(entry))
+	}
+	w.Flush()
+
+	return sb.String()
+}
+
+// InspectAllPlans describes the plans of every member as InspectRunPlan does, in a single table
+// naming each action on the first row of its directions. The actions are in topological order,
+// falling back to the order given to the constructor when the plans have cycles.
+func (p *Pipeline[T]) InspectAllPlans() string {
+	members := append([]Action[T]{}, p.members...)
+	if sorted, err := p.Graph().TopoSort(); err == nil {
+		rank := make(map[string]int, len(sorted))
+		for i, name := range sorted {
+			rank[name] = i
+		}
+		sort.SliceStable(members, func(i, j int) bool {
+			return rank[members[i].Name()] < rank[members[j].Name()]
+		})
+	}
+
+	var sb strings.Builder
+	w := tabwriter.NewWriter(&sb, 0, 0, 2, ' ', 0)
+	fmt.Fprint(w, "action\tdirection\tnext action\n")
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	for _, action := range members {
+		for i, entry := range p.planEntriesOf(action) {
+			name := ""
+			if i == 0 {
+				name = entry.Action
+			}
+			fmt.Fprintf(w, "%s\t%s\t%s\n", name, entry.Direction, inspectTarget(entry))
+		}
+	}
+	w.Flush()
+
+	return sb.String()
+}
+
+func inspectTarget(entry PlanEntry) string {
+	switch {
+	case !entry.Terminates:
+		return entry.Target
+	case entry.Source == PlanDefault:
+		return "[default: terminate]"
+	default:
+		return "terminate"
+	}
+}
@@ -0,0 +1,102 @@
+package chain
+
+import (
+	"github.com/stretchr/testify/assert"
+	"strings"
+	"testing"
+)
+
+func TestPipeline_ExplainPlans(t *testing.T) {
+	action1 := &DirectingAction{name: "action1"}
+	action2 := &DirectingAction{name: "action2"}
+	action3 := &DirectingAction{name: "action3"}
+	pipeline := NewPipeline("Pipeline", action1, action2, action3)
+	pipeline.SetRunPlan(action2, ActionPlan[int]{Success: action3, Error: Terminate[int]()})
+	assert.NoError(t, pipeline.Divert(action3, Abort, action1))
+
+	assert.Equal(t, []PlanEntry{
+		{Action: "action1", Direction: Success, Target: "action2", Source: PlanDefault},
+		{Action: "action1", Direction: Error, Terminates: true, Source: PlanDefault},
+		{Action: "action1", Direction: Abort, Terminates: true, Source: PlanDefault},
+		{Action: "action2", Direction: Success, Target: "action3", Source: PlanSetRunPlan},
+		{Action: "action2", Direction: Error, Terminates: true, Source: PlanSetRunPlan},
+		{Action: "action2", Direction: Abort, Terminates: true, Source: PlanDefault},
+		{Action: "action3", Direction: Success, Terminates: true, Source: PlanDefault},
+		{Action: "action3", Direction: Error, Terminates: true, Source: PlanDefault},
+		{Action: "action3", Direction: Abort, Target: "action1", Source: PlanDivert},
+	}, pipeline.PlanEntries())
+
+	expected := "`action1`\n" +
+		"  success  -> `action2`  (default)\n" +
+		"  error    -> terminate  (default)\n" +
+		"  abort    -> terminate  (default)\n" +
+		"`action2`\n" +
+		"  success  -> `action3`  (SetRunPlan)\n" +
+		"  error    -> terminate  (SetRunPlan)\n" +
+		"  abort    -> terminate  (default)\n" +
+		"`action3`\n" +
+		"  success  -> terminate  (default)\n" +
+		"  error    -> terminate  (default)\n" +
+		"  abort    -> `action1`  (Divert)\n"
+	assert.Equal(t, expected, pipeline.ExplainPlans())
+}
+
+func TestPipeline_InspectRunPlan(t *testing.T) {
+	t.Run("marks default terminations", func(t *testing.T) {
+		collatz := NewCollatz("Collatz")
+
+		expected := "direction  next action\n" +
+			"success    [default: terminate]\n" +
+			"error      [default: terminate]\n" +
+			"abort      [default: terminate]\n" +
+			"even       OnEven\n" +
+			"odd        OnOdd\n"
+		assert.Equal(t, expected, collatz.InspectRunPlan(collatz.CheckNext))
+		assert.Contains(t, collatz.InspectRunPlan(collatz.OnEven), "success    terminate\n")
+	})
+
+	t.Run("panics on non-member", func(t *testing.T) {
+		collatz := NewCollatz("Collatz")
+
+		assert.PanicsWithError(t, "`SetTen` is not a member of this pipeline", func() {
+			collatz.InspectRunPlan(&SetTen{})
+		})
+	})
+}
+
+func TestPipeline_InspectAllPlans(t *testing.T) {
+	t.Run("lists actions in topological order", func(t *testing.T) {
+		action1 := &DirectingAction{name: "action1"}
+		action2 := &DirectingAction{name: "action2"}
+		action3 := &DirectingAction{name: "action3"}
+		pipeline := NewPipeline("Pipeline", action3, action2, action1)
+		pipeline.SetRunPlan(action3, TerminationPlan[int]())
+		pipeline.SetRunPlan(action1, ActionPlan[int]{Success: action2, Error: Terminate[int]()})
+		pipeline.SetRunPlan(action2, SuccessOnlyPlan(action3))
+		assert.NoError(t, pipeline.SetInitAction(action1))
+
+		expected := "action   direction  next action\n" +
+			"action1  success    action2\n" +
+			"         error      terminate\n" +
+			"         abort      [default: terminate]\n" +
+			"action2  success    action3\n" +
+			"         error      terminate\n" +
+			"         abort      terminate\n" +
+			"action3  success    [default: terminate]\n" +
+			"         error      [default: terminate]\n" +
+			"         abort      [default: terminate]\n"
+		assert.Equal(t, expected, pipeline.InspectAllPlans())
+	})
+
+	t.Run("keeps member order on cycles", func(t *testing.T) {
+		action1 := &DirectingAction{name: "action1"}
+		action2 := &DirectingAction{name: "action2"}
+		pipeline := NewPipeline("Pipeline", action2, action1)
+		pipeline.SetRunPlan(action2, SuccessOnlyPlan(action1))
+		pipeline.SetRunPlan(action1, SuccessOnlyPlan(action2))
+
+		inspected := pipeline.InspectAllPlans()
+
+		assert.Less(t, strings.Index(inspected, "action2  success"), strings.Index(inspected, "action1  success"))
+	})
+}
@@ -0,0 +1,53 @@
+package chain
+
+import (
+	"context"
+	"github.com/sirupsen/logrus"
+)
+
+// WithFallback sets the pipeline to run fallback when a run ends with Error,
+// such as a pipeline reaching a replica when the primary one fails. The fallback runs with the original
+// input, rather than the output of the failed run, and its result is reported in place of the failed one,
+// including the errors of the fallback when it fails as well. Runs ending with Abort do not fall back,
+// as they were stopped on purpose. It returns the pipeline itself to allow chaining.
+//
+// The fallback runs as its own Run does, so it falls back to its own fallback in turn.
+// Passing nil removes the fallback. Fallbacks must not fall back to p, which would never end.
+//
+// Every way of running the pipeline falls back, including the functions of CompileToFunction and CompileFlat,
+// but the ones running a part of it, such as RunToAction, or observing it for tests and profiling,
+// such as TestRun, RunCollect and Profile.
+func (p *Pipeline[T]) WithFallback(fallback *Pipeline[T]) *Pipeline[T] {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.fallback = fallback
+	return p
+}
+
+// fallBack runs the fallback pipeline with the input when the run ended with Error,
+// reporting the result of the fallback in place of the result of the run.
+func (p *Pipeline[T]) fallBack(ctx context.Context, input, output T, direction string, err error) (T, string, error) {
+	p.mu.RLock()
+	fallback := p.fallback
+	p.mu.RUnlock()
+	return fallBackTo(ctx, p.Name(), fallback, input, output, direction, err)
+}
+
+// fallBackTo runs fallback for the run of the pipeline named name as fallBack does,
+// for the runs capturing the fallback beforehand, such as the ones of CompileFlat.
+func fallBackTo[T any](ctx context.Context, name string, fallback *Pipeline[T], input, output T, direction string, err error) (T, string, error) {
+	if fallback == nil || direction != Error {
+		return output, direction, err
+	}
+
+	if logrus.IsLevelEnabled(logrus.DebugLevel) {
+		logrus.Debugf("%s: Falling back to `%s`, caused by %s", name, fallback.Name(), err)
+	}
+	return fallback.runFallingBack(ctx, input)
+}
+
+// runFallingBack runs the pipeline as run does, falling back on Error as Run does.
+func (p *Pipeline[T]) runFallingBack(ctx context.Context, input T) (T, string, error) {
+	output, direction, err := p.run(ctx, input)
+	return p.fallBack(ctx, input, output, direction, err)
+}
@@ -0,0 +1,155 @@
+package chain
+
+import (
+	"context"
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func TestPipeline_WithFallback(t *testing.T) {
+	ctx := context.Background()
+	newIncrement := func() Action[int] {
+		return NewSimpleAction("Increment", func(_ context.Context, input int) (int, error) { return input + 1, nil })
+	}
+	newDouble := func() Action[int] {
+		return NewSimpleAction("Double", func(_ context.Context, input int) (int, error) { return input * 2, nil })
+	}
+
+	t.Run("falls back with original input on error", func(t *testing.T) {
+		primary := NewPipeline("Primary", newIncrement(), ErrorMaker{"primary failed"})
+		fallback := NewPipeline("Fallback", newDouble())
+		assert.Same(t, primary, primary.WithFallback(fallback))
+
+		output, err := primary.Run(ctx, 5)
+
+		assert.NoError(t, err)
+		assert.Equal(t, 10, output)
+		assert.Equal(t, map[string]int64{Error: 1}, primary.Stats().Runs())
+		assert.Equal(t, map[string]int64{Success: 1}, fallback.Stats().Runs())
+	})
+
+	t.Run("reports error of failing fallback", func(t *testing.T) {
+		primary := NewPipeline("Primary", ErrorMaker{"primary failed"})
+		primary.WithFallback(NewPipeline("Fallback", newDouble(), ErrorMaker{"fallback failed"}))
+
+		output, err := primary.Run(ctx, 5)
+
+		assert.EqualError(t, err, "fallback failed")
+		assert.Equal(t, 10, output)
+	})
+
+	t.Run("falls back in turn", func(t *testing.T) {
+		primary := NewPipeline("Primary", ErrorMaker{"primary failed"})
+		secondary := NewPipeline("Secondary", ErrorMaker{"secondary failed"})
+		primary.WithFallback(secondary.WithFallback(NewPipeline("Tertiary", newDouble())))
+
+		output, err := primary.Run(ctx, 5)
+
+		assert.NoError(t, err)
+		assert.Equal(t, 10, output)
+	})
+
+	t.Run("not on success", func(t *testing.T) {
+		fallback := NewPipeline("Fallback", newDouble())
+		primary := NewPipeline("Primary", newIncrement()).WithFallback(fallback)
+
+		output, err := primary.Run(ctx, 5)
+
+		assert.NoError(t, err)
+		assert.Equal(t, 6, output)
+		assert.Empty(t, fallback.Stats().Runs())
+	})
+
+	t.Run("not on abort", func(t *testing.T) {
+		canceled, cancel := context.WithCancel(ctx)
+		cancel()
+		fallback := NewPipeline("Fallback", newDouble())
+		primary := NewPipeline("Primary", newIncrement()).WithFallback(fallback)
+
+		output, err := primary.Run(canceled, 5)
+
+		assert.True(t, errors.Is(err, context.Canceled))
+		assert.Equal(t, 5, output)
+		assert.Empty(t, fallback.Stats().Runs())
+	})
+
+	t.Run("falls back from RunAt", func(t *testing.T) {
+		increment, failing := newIncrement(), ErrorMaker{"primary failed"}
+		primary := NewPipeline("Primary", increment, failing)
+		primary.WithFallback(NewPipeline("Fallback", newDouble()))
+
+		output, err := primary.RunAt(failing, ctx, 5)
+
+		assert.NoError(t, err)
+		assert.Equal(t, 10, output)
+	})
+
+	t.Run("falls back from other runs", func(t *testing.T) {
+		primary := NewPipeline("Primary", newIncrement(), ErrorMaker{"primary failed"})
+		primary.WithFallback(NewPipeline("Fallback", newDouble()))
+		testCases := map[string]func() (int, string, error){
+			"RunInBackground": func() (int, string, error) {
+				result := <-primary.RunInBackground(ctx, 5)
+				return result.Output, result.Direction, result.Err
+			},
+			"RunIf": func() (int, string, error) {
+				return primary.RunIf(ctx, 5, func(int) bool { return true })
+			},
+			"RunWithInputFunc": func() (int, string, error) {
+				return primary.RunWithInputFunc(ctx, func() (int, error) { return 5, nil })
+			},
+			"RunWithDeadline": func() (int, string, error) {
+				return primary.RunWithDeadline(ctx, 5, time.Now().Add(time.Minute))
+			},
+			"RunWithTrace": func() (int, string, error) {
+				result := primary.RunWithTrace(ctx, 5)
+				return result.Output, result.Direction, result.Err
+			},
+			"RunWithSampling": func() (int, string, error) {
+				result := primary.RunWithSampling(ctx, 5, 0)
+				return result.Output, result.Direction, result.Err
+			},
+			"CompileToFunction": func() (int, string, error) {
+				return primary.CompileToFunction()(ctx, 5)
+			},
+			"CompileFlat": func() (int, string, error) {
+				run, err := primary.CompileFlat()
+				assert.NoError(t, err)
+				return run(ctx, 5)
+			},
+		}
+
+		for name, run := range testCases {
+			t.Run(name, func(t *testing.T) {
+				output, direction, err := run()
+
+				assert.NoError(t, err)
+				assert.Equal(t, Success, direction)
+				assert.Equal(t, 10, output)
+			})
+		}
+	})
+
+	t.Run("falls back as member", func(t *testing.T) {
+		inner := NewPipeline("Inner", ErrorMaker{"inner failed"})
+		inner.WithFallback(NewPipeline("Fallback", newDouble()))
+		outer := NewPipeline("Outer", newIncrement(), inner)
+
+		output, err := outer.Run(ctx, 5)
+
+		assert.NoError(t, err)
+		assert.Equal(t, 12, output)
+	})
+
+	t.Run("removed by nil", func(t *testing.T) {
+		primary := NewPipeline("Primary", ErrorMaker{"primary failed"})
+		primary.WithFallback(NewPipeline("Fallback", newDouble())).WithFallback(nil)
+
+		output, err := primary.Run(ctx, 5)
+
+		assert.EqualError(t, err, "primary failed")
+		assert.Equal(t, 5, output)
+	})
+}
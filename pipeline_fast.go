@@ -0,0 +1,89 @@
+package chain
+
+import "github.com/sirupsen/logrus"
+
+// fastPath reports whether a run of the pipeline can take runFast, with the policies it follows.
+// It is allowed for frozen pipelines with nothing but Stats watching the steps: no observers, hooks,
+// taps, metrics or tracing, none of the configurations only runAt follows, and silent debug logging.
+func (p *Pipeline[T]) fastPath(frame *runFrame, config *runConfig[T]) bool {
+	if !config.fast || logrus.IsLevelEnabled(logrus.DebugLevel) {
+		return false
+	}
+
+	// Direction hooks propagated by the parents fire on the steps of nested pipelines as well
+	inherited, _ := frame.Value(propagatedDirectionHooks).([]registeredDirectionHook[T])
+	return len(inherited) == 0
+}
+
+// runFast runs the pipeline as runAt does for the runs fastPath allows, where each step is left with
+// only the call of the action, the count on Stats and the lookup of the next action on the compiled plans.
+func (p *Pipeline[T]) runFast(frame *runFrame, initAction Action[T], input T, config *runConfig[T]) (output T, direction string, lastErr error) {
+	policy, unknownDirection := config.finalDirectionPolicy, config.unknownDirectionPolicy
+	var (
+		terminate     = Terminate[T]()
+		currentAction Action[T]
+		nextAction    Action[T]
+		currentIndex  = p.memberIndex[initAction]
+		nextIndex     = terminated
+		runErr        error
+		stepErrs      []*StepError
+		failedAction  string
+		failedErr     error
+		selectErr     *ErrUnknownDirection
+		branchData    any
+	)
+	if frame.parent != nil {
+		branchData = frame.parent.handoff.received
+	}
+	frame.paths = &p.runnerPaths
+
+	for currentAction = initAction; currentAction != nil; currentAction, currentIndex = nextAction, nextIndex {
+		if ctxErr := frame.Err(); ctxErr != nil {
+			canceledErr := canceledErrorOf(frame, ctxErr)
+			output, direction, lastErr = input, Abort, joinRunErrors(stepErrs, canceledErr)
+			failedAction, failedErr = currentAction.Name(), canceledErr
+			break
+		}
+
+		frame.handoff = branchHandoff{received: branchData}
+		output, direction, runErr, _ = runAction(currentAction, frame, input, false)
+		if runErr != nil && isCanceledBy(frame, runErr) {
+			direction, runErr = Abort, canceledErrorOf(frame, runErr)
+		}
+		p.stats.recordMemberStep(currentIndex, direction)
+
+		if nextAction, nextIndex, selectErr = p.selectNext(currentAction, currentIndex, direction); selectErr != nil {
+			selectErr.Pipeline = frame.runnerName()
+			if unknownDirection != UnknownDirectionTerminate {
+				logrus.Error(selectErr)
+				direction = Abort
+				lastErr = joinRunErrors(stepErrs, selectErr)
+				failedAction, failedErr = currentAction.Name(), selectErr
+				break
+			}
+			logrus.Warn(selectErr)
+		}
+
+		branchData = nil
+		if nextAction != terminate {
+			branchData = frame.handoff.attached
+		}
+		input = output
+		if runErr != nil {
+			stepErrs = append(stepErrs, &StepError{Action: currentAction.Name(), Err: runErr})
+			lastErr = joinRunErrors(stepErrs, nil)
+			failedAction, failedErr = currentAction.Name(), runErr
+		} else if policy == ClearedOnRecovery {
+			stepErrs, lastErr = nil, nil
+		}
+	}
+	if lastErr != nil {
+		lastErr = newPipelineError(frame.runnerName(), failedAction, failedErr, lastErr)
+	}
+	if lastErr != nil && direction != Abort && policy != LastAction {
+		direction = Error
+	}
+	p.stats.recordRun(direction)
+
+	return output, direction, lastErr
+}
@@ -0,0 +1,287 @@
+package chain
+
+import (
+	"context"
+	"fmt"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"os"
+	"testing"
+)
+
+func TestPipeline_FastPath(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("taken by frozen pipelines without observers", func(t *testing.T) {
+		level := logrus.GetLevel()
+		defer logrus.SetLevel(level)
+		logrus.SetLevel(logrus.InfoLevel)
+		testCases := map[string]struct {
+			pipeline func() *Pipeline[int]
+			ctx      context.Context
+			fast     bool
+		}{
+			"frozen": {
+				pipeline: func() *Pipeline[int] { return NewCollatz("Collatz").Freeze() },
+				ctx:      ctx,
+				fast:     true,
+			},
+			"not frozen": {
+				pipeline: func() *Pipeline[int] {
+					collatz := NewCollatz("Collatz")
+					collatz.frozen = false
+					return collatz.Pipeline
+				},
+				ctx:  ctx,
+				fast: false,
+			},
+			"with terminate hooks": {
+				pipeline: func() *Pipeline[int] {
+					collatz := NewCollatz("Collatz").Freeze()
+					collatz.OnTerminate(func(context.Context, int, string, error) {})
+					return collatz
+				},
+				ctx:  ctx,
+				fast: false,
+			},
+			"with tracing": {
+				pipeline: func() *Pipeline[int] {
+					return NewCollatz("Collatz").Freeze().EnableTracing()
+				},
+				ctx:  ctx,
+				fast: false,
+			},
+			"under propagated direction hooks": {
+				pipeline: func() *Pipeline[int] { return NewCollatz("Collatz").Freeze() },
+				ctx: context.WithValue(ctx, propagatedDirectionHooks, []registeredDirectionHook[int]{
+					{direction: Success, fn: func(context.Context, string, int) {}, propagate: true},
+				}),
+				fast: false,
+			},
+		}
+
+		for name, tc := range testCases {
+			t.Run(name, func(t *testing.T) {
+				pipeline := tc.pipeline()
+				config := pipeline.runConfig()
+
+				fast := pipeline.fastPath(&runFrame{Context: tc.ctx, name: pipeline.Name()}, &config)
+
+				assert.Equal(t, tc.fast, fast)
+			})
+		}
+	})
+
+	t.Run("not taken with debug logging", func(t *testing.T) {
+		level := logrus.GetLevel()
+		defer logrus.SetLevel(level)
+		logrus.SetLevel(logrus.DebugLevel)
+		collatz := NewCollatz("Collatz").Freeze()
+		config := collatz.runConfig()
+
+		fast := collatz.fastPath(&runFrame{Context: ctx, name: collatz.Name()}, &config)
+
+		assert.False(t, fast)
+	})
+
+	t.Run("runs as the full loop", func(t *testing.T) {
+		canceled, cancel := context.WithCancel(ctx)
+		cancel()
+		testCases := map[string]struct {
+			pipeline func() *Pipeline[int]
+			ctx      context.Context
+		}{
+			"branching": {
+				pipeline: func() *Pipeline[int] { return NewCollatz("Collatz").Pipeline },
+				ctx:      ctx,
+			},
+			"nested": {
+				pipeline: func() *Pipeline[int] {
+					return NewPipeline[int]("Outer", &DirectingAction{name: "action1"}, NewCollatz("Inner"))
+				},
+				ctx: ctx,
+			},
+			"error": {
+				pipeline: func() *Pipeline[int] {
+					return NewPipeline[int]("Failing", &DirectingAction{name: "action1"}, ErrorMaker{"broken"}, &SetTen{})
+				},
+				ctx: ctx,
+			},
+			"unknown direction": {
+				pipeline: func() *Pipeline[int] {
+					return NewPipeline[int]("Stray", StrayAction{name: "Stray", direction: "nowhere"}, &SetTen{})
+				},
+				ctx: ctx,
+			},
+			"canceled": {
+				pipeline: func() *Pipeline[int] { return NewCollatz("Collatz").Pipeline },
+				ctx:      canceled,
+			},
+		}
+
+		for name, tc := range testCases {
+			t.Run(name, func(t *testing.T) {
+				fast, full := tc.pipeline().Freeze(), tc.pipeline().Freeze()
+
+				for _, input := range []int{5, 6} {
+					fastOutput, fastDirection, fastErr := fast.runAt(fast.entryAction(), tc.ctx, input, nil)
+					fullOutput, fullDirection, fullErr := full.runAt(full.entryAction(), tc.ctx, input, &runHooks[int]{})
+
+					assert.Equal(t, fullOutput, fastOutput)
+					assert.Equal(t, fullDirection, fastDirection)
+					assert.Equal(t, fullErr, fastErr)
+				}
+				assert.Equal(t, full.Stats().Runs(), fast.Stats().Runs())
+				assert.Equal(t, full.Stats().Steps(), fast.Stats().Steps())
+			})
+		}
+	})
+
+	t.Run("counts stats of the runs", func(t *testing.T) {
+		collatz := NewCollatz("Collatz").Freeze()
+		assert.NoError(t, collatz.Stats().Restore(mustSnapshot(t, NewCollatz("Collatz").Pipeline, 5)))
+
+		_, _ = collatz.Run(ctx, 5)
+		_, _ = collatz.Run(ctx, 6)
+
+		assert.Equal(t, map[string]int64{Success: 3}, collatz.Stats().Runs())
+		assert.Equal(t, map[string]map[string]int64{
+			"CheckNext": {"odd": 2, "even": 1},
+			"OnOdd":     {Success: 2},
+			"OnEven":    {Success: 1},
+		}, collatz.Stats().Steps())
+
+		restored := NewCollatz("Collatz").Pipeline
+		assert.NoError(t, restored.Stats().Restore(mustSnapshot(t, collatz)))
+		assert.Equal(t, collatz.Stats().Runs(), restored.Stats().Runs())
+		assert.Equal(t, collatz.Stats().Steps(), restored.Stats().Steps())
+	})
+}
+
+// mustSnapshot takes a snapshot of the stats of the pipeline after running it with the inputs.
+func mustSnapshot(t *testing.T, p *Pipeline[int], inputs ...int) []byte {
+	for _, input := range inputs {
+		_, _ = p.Run(context.Background(), input)
+	}
+	snapshot, err := p.Stats().Snapshot()
+	assert.NoError(t, err)
+	return snapshot
+}
+
+// fastPathAllocs is the most a run on the fast path may allocate, which is the frame of the run.
+const fastPathAllocs = 1
+
+// TestPipeline_FastPathAllocs guards the cost of the fast path, which allocates nothing for the steps,
+// along with TestPipeline_FastPathOverhead guarding its time.
+func TestPipeline_FastPathAllocs(t *testing.T) {
+	level := logrus.GetLevel()
+	defer logrus.SetLevel(level)
+	logrus.SetLevel(logrus.InfoLevel)
+	ctx := context.Background()
+	for _, n := range []int{1, 5, 50} {
+		pipeline, _ := newTrivialPipeline(n)
+		config := pipeline.runConfig()
+		assert.True(t, pipeline.fastPath(&runFrame{Context: ctx, name: pipeline.Name()}, &config))
+
+		allocs := testing.AllocsPerRun(100, func() { _, _ = pipeline.Run(ctx, 1) })
+
+		assert.LessOrEqual(t, allocs, float64(fastPathAllocs), "fast path of %d actions allocates", n)
+	}
+}
+
+// fastPathFactor is the most a run on the fast path may take, relative to calling the same actions
+// in a hand-written loop, when each action takes about a microsecond as the ones doing any real work.
+const fastPathFactor = 1.3
+
+// TestPipeline_FastPathOverhead guards the time of the fast path against a hand-written loop,
+// measured by the benchmarks of both. It depends on the load of the machine, so it only runs with
+// CHAIN_TEST_OVERHEAD set, as CI does on a dedicated runner without the race detector.
+// The measurement is taken up to 3 times, so a busy moment does not fail it at once.
+func TestPipeline_FastPathOverhead(t *testing.T) {
+	if os.Getenv("CHAIN_TEST_OVERHEAD") == "" {
+		t.Skip("the overhead is only measured with CHAIN_TEST_OVERHEAD set")
+	}
+	level := logrus.GetLevel()
+	defer logrus.SetLevel(level)
+	logrus.SetLevel(logrus.InfoLevel)
+	pipeline, actions := newWeightedPipeline(5)
+	ctx := context.Background()
+
+	var factor float64
+	for attempt := 0; attempt < 3; attempt++ {
+		fast := testing.Benchmark(func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				_, _ = pipeline.Run(ctx, i)
+			}
+		})
+		handWritten := testing.Benchmark(func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				runHandWritten(ctx, actions, i)
+			}
+		})
+		if factor = float64(fast.NsPerOp()) / float64(handWritten.NsPerOp()); factor <= fastPathFactor {
+			break
+		}
+	}
+
+	assert.LessOrEqual(t, factor, fastPathFactor, "fast path takes %.2fx of a hand-written loop", factor)
+}
+
+// A frozen pipeline on the fast path, against a hand-written loop calling the same actions,
+// where the actions do nearly nothing so the cost of the steps is measured.
+func BenchmarkRun_FastPath(b *testing.B) {
+	level := logrus.GetLevel()
+	defer logrus.SetLevel(level)
+	logrus.SetLevel(logrus.InfoLevel)
+	pipeline, actions := newTrivialPipeline(5)
+	ctx := context.Background()
+
+	b.Run("FastPath", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			_, _ = pipeline.Run(ctx, i)
+		}
+	})
+	b.Run("HandWritten", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			runHandWritten(ctx, actions, i)
+		}
+	})
+}
+
+// newTrivialPipeline makes a frozen pipeline of n actions in a row, each incrementing the input.
+func newTrivialPipeline(n int) (*Pipeline[int], []Action[int]) {
+	actions := make([]Action[int], n)
+	for i := range actions {
+		actions[i] = NewSimpleAction(fmt.Sprintf("Increment%d", i+1), func(_ context.Context, input int) (int, error) {
+			return input + 1, nil
+		})
+	}
+	return NewPipeline("Trivial", actions...).Freeze(), actions
+}
+
+// newWeightedPipeline makes a frozen pipeline of n actions in a row, each taking about a microsecond.
+func newWeightedPipeline(n int) (*Pipeline[int], []Action[int]) {
+	actions := make([]Action[int], n)
+	for i := range actions {
+		actions[i] = NewSimpleAction(fmt.Sprintf("Weighted%d", i+1), func(_ context.Context, input int) (int, error) {
+			for round := 0; round < 1000; round++ {
+				input = input*31 + round
+			}
+			return input, nil
+		})
+	}
+	return NewPipeline("Weighted", actions...).Freeze(), actions
+}
+
+// runHandWritten calls the actions in order, as a loop written for them would.
+func runHandWritten(ctx context.Context, actions []Action[int], input int) {
+	for _, action := range actions {
+		output, err := action.Run(ctx, input)
+		if err != nil {
+			return
+		}
+		input = output
+	}
+}
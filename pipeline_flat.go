@@ -0,0 +1,333 @@
+package chain
+
+import (
+	"context"
+	"fmt"
+	"github.com/sirupsen/logrus"
+	"strings"
+)
+
+// CompileFlat returns a function running the pipeline as CompileToFunction does, where the members of
+// nested pipelines are inlined into a single flat plan at any depth, so the steps of nested pipelines
+// cost the same as the others. Runs of the function end with the same outputs, directions and errors
+// as runs of the pipeline, and record the same Stats on the pipeline and the nested ones.
+// Runs ending with Error fall back to the fallback of the pipeline as of the compilation, as Run does.
+//
+// The function captures the plans of the pipeline and the nested ones at the time of compilation,
+// so later changes on any of them do not affect it. Nested pipelines no longer run on their own,
+// so their steps see the pipeline as the runner, rather than the nested pipeline.
+//
+// An error is returned when the pipeline or any nested one is configured with what only runs of
+// the pipeline handle, such as retries, observers or hooks, when a nested one has a fallback,
+// or when a pipeline contains itself through its member pipelines, as ValidateGraph reports.
+func (p *Pipeline[T]) CompileFlat() (func(context.Context, T) (T, string, error), error) {
+	f := &flatPlan[T]{}
+	if _, err := f.inline(p, noFlatNode, noFlatNode, "", 0); err != nil {
+		return nil, err
+	}
+	return f.runFallingBack, nil
+}
+
+// noFlatNode is the index for the absent parents of the root level, and the nodes of no nested levels.
+const noFlatNode = -1
+
+// flatPlan holds the members of a pipeline and the nested ones as nodes indexed in a single table,
+// with a level for each pipeline, where the nodes of nested pipelines enter the levels of them.
+type flatPlan[T any] struct {
+	levels []flatLevel[T]
+	nodes  []flatNode[T]
+	depth  int
+	// fallback is the fallback of the root level, the only one the flat runs fall back to
+	fallback *Pipeline[T]
+}
+
+type flatLevel[T any] struct {
+	pipeline *Pipeline[T]
+	// path is appended to the name of the runner for the nested levels, such as `/Inner`
+	path   string
+	parent int
+	node   int
+	depth  int
+	entry  int
+
+	finalDirectionPolicy   FinalDirectionPolicy
+	unknownDirectionPolicy UnknownDirectionPolicy
+}
+
+type flatNode[T any] struct {
+	action Action[T]
+	name   string
+	level  int
+	nested int
+	edges  []compiledEdge
+}
+
+// flatState is the state of a level running, as runAt keeps for a run.
+type flatState struct {
+	stepErrs     []*StepError
+	lastErr      error
+	failedAction string
+	failedErr    error
+}
+
+// inline adds the level of p with its members as nodes, and the levels of the nested pipelines,
+// reporting the index of the level.
+func (f *flatPlan[T]) inline(p *Pipeline[T], parent, node int, path string, depth int) (int, error) {
+	// A pipeline inlined again into its own levels would be inlined forever
+	for ancestor := parent; ancestor != noFlatNode; ancestor = f.levels[ancestor].parent {
+		if f.levels[ancestor].pipeline == p {
+			return 0, fmt.Errorf("pipeline contains itself: %s", f.containment(ancestor, parent, p))
+		}
+	}
+	if feature := p.flatUnsupported(); feature != "" {
+		return 0, fmt.Errorf("cannot flatten `%s` with %s", p.Name(), feature)
+	}
+
+	p.mu.RLock()
+	level := flatLevel[T]{
+		pipeline:               p,
+		path:                   path,
+		parent:                 parent,
+		node:                   node,
+		depth:                  depth,
+		finalDirectionPolicy:   p.finalDirectionPolicy,
+		unknownDirectionPolicy: p.unknownDirectionPolicy,
+	}
+	plans := make([]ActionPlan[T], len(p.members))
+	for i, member := range p.members {
+		plans[i] = p.planOfLocked(member)
+	}
+	initAction, fallback := p.initAction, p.fallback
+	p.mu.RUnlock()
+	// Nested pipelines fall back as members, which a single flat plan cannot follow
+	if depth > 0 && fallback != nil {
+		return 0, fmt.Errorf("cannot flatten `%s` with fallback", p.Name())
+	} else if depth == 0 {
+		f.fallback = fallback
+	}
+
+	levelIndex, base := len(f.levels), len(f.nodes)
+	level.entry = base + p.memberIndex[initAction]
+	f.levels = append(f.levels, level)
+	for _, member := range p.members {
+		f.nodes = append(f.nodes, flatNode[T]{action: member, name: member.Name(), level: levelIndex, nested: noFlatNode})
+	}
+	if depth+1 > f.depth {
+		f.depth = depth + 1
+	}
+
+	for i, plan := range plans {
+		edges := make([]compiledEdge, 0, len(plan))
+		for direction, nextAction := range plan {
+			edge := compiledEdge{direction: direction, next: terminated}
+			if index, isMember := p.memberIndex[nextAction]; isMember {
+				edge.next = base + index
+			}
+			edges = append(edges, edge)
+			// Success comes first, as it is the most frequent direction
+			if direction == Success {
+				last := len(edges) - 1
+				edges[0], edges[last] = edges[last], edges[0]
+			}
+		}
+		f.nodes[base+i].edges = edges
+	}
+
+	for i, member := range p.members {
+		nested, isPipeline := member.(*Pipeline[T])
+		if !isPipeline {
+			continue
+		}
+		nestedLevel, err := f.inline(nested, levelIndex, base+i, path+"/"+nested.Name(), depth+1)
+		if err != nil {
+			return 0, err
+		}
+		f.nodes[base+i].nested = nestedLevel
+	}
+
+	return levelIndex, nil
+}
+
+// containment formats the levels from ancestor down to parent containing p, as `P1` -> `P2` -> `P1`.
+func (f *flatPlan[T]) containment(ancestor, parent int, p *Pipeline[T]) string {
+	names := []string{"`" + p.Name() + "`"}
+	for level := parent; level != f.levels[ancestor].parent; level = f.levels[level].parent {
+		names = append(names, "`"+f.levels[level].pipeline.Name()+"`")
+	}
+	for i, j := 0, len(names)-1; i < j; i, j = i+1, j-1 {
+		names[i], names[j] = names[j], names[i]
+	}
+	return strings.Join(names, " -> ")
+}
+
+// flatUnsupported names the first configuration of the pipeline the flat runs cannot follow, if any.
+func (p *Pipeline[T]) flatUnsupported() string {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	return p.fullRunFeatureLocked()
+}
+
+// fullRunFeatureLocked names the first configuration of the pipeline only runAt follows, if any,
+// which rules out the flat runs and the fast path. p.mu should be held.
+func (p *Pipeline[T]) fullRunFeatureLocked() string {
+	switch {
+	case len(p.retries) > 0:
+		return "retries"
+	case len(p.notifiers) > 0:
+		return "notifiers"
+	case len(p.terminateHooks) > 0:
+		return "terminate hooks"
+	case p.observers != nil:
+		return "observers"
+	case len(p.taps) > 0:
+		return "taps"
+	case len(p.directionHooks) > 0:
+		return "direction hooks"
+	case len(p.directionAliases) > 0:
+		return "direction aliases"
+	case p.circularGuard != nil:
+		return "circular guard"
+	case p.terminateAction != nil:
+		return "terminate action"
+	case p.stepInterval > 0:
+		return "step interval"
+	case p.postCondition != nil:
+		return "post condition"
+	case p.unknownDirectionPolicy == UnknownDirectionPanic:
+		return "UnknownDirectionPanic"
+	case p.directionCheck:
+		return "direction check"
+	case p.tracing:
+		return "tracing"
+	case p.metrics != nil:
+		return "metrics"
+	}
+	return ""
+}
+
+// runFallingBack runs the flat plan, falling back as the root pipeline does.
+func (f *flatPlan[T]) runFallingBack(ctx context.Context, input T) (T, string, error) {
+	output, direction, err := f.run(ctx, input)
+	return fallBackTo(ctx, f.levels[0].pipeline.Name(), f.fallback, input, output, direction, err)
+}
+
+// run runs the flat plan from the entry of the root level, following runAt step by step.
+// Entering a nested node starts its level, and ending a level completes its node on the parent level,
+// as the nested pipeline would complete as a step.
+func (f *flatPlan[T]) run(ctx context.Context, input T) (output T, direction string, lastErr error) {
+	parent, _ := ctx.Value(runFrameKey).(*runFrame)
+	frame := &runFrame{Context: ctx, parent: parent, name: f.levels[0].pipeline.Name()}
+	directionHooks, _ := ctx.Value(propagatedDirectionHooks).([]registeredDirectionHook[T])
+
+	// Only the levels from the root to the running one are kept, indexed by their depths
+	var stack [8]flatState
+	states := stack[:]
+	if f.depth > len(stack) {
+		states = make([]flatState, f.depth)
+	}
+
+	var (
+		current    = f.levels[0].entry
+		completed  = false
+		runErr     error
+		branchData any
+	)
+	if parent != nil {
+		branchData = parent.handoff.received
+	}
+	for {
+		node := &f.nodes[current]
+		level := &f.levels[node.level]
+		state := &states[level.depth]
+		ended := false
+
+		if completed {
+			// The nested level ended with output, direction and lastErr, which completes the node
+			completed, runErr, direction = false, lastErr, Success
+			if runErr != nil {
+				direction = errorDirection(node.action, runErr)
+			}
+			if runErr != nil && isCanceledBy(ctx, runErr) {
+				direction, runErr = Abort, canceledErrorOf(ctx, runErr)
+			}
+		} else if ctxErr := ctx.Err(); ctxErr != nil {
+			canceledErr := canceledErrorOf(ctx, ctxErr)
+			output, direction, ended = input, Abort, true
+			state.lastErr = joinRunErrors(state.stepErrs, canceledErr)
+			state.failedAction, state.failedErr = node.name, canceledErr
+		} else if node.nested != noFlatNode {
+			nested := &f.levels[node.nested]
+			states[nested.depth] = flatState{}
+			current = nested.entry
+			continue
+		} else {
+			frame.handoff = branchHandoff{received: branchData}
+			output, direction, runErr, _ = runAction(node.action, frame, input, false)
+			if runErr != nil && isCanceledBy(ctx, runErr) {
+				direction, runErr = Abort, canceledErrorOf(ctx, runErr)
+			}
+		}
+
+		if !ended {
+			level.pipeline.stats.recordStep(node.name, direction)
+			fireDirectionHooks(ctx, directionHooks, node.name, direction, output)
+
+			next, selectErr := node.selectNext(direction)
+			if selectErr != nil {
+				selectErr.Pipeline = frame.runnerName() + level.path
+				if level.unknownDirectionPolicy != UnknownDirectionTerminate {
+					logrus.Error(selectErr)
+					direction, ended = Abort, true
+					state.lastErr = joinRunErrors(state.stepErrs, selectErr)
+					state.failedAction, state.failedErr = node.name, selectErr
+				} else {
+					logrus.Warn(selectErr)
+				}
+			}
+			if !ended {
+				branchData = nil
+				if next != terminated {
+					branchData = frame.handoff.attached
+				}
+				input = output
+				if runErr != nil {
+					state.stepErrs = append(state.stepErrs, &StepError{Action: node.name, Err: runErr})
+					state.lastErr = joinRunErrors(state.stepErrs, nil)
+					state.failedAction, state.failedErr = node.name, runErr
+				} else if level.finalDirectionPolicy == ClearedOnRecovery {
+					state.stepErrs, state.lastErr = nil, nil
+				}
+				if next != terminated {
+					current = next
+					continue
+				}
+			}
+		}
+
+		// The level ended, as the run of the pipeline would end
+		lastErr = state.lastErr
+		if lastErr != nil {
+			lastErr = newPipelineError(frame.runnerName()+level.path, state.failedAction, state.failedErr, lastErr)
+		}
+		if lastErr != nil && direction != Abort && level.finalDirectionPolicy != LastAction {
+			direction = Error
+		}
+		level.pipeline.stats.recordRun(direction)
+		if level.parent == noFlatNode {
+			return output, direction, lastErr
+		}
+		frame.handoff = branchHandoff{}
+		input, branchData = output, nil
+		current, completed = level.node, true
+	}
+}
+
+// selectNext selects the index of the next node by the direction.
+func (n *flatNode[T]) selectNext(direction string) (int, *ErrUnknownDirection) {
+	for _, edge := range n.edges {
+		if edge.direction == direction {
+			return edge.next, nil
+		}
+	}
+	return terminated, &ErrUnknownDirection{Action: n.name, Direction: direction}
+}
@@ -0,0 +1,168 @@
+package chain
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/stretchr/testify/assert"
+	"math/rand"
+	"testing"
+)
+
+// newFlatTestPipeline makes a pipeline nested 3 levels deep, with branches, recoveries,
+// failures and unknown directions depending on the input.
+func newFlatTestPipeline() *Pipeline[int] {
+	add := func(name string, n int) Action[int] {
+		return NewSimpleAction(name, func(_ context.Context, input int) (int, error) { return input + n, nil })
+	}
+	failOn := func(name string, divisor int) Action[int] {
+		return NewSimpleAction(name, func(_ context.Context, input int) (int, error) {
+			if input%divisor == 0 {
+				return input, fmt.Errorf("%d is divisible by %d", input, divisor)
+			}
+			return input + 3, nil
+		})
+	}
+
+	deepFail, deepAdd := failOn("Deep-Fail", 7), add("Deep-Add", 1)
+	deep := NewPipeline("Deep", deepFail, deepAdd)
+	deep.SetRunPlan(deepFail, ActionPlan[int]{Success: deepAdd, Error: deepAdd})
+	_ = deep.SetFinalDirectionPolicy(ClearedOnRecovery)
+
+	size := NewSimpleBranchAction[int]("Size", nil, []string{"small", "large"}, func(_ context.Context, output int) (string, error) {
+		if output < 100 {
+			return "small", nil
+		}
+		return "large", nil
+	})
+	double := NewSimpleAction("Double", func(_ context.Context, input int) (int, error) { return input * 2, nil })
+	rare := NewSimpleBranchAction[int]("Rare", nil, nil, func(_ context.Context, output int) (string, error) {
+		if output%11 == 0 {
+			return "rare", nil
+		}
+		return Success, nil
+	})
+	middle := NewPipeline("Middle", size, deep, double, rare)
+	middle.SetRunPlan(size, ActionPlan[int]{"small": deep, "large": double})
+	middle.SetRunPlan(deep, ActionPlan[int]{Success: rare, Error: double})
+	_ = middle.SetFinalDirectionPolicy(LastAction)
+
+	outerAdd, outerFail, outerSub := add("Outer-Add", 1), failOn("Outer-Fail", 13), add("Outer-Sub", -5)
+	outer := NewPipeline("Outer", outerAdd, middle, outerFail, outerSub)
+	outer.SetRunPlan(middle, ActionPlan[int]{Success: outerFail, Error: outerSub, Abort: outerSub})
+	return outer
+}
+
+func TestPipeline_CompileFlat(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("runs as nested pipelines on random inputs", func(t *testing.T) {
+		pipeline := newFlatTestPipeline()
+		run, err := pipeline.CompileFlat()
+		assert.NoError(t, err)
+
+		random := rand.New(rand.NewSource(1))
+		for i := 0; i < 1000; i++ {
+			input := random.Intn(300)
+			expectedOutput, expectedDirection, expectedErr := pipeline.run(ctx, input)
+			output, direction, err := run(ctx, input)
+
+			assert.Equal(t, expectedOutput, output, "input %d", input)
+			assert.Equal(t, expectedDirection, direction, "input %d", input)
+			if expectedErr == nil {
+				assert.NoError(t, err, "input %d", input)
+				continue
+			}
+			assert.EqualError(t, err, expectedErr.Error(), "input %d", input)
+			var expectedPipelineErr, pipelineErr *PipelineError
+			assert.True(t, errors.As(expectedErr, &expectedPipelineErr))
+			assert.True(t, errors.As(err, &pipelineErr))
+			assert.Equal(t, expectedPipelineErr.Path, pipelineErr.Path, "input %d", input)
+			assert.Equal(t, expectedPipelineErr.Action, pipelineErr.Action, "input %d", input)
+		}
+	})
+
+	t.Run("runs as nested pipelines on canceled ctx", func(t *testing.T) {
+		pipeline := newFlatTestPipeline()
+		run, err := pipeline.CompileFlat()
+		assert.NoError(t, err)
+		canceledCtx, cancel := context.WithCancel(ctx)
+		cancel()
+
+		expectedOutput, expectedDirection, expectedErr := pipeline.run(canceledCtx, 1)
+		output, direction, err := run(canceledCtx, 1)
+
+		assert.Equal(t, expectedOutput, output)
+		assert.Equal(t, expectedDirection, direction)
+		assert.EqualError(t, err, expectedErr.Error())
+		assert.ErrorIs(t, err, context.Canceled)
+	})
+
+	t.Run("captures plans on compilation", func(t *testing.T) {
+		collatz := NewCollatz("Collatz")
+		run, err := collatz.CompileFlat()
+		assert.NoError(t, err)
+
+		collatz.SetRunPlan(collatz.OnOdd, ActionPlan[int]{Success: collatz.CheckNext})
+		output, direction, err := run(ctx, 5)
+
+		assert.NoError(t, err)
+		assert.Equal(t, Success, direction)
+		assert.Equal(t, 16, output)
+	})
+
+	t.Run("records stats as nested pipelines", func(t *testing.T) {
+		nested, flat := newFlatTestPipeline(), newFlatTestPipeline()
+		run, err := flat.CompileFlat()
+		assert.NoError(t, err)
+
+		for input := 0; input < 300; input++ {
+			_, _, _ = nested.run(ctx, input)
+			_, _, _ = run(ctx, input)
+		}
+
+		for _, path := range [][]int{{}, {1}, {1, 1}} {
+			expected, actual := nested, flat
+			for _, index := range path {
+				expected, actual = expected.members[index].(*Pipeline[int]), actual.members[index].(*Pipeline[int])
+			}
+			assert.Equal(t, expected.Stats().Runs(), actual.Stats().Runs(), expected.Name())
+			assert.Equal(t, expected.Stats().Steps(), actual.Stats().Steps(), expected.Name())
+		}
+	})
+
+	t.Run("rejects unsupported configurations", func(t *testing.T) {
+		inner := NewPipeline[int]("Inner", &SetTen{})
+		assert.NoError(t, inner.Retry(inner.members[0], 1))
+		outer := NewPipeline[int]("Outer", &DirectingAction{name: "action1"}, inner)
+
+		run, err := outer.CompileFlat()
+
+		assert.Nil(t, run)
+		assert.EqualError(t, err, "cannot flatten `Inner` with retries")
+	})
+
+	t.Run("rejects nested fallback", func(t *testing.T) {
+		inner := NewPipeline[int]("Inner", &SetTen{}).WithFallback(NewPipeline[int]("Fallback", &SetTen{}))
+		outer := NewPipeline[int]("Outer", &DirectingAction{name: "action1"}, inner)
+
+		run, err := outer.CompileFlat()
+
+		assert.Nil(t, run)
+		assert.EqualError(t, err, "cannot flatten `Inner` with fallback")
+	})
+	t.Run("rejects pipelines containing themselves", func(t *testing.T) {
+		// NewPipeline cannot make a pipeline containing itself, so the members are assembled directly
+		p1 := NewPipeline[int]("P1", &DirectingAction{name: "action1"})
+		p2 := NewPipeline[int]("P2", p1)
+		p1.memberIndex[p2] = len(p1.members)
+		p1.members = append(p1.members, p2)
+		p1.runPlans[p2] = TerminationPlan[int]()
+		outer := NewPipeline[int]("Outer", &DirectingAction{name: "action0"}, p1)
+
+		run, err := outer.CompileFlat()
+
+		assert.Nil(t, run)
+		assert.EqualError(t, err, "pipeline contains itself: `P1` -> `P2` -> `P1`")
+	})
+}
@@ -0,0 +1,116 @@
+package chain
+
+import (
+	"context"
+	"github.com/stretchr/testify/assert"
+	"os"
+	"testing"
+)
+
+// TestMain runs the tests against the compiled plans of frozen pipelines with CHAIN_TEST_FROZEN set,
+// as every pipeline should behave the same whether frozen or not.
+func TestMain(m *testing.M) {
+	freezeNewPipelines = os.Getenv("CHAIN_TEST_FROZEN") != ""
+	os.Exit(m.Run())
+}
+
+func TestPipeline_Freeze(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("runs as before freezing", func(t *testing.T) {
+		collatz := NewCollatz("Collatz")
+		assert.Same(t, collatz.Pipeline, collatz.Freeze())
+
+		for input, expected := range map[int]int{5: 16, 6: 3} {
+			output, direction, err := collatz.run(ctx, input)
+
+			assert.NoError(t, err)
+			assert.Equal(t, Success, direction)
+			assert.Equal(t, expected, output)
+		}
+	})
+
+	t.Run("follows changes of plans", func(t *testing.T) {
+		collatz := NewCollatz("Collatz")
+		collatz.Freeze()
+		_, _ = collatz.Run(ctx, 5)
+
+		assert.NoError(t, collatz.Divert(collatz.CheckNext, "odd", Terminate[int]()))
+		output, err := collatz.Run(ctx, 5)
+
+		assert.NoError(t, err)
+		assert.Equal(t, 5, output)
+	})
+
+	t.Run("reports unknown directions", func(t *testing.T) {
+		pipeline := NewPipeline("Pipeline", &StrayAction{name: "Stray", direction: "sideways"}).Freeze()
+
+		_, direction, err := pipeline.run(ctx, 1)
+
+		var unknownErr *ErrUnknownDirection
+		assert.ErrorAs(t, err, &unknownErr)
+		assert.Equal(t, Abort, direction)
+		assert.Equal(t, "sideways", unknownErr.Direction)
+	})
+
+	t.Run("stays frozen on compiling to function", func(t *testing.T) {
+		collatz := NewCollatz("Collatz").Freeze()
+
+		output, _, err := collatz.CompileToFunction()(ctx, 5)
+
+		assert.NoError(t, err)
+		assert.Equal(t, 16, output)
+		assert.True(t, collatz.clone().isFrozen())
+	})
+
+	t.Run("interns names of nested runners", func(t *testing.T) {
+		var runners []string
+		record := NewSimpleAction("Record", func(ctx context.Context, input int) (int, error) {
+			runners = append(runners, ctx.Value(parentRunner).(string))
+			return input, nil
+		})
+		deep := NewPipeline("Deep", record).Freeze()
+		outer := NewPipeline("Outer", NewPipeline("Middle", deep).Freeze()).Freeze()
+
+		_, _ = outer.Run(ctx, 1)
+		_, _ = outer.Run(ctx, 2)
+
+		assert.Equal(t, []string{"Outer/Middle/Deep", "Outer/Middle/Deep"}, runners)
+		assert.Equal(t, map[runnerPath]any{{parent: "Outer/Middle", name: "Deep"}: "Outer/Middle/Deep"}, deep.runnerPaths.paths)
+	})
+
+	t.Run("keeps only overridden plans", func(t *testing.T) {
+		action1 := &DirectingAction{name: "action1"}
+		action2 := &DirectingAction{name: "action2"}
+		action3 := &DirectingAction{name: "action3"}
+		route := &RoutingAction{name: "Route", directions: []string{"express", "standard"}}
+		pipeline := NewPipeline[int]("Pipeline", action1, route, action2, action3)
+		pipeline.SetRunPlan(route, ActionPlan[int]{"express": action3, "standard": action2})
+		snapshot := pipeline.Snapshot()
+
+		pipeline.Freeze()
+
+		assert.Len(t, pipeline.runPlans, 1)
+		assert.Equal(t, snapshot, pipeline.Snapshot())
+		assert.Equal(t, ActionPlan[int]{Success: route, Error: Terminate[int](), Abort: Terminate[int]()}, pipeline.planOf(action1))
+
+		assert.NoError(t, pipeline.Divert(action1, Error, action3))
+		assert.Len(t, pipeline.runPlans, 2)
+		assert.Equal(t, action3, pipeline.planOf(action1)[Error])
+		assert.Equal(t, route, pipeline.planOf(action1)[Success])
+	})
+
+	t.Run("follows default plans", func(t *testing.T) {
+		route := &RoutingAction{name: "Route", directions: []string{"express"}}
+		pipeline := NewPipeline[int]("Pipeline", &DirectingAction{name: "action1"}, route).Freeze()
+
+		trace := pipeline.RunWithTrace(ctx, 1).Trace
+
+		assert.Len(t, trace.Steps, 2)
+		next, _, err := pipeline.selectDefault(1, "express")
+		assert.Nil(t, err)
+		assert.Equal(t, Terminate[int](), next)
+		_, _, err = pipeline.selectDefault(1, "sideways")
+		assert.EqualError(t, err, "no action plan from `Route` directing `sideways`")
+	})
+}
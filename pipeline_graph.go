@@ -1,88 +1,219 @@
 package chain
 
-import "fmt"
+import (
+	"fmt"
+	"strings"
+	"sync"
+)
 
 // ValidateGraph ensures the pipeline's graph is connected and acyclic.
 // It checks for cycles first, then verifies that all nodes connected as a single graph.
+// Before checking the graph, it ensures no pipeline contains itself through its member pipelines,
+// as running such pipeline never ends.
+//
+// The containment of member pipelines is checked concurrently with the graph, as they are independent,
+// while the errors are reported in the same order. The directions of each action are walked
+// in the order of Success, Error and Abort followed by the custom ones sorted alphabetically,
+// so the same cycle is reported on every call.
 func (p *Pipeline[T]) ValidateGraph() error {
+	var containmentErr error
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		containmentErr = p.validateContainment()
+	}()
+	graphErr := p.validatePlanGraph()
+	wg.Wait()
+
+	if containmentErr != nil {
+		return containmentErr
+	}
+	return graphErr
+}
+
+// validatePlanGraph checks the plans for cycles and disconnections as ValidateGraph describes.
+func (p *Pipeline[T]) validatePlanGraph() error {
+	graph := p.planGraph()
+
 	// Step 1: Perform DFS from initAction to check for cycles and track visited nodes
-	visited := make(map[Action[T]]int)
-	if err := dfsWithCycleCheck(p.initAction, p.runPlans, visited, []string{}); err != nil {
+	initAction := p.entryAction()
+	visited := make([]int, len(p.members))
+	walk := graphWalk[T]{graph: graph, states: visited}
+	if err := walk.visit(p.memberIndex[initAction]); err != nil {
 		return err
 	}
 
-	// Step 2: After DFS, check if all actions have been visited
-	unvisited := make([]Action[T], 0, len(p.runPlans))
-	for action := range p.runPlans {
-		if visited[action] == notVisited {
-			unvisited = append(unvisited, action)
+	// Step 2: Start new DFS from each node still unvisited, in the order of members.
+	// The new DFS stops at the nodes visited by former ones, as they were already checked,
+	// so each node is walked only once even for pipelines with thousands of members.
+	var visitedFromNewStart []int
+	for newStart := range p.members {
+		if visited[newStart] != notVisited {
+			continue
 		}
-	}
-
-	// Step 3: If there are unvisited nodes, start new DFS from them
-	for len(unvisited) > 0 {
-		newStart := unvisited[0] // Pick any unvisited node
-		visitedFromNewStart := make(map[Action[T]]int)
-		if err := dfsWithCycleCheck(newStart, p.runPlans, visitedFromNewStart, []string{}); err != nil {
+		if visitedFromNewStart == nil {
+			visitedFromNewStart = make([]int, len(p.members))
+		}
+		walk = graphWalk[T]{graph: graph, states: visitedFromNewStart, known: visited, touched: walk.touched[:0]}
+		if err := walk.visit(newStart); err != nil {
 			return err
 		}
 
-		// Step 4: Merge the visited nodes from the current traversal into the overall visited set
-		// If the current traversal's visited nodes intersect with the previously visited ones, they are connected
-		// If there is no intersection, it's a disconnected graph.
+		// Step 3: Merge the visited nodes from the current traversal into the overall visited set.
+		// If the current traversal reached the previously visited nodes, they are connected.
+		// If not, it's a disconnected graph.
 		intersectionFound := false
-		for action := range visitedFromNewStart {
-			if visited[action] != notVisited {
+		for _, node := range walk.touched {
+			if visitedFromNewStart[node] == reachedKnown {
 				intersectionFound = true
+			} else {
+				visited[node] = confirmed
 			}
-			visited[action] = confirmed
+			visitedFromNewStart[node] = notVisited
 		}
-
-		// Step 5: If no intersection found, it means the graph is disconnected
 		if !intersectionFound {
-			return fmt.Errorf("disconnect detected: action `%s` cannot reach the graph started from initAction `%s`", newStart.Name(), p.initAction.Name())
+			return fmt.Errorf("disconnect detected: action `%s` cannot reach the graph started from initAction `%s`", p.members[newStart].Name(), initAction.Name())
 		}
+	}
 
-		// Step 6: Check all nodes have been visited, no need for further checks
-		if len(visited) == len(p.runPlans) {
-			return nil
-		}
+	return nil
+}
+
+// planGraph is the adjacency of the plans, referring to the members by their indexes,
+// built once for walking the whole graph. Edges to termination are left out.
+type planGraph[T any] struct {
+	members []Action[T]
+	edges   [][]graphEdge
+}
 
-		// If there are still unvisited nodes, continue to the next round of DFS
-		stillUnvisited := make([]Action[T], 0, len(unvisited))
-		for _, action := range unvisited {
-			if visited[action] == notVisited {
-				stillUnvisited = append(stillUnvisited, action)
+type graphEdge struct {
+	direction string
+	next      int
+}
+
+// planGraph builds the adjacency of the current plans, with the directions of each member
+// in the order of Success, Error and Abort followed by the custom ones sorted alphabetically.
+func (p *Pipeline[T]) planGraph() *planGraph[T] {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	terminate := Terminate[T]()
+	graph := &planGraph[T]{members: p.members, edges: make([][]graphEdge, len(p.members))}
+	// The edges of all members share a single array, as most members have only a single edge
+	all := make([]graphEdge, 0, len(p.members))
+	for i, action := range p.members {
+		start := len(all)
+		plan, stored := p.runPlans[action]
+		if !stored && i+1 < len(p.members) {
+			// Left on the default plan by freezing
+			all = append(all, graphEdge{direction: Success, next: i + 1})
+		}
+		for _, direction := range []string{Success, Error, Abort} {
+			if nextAction, exists := plan[direction]; exists && nextAction != terminate {
+				all = append(all, graphEdge{direction: direction, next: p.memberIndex[nextAction]})
+			}
+		}
+		custom := len(all)
+		for direction, nextAction := range plan {
+			if nextAction == terminate || direction == Success || direction == Error || direction == Abort {
+				continue
+			}
+			all = append(all, graphEdge{direction: direction, next: p.memberIndex[nextAction]})
+			// Insertion sort, as an action has only a few custom directions
+			for j := len(all) - 1; j > custom && all[j].direction < all[j-1].direction; j-- {
+				all[j], all[j-1] = all[j-1], all[j]
 			}
 		}
-		unvisited = stillUnvisited
+		graph.edges[i] = all[start:len(all):len(all)]
+	}
+
+	return graph
+}
+
+// graphWalk walks a planGraph depth first, reporting the path of the first cycle found.
+// The nodes in known were checked by former walks, so the walk stops there, marking them as reachedKnown.
+// The nodes the walk changed the states of are listed in touched.
+type graphWalk[T any] struct {
+	graph   *planGraph[T]
+	states  []int
+	known   []int
+	touched []int
+	path    []graphStep
+}
+
+// graphStep is a node on the path of a walk, with the direction the walk took from it.
+type graphStep struct {
+	node      int
+	direction string
+}
+
+func (w *graphWalk[T]) visit(node int) error {
+	if w.known != nil && w.known[node] != notVisited {
+		w.mark(node, reachedKnown)
+		return nil
+	}
+	if w.states[node] != notVisited {
+		return fmt.Errorf("cycle detected: %v", w.pathTo(node))
+	}
+
+	w.mark(node, visiting)
+	for _, edge := range w.graph.edges[node] {
+		w.path = append(w.path, graphStep{node: node, direction: edge.direction})
+		if err := w.visit(edge.next); err != nil {
+			return err
+		}
+		w.path = w.path[:len(w.path)-1]
 	}
+	w.states[node] = confirmed
 
 	return nil
 }
 
-func dfsWithCycleCheck[T any](node Action[T], graph map[Action[T]]ActionPlan[T], visited map[Action[T]]int, path []string) error {
-	path = append(path, "`"+node.Name()+"`")
+func (w *graphWalk[T]) mark(node, state int) {
+	if w.states[node] == notVisited {
+		w.touched = append(w.touched, node)
+	}
+	w.states[node] = state
+}
 
-	if visited[node] != notVisited {
-		return fmt.Errorf("cycle detected: %v", path)
+// pathTo formats the path of the walk reaching node, as `a` -success-> `b`.
+func (w *graphWalk[T]) pathTo(node int) []string {
+	path := make([]string, 0, 2*len(w.path)+1)
+	for _, step := range w.path {
+		path = append(path, "`"+w.graph.members[step.node].Name()+"`", "-"+step.direction+"->")
 	}
+	return append(path, "`"+w.graph.members[node].Name()+"`")
+}
 
-	visited[node] = visiting
+// validateContainment walks the member pipelines recursively,
+// and reports the first pipeline found to contain itself, such as `P1` -> `P2` -> `P1`.
+// Members are fixed on construction, so a pipeline made by NewPipeline cannot contain itself,
+// but pipelines assembled by other means are checked as well.
+func (p *Pipeline[T]) validateContainment() error {
+	return walkContainment(p, nil)
+}
 
-	terminate := Terminate[T]()
-	for direction, nextAction := range graph[node] {
-		if nextAction != terminate {
-			edge := "-" + direction + "->"
-			path = append(path, edge)
-			if err := dfsWithCycleCheck(nextAction, graph, visited, path); err != nil {
-				return err
+func walkContainment[T any](p *Pipeline[T], path []*Pipeline[T]) error {
+	for i, ancestor := range path {
+		if ancestor == p {
+			names := make([]string, 0, len(path)-i+1)
+			for _, member := range path[i:] {
+				names = append(names, "`"+member.Name()+"`")
 			}
-			path = path[:len(path)-1]
+			names = append(names, "`"+p.Name()+"`")
+			return fmt.Errorf("pipeline contains itself: %s", strings.Join(names, " -> "))
 		}
 	}
 
-	visited[node] = confirmed
+	path = append(path, p)
+	for _, action := range p.members {
+		if nested, isPipeline := action.(*Pipeline[T]); isPipeline {
+			if err := walkContainment(nested, path); err != nil {
+				return err
+			}
+		}
+	}
 
 	return nil
 }
@@ -91,4 +222,59 @@ const (
 	notVisited = iota
 	visiting
 	confirmed
+	// reachedKnown marks the nodes visited by former walks, where graphWalk stopped
+	reachedKnown
 )
+
+// CycleEdge is an entry of the plans forming a cycle, where From continues to To
+// when it directs Direction.
+type CycleEdge[T any] struct {
+	From      Action[T]
+	Direction string
+	To        Action[T]
+}
+
+// CycleEdges lists every entry of the plans forming a cycle, so all the cycles can be fixed at once
+// instead of fixing the one ValidateGraph reports and validating again.
+// Those are the entries between members of the same strongly connected component,
+// found with Tarjan's algorithm.
+// The edges are ordered by the members, then by their directions with Success, Error and Abort first.
+func (p *Pipeline[T]) CycleEdges() []CycleEdge[T] {
+	terminate := Terminate[T]()
+	plans := make(map[Action[T]]ActionPlan[T], len(p.members))
+	for _, action := range p.members {
+		plans[action] = p.planOf(action)
+	}
+
+	components := stronglyConnected(p.members, func(action Action[T]) []Action[T] {
+		var nextActions []Action[T]
+		for _, nextAction := range plans[action] {
+			if nextAction != terminate {
+				nextActions = append(nextActions, nextAction)
+			}
+		}
+		return nextActions
+	})
+	componentOf := make(map[Action[T]]int, len(p.members))
+	for i, component := range components {
+		for _, action := range component {
+			componentOf[action] = i + 1
+		}
+	}
+
+	var edges []CycleEdge[T]
+	for _, action := range p.members {
+		plan := plans[action]
+		for _, direction := range p.plannedDirectionsOf(action) {
+			nextAction, exists := plan[direction]
+			if !exists || nextAction == terminate {
+				continue
+			}
+			if componentOf[action] != 0 && componentOf[action] == componentOf[nextAction] {
+				edges = append(edges, CycleEdge[T]{From: action, Direction: direction, To: nextAction})
+			}
+		}
+	}
+
+	return edges
+}
@@ -0,0 +1,55 @@
+package chain
+
+import (
+	"errors"
+	"fmt"
+)
+
+// ErrCircularExecution is the error a run aborts with, when the circular guard added by
+// AddCircularGuard finds an action repeating more than allowed.
+var ErrCircularExecution = errors.New("circular execution detected")
+
+// AddCircularGuard guards each run of the pipeline against logical infinite loops,
+// which static validation such as ValidateGraph cannot find,
+// e.g. an action routing back to the previous one on Error while always failing.
+// Each action can run up to maxRepeats more times in a single run; when an action is about to
+// repeat more than that, the run aborts with Abort and an error wrapping ErrCircularExecution.
+//
+// An error is returned when maxRepeats is negative.
+func (p *Pipeline[T]) AddCircularGuard(maxRepeats int) error {
+	if maxRepeats < 0 {
+		return errors.New("repeats allowed by circular guard must not be negative")
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.circularGuard = &maxRepeats
+
+	return nil
+}
+
+// circularGuard counts the visits of actions in a single run.
+type circularGuard[T any] struct {
+	maxRepeats int
+	visits     map[Action[T]]int
+}
+
+// newCircularGuard prepares a guard allowing maxRepeats for a run, or returns nil when the guard was not added.
+func newCircularGuard[T any](maxRepeats *int) *circularGuard[T] {
+	if maxRepeats == nil {
+		return nil
+	}
+	return &circularGuard[T]{maxRepeats: *maxRepeats, visits: map[Action[T]]int{}}
+}
+
+// visit records a visit of the action, and returns an error when it repeats more than allowed.
+func (g *circularGuard[T]) visit(action Action[T]) error {
+	if g == nil {
+		return nil
+	}
+	g.visits[action]++
+	if repeats := g.visits[action] - 1; repeats > g.maxRepeats {
+		return fmt.Errorf("%w: `%s` repeated more than %d times", ErrCircularExecution, action.Name(), g.maxRepeats)
+	}
+	return nil
+}
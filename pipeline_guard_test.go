@@ -0,0 +1,57 @@
+package chain
+
+import (
+	"context"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestPipeline_AddCircularGuard(t *testing.T) {
+	ctx := context.Background()
+	// action1 always errors, and action2 routes back to action1
+	newLoop := func() *Pipeline[int] {
+		action1 := &ErrorMaker{message: "action1"}
+		action2 := &DirectingAction{name: "action2"}
+		pipeline := NewPipeline[int]("Loop", action1, action2)
+		pipeline.SetRunPlan(action1, ActionPlan[int]{Error: action2})
+		pipeline.SetRunPlan(action2, ActionPlan[int]{Success: action1})
+		return pipeline
+	}
+
+	t.Run("abort on first repeat", func(t *testing.T) {
+		pipeline := newLoop()
+		assert.NoError(t, pipeline.AddCircularGuard(0))
+
+		result := pipeline.RunWithTrace(ctx, 1)
+
+		assert.ErrorIs(t, result.Err, ErrCircularExecution)
+		assert.EqualError(t, result.Err, "`action1`: action1\ncircular execution detected: `action1` repeated more than 0 times")
+		assert.Equal(t, Abort, result.Direction)
+		assert.Len(t, result.Trace.Steps, 2)
+	})
+
+	t.Run("allow repeats up to threshold", func(t *testing.T) {
+		pipeline := newLoop()
+		assert.NoError(t, pipeline.AddCircularGuard(2))
+
+		result := pipeline.RunWithTrace(ctx, 1)
+
+		assert.ErrorIs(t, result.Err, ErrCircularExecution)
+		assert.Len(t, result.Trace.Steps, 6)
+	})
+
+	t.Run("guard is reset on each run", func(t *testing.T) {
+		collatz := NewCollatz("Collatz")
+		assert.NoError(t, collatz.AddCircularGuard(0))
+
+		for i := 0; i < 2; i++ {
+			output, err := collatz.Run(ctx, 5)
+			assert.NoError(t, err)
+			assert.Equal(t, 16, output)
+		}
+	})
+
+	t.Run("negative threshold", func(t *testing.T) {
+		assert.EqualError(t, newLoop().AddCircularGuard(-1), "repeats allowed by circular guard must not be negative")
+	})
+}
@@ -0,0 +1,68 @@
+package chain
+
+import "sort"
+
+// UnroutedDirections lists the custom directions declared by BranchActions through Directions(),
+// which were never given a plan by SetRunPlan or Divert and are left to terminate by default.
+// Such directions usually mean a forgotten SetRunPlan, or a vestigial direction of the action.
+// Directions explicitly planned to terminate are not listed, as they are routed on purpose.
+//
+// The result maps the names of actions to their unrouted directions, in the order of Directions().
+// Actions without any unrouted direction are omitted, so a pipeline with honest plans results in an empty map.
+func (p *Pipeline[T]) UnroutedDirections() map[string][]string {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	terminate := Terminate[T]()
+	unrouted := map[string][]string{}
+	for _, action := range p.members {
+		branchAction, isBranch := action.(BranchAction[T])
+		if !isBranch {
+			continue
+		}
+		plan := p.planOfLocked(action)
+		for _, direction := range branchAction.Directions() {
+			if p.planSources[action][direction] != PlanDefault || plan[direction] != terminate {
+				continue
+			}
+			if !contains(unrouted[action.Name()], direction) {
+				unrouted[action.Name()] = append(unrouted[action.Name()], direction)
+			}
+		}
+	}
+
+	return unrouted
+}
+
+// ListDirections lists the directions each member action has a plan for, mapping the names of
+// actions to their directions sorted alphabetically, to see every custom direction at a glance.
+func (p *Pipeline[T]) ListDirections() map[string][]string {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	directions := make(map[string][]string, len(p.members))
+	for _, action := range p.members {
+		for direction := range p.planOfLocked(action) {
+			if !contains(directions[action.Name()], direction) {
+				directions[action.Name()] = append(directions[action.Name()], direction)
+			}
+		}
+		sort.Strings(directions[action.Name()])
+	}
+
+	return directions
+}
+
+// ActionsWithDirection lists the member actions declaring the direction through Directions()
+// of BranchAction, in the order of members, to find which actions can direct a custom direction.
+// Success, Error and Abort are not declared by Directions(), so they result in no actions
+// unless an action declares them explicitly.
+func (p *Pipeline[T]) ActionsWithDirection(direction string) []Action[T] {
+	var actions []Action[T]
+	for _, action := range p.members {
+		if branchAction, isBranch := action.(BranchAction[T]); isBranch && contains(branchAction.Directions(), direction) {
+			actions = append(actions, action)
+		}
+	}
+	return actions
+}
@@ -0,0 +1,58 @@
+package chain
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestPipeline_UnroutedDirections(t *testing.T) {
+	t.Run("default plans", func(t *testing.T) {
+		collatz := NewCollatz("Collatz")
+
+		assert.Equal(t, map[string][]string{}, collatz.UnroutedDirections())
+	})
+
+	t.Run("forgotten and vestigial directions", func(t *testing.T) {
+		route := &RoutingAction{name: "Route", directions: []string{"express", "standard", "hold", "legacy"}}
+		express := &DirectingAction{name: "Express"}
+		pipeline := NewPipeline("Shipping", route, express)
+
+		assert.Equal(t, map[string][]string{
+			"Route": {"express", "standard", "hold", "legacy"},
+		}, pipeline.UnroutedDirections())
+
+		pipeline.SetRunPlan(route, ActionPlan[int]{
+			"express": express,
+			"hold":    Terminate[int](),
+		})
+		assert.Equal(t, map[string][]string{
+			"Route": {"standard", "legacy"},
+		}, pipeline.UnroutedDirections())
+
+		assert.NoError(t, pipeline.Divert(route, "standard", express))
+		assert.NoError(t, pipeline.Divert(route, "legacy", Terminate[int]()))
+		assert.Equal(t, map[string][]string{}, pipeline.UnroutedDirections())
+	})
+}
+
+func TestPipeline_ListDirections(t *testing.T) {
+	collatz := NewCollatz("Collatz")
+
+	assert.Equal(t, map[string][]string{
+		"CheckNext": {Abort, Error, "even", "odd", Success},
+		"OnEven":    {Abort, Error, Success},
+		"OnOdd":     {Abort, Error, Success},
+	}, collatz.ListDirections())
+}
+
+func TestPipeline_ActionsWithDirection(t *testing.T) {
+	route := &RoutingAction{name: "Route", directions: []string{"express", "standard"}}
+	reroute := &RoutingAction{name: "Reroute", directions: []string{"standard"}}
+	express := &DirectingAction{name: "Express"}
+	pipeline := NewPipeline("Shipping", route, express, reroute)
+
+	assert.Equal(t, []Action[int]{route, reroute}, pipeline.ActionsWithDirection("standard"))
+	assert.Equal(t, []Action[int]{route}, pipeline.ActionsWithDirection("express"))
+	assert.Empty(t, pipeline.ActionsWithDirection("hold"))
+	assert.Empty(t, pipeline.ActionsWithDirection(Success))
+}
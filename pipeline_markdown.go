@@ -0,0 +1,176 @@
+package chain
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// MarkdownOption customizes the document generated by ExportMarkdown.
+type MarkdownOption func(*markdownConfig)
+
+type markdownConfig struct {
+	inlineNested bool
+}
+
+// InlineNestedPipelines makes ExportMarkdown describe member pipelines inline,
+// right under the section of the member, instead of linking to separate sections.
+func InlineNestedPipelines() MarkdownOption {
+	return func(c *markdownConfig) { c.inlineNested = true }
+}
+
+// ExportMarkdown renders a Markdown document describing the pipeline.
+// The document starts with the name of the pipeline and a Mermaid diagram of its plans,
+// followed by a section per member action listing its annotations and a table showing
+// where each of its directions routes.
+//
+// Member pipelines get their own sections appended after the parent, which the parent links to.
+// With InlineNestedPipelines, they are described inside the section of the member instead.
+//
+// The output only depends on the structure of the pipeline, so it can be committed
+// and compared against a freshly generated one to detect stale documentation.
+func (p *Pipeline[T]) ExportMarkdown(opts ...MarkdownOption) string {
+	config := markdownConfig{}
+	for _, opt := range opts {
+		opt(&config)
+	}
+
+	w := &markdownWriter[T]{
+		config:  config,
+		anchors: map[*Pipeline[T]]string{},
+		slugs:   map[string]int{},
+	}
+	w.queue = append(w.queue, p)
+	w.anchorOf(p)
+	for i := 0; i < len(w.queue); i++ {
+		if i > 0 {
+			w.sb.WriteString("\n")
+		}
+		w.writePipeline(w.queue[i], 1)
+	}
+
+	return w.sb.String()
+}
+
+type markdownWriter[T any] struct {
+	sb      strings.Builder
+	config  markdownConfig
+	queue   []*Pipeline[T]
+	anchors map[*Pipeline[T]]string
+	slugs   map[string]int
+}
+
+func (w *markdownWriter[T]) writePipeline(p *Pipeline[T], level int) {
+	w.sb.WriteString(markdownHeading(level) + " Pipeline " + markdownCode(p.Name()) + "\n\n")
+	w.sb.WriteString("```mermaid\n")
+	w.sb.WriteString("flowchart LR\n")
+	p.writeMermaidGraph(&w.sb, "", "    ", nil)
+	w.sb.WriteString("```\n")
+
+	terminate := Terminate[T]()
+	for _, action := range p.members {
+		w.sb.WriteString("\n" + markdownHeading(level+1) + " " + markdownCode(action.Name()) + "\n\n")
+
+		nested, isPipeline := action.(*Pipeline[T])
+		switch {
+		case isPipeline && !w.config.inlineNested:
+			w.sb.WriteString("Nested pipeline, described in " + w.linkTo(nested) + ".\n\n")
+		case isPipeline:
+			w.sb.WriteString("Nested pipeline, described below.\n\n")
+		case isBranchAction[T](action):
+			w.sb.WriteString("Branch action.\n\n")
+		default:
+			w.sb.WriteString("Action.\n\n")
+		}
+
+		if annotations := p.Annotations(action); len(annotations) > 0 {
+			keys := make([]string, 0, len(annotations))
+			for key := range annotations {
+				keys = append(keys, key)
+			}
+			sort.Strings(keys)
+			for _, key := range keys {
+				w.sb.WriteString("- " + markdownCode(key) + ": " + annotations[key] + "\n")
+			}
+			w.sb.WriteString("\n")
+		}
+
+		w.sb.WriteString("| Direction | Next action |\n")
+		w.sb.WriteString("| --- | --- |\n")
+		plan := p.planOf(action)
+		for _, direction := range p.plannedDirectionsOf(action) {
+			nextAction, exists := plan[direction]
+			if !exists {
+				continue
+			}
+			target := "_terminate_"
+			if nextAction != terminate {
+				target = markdownCode(nextAction.Name())
+			}
+			w.sb.WriteString("| " + markdownCode(direction) + " | " + target + " |\n")
+		}
+
+		if isPipeline && w.config.inlineNested {
+			w.sb.WriteString("\n")
+			w.writePipeline(nested, level+2)
+		}
+	}
+}
+
+// linkTo returns a link to the section of the nested pipeline,
+// scheduling the section to be written if it is not yet.
+func (w *markdownWriter[T]) linkTo(p *Pipeline[T]) string {
+	anchor, exists := w.anchors[p]
+	if !exists {
+		anchor = w.anchorOf(p)
+		w.queue = append(w.queue, p)
+	}
+	return "[Pipeline " + markdownCode(p.Name()) + "](#" + anchor + ")"
+}
+
+// anchorOf generates the anchor of the pipeline heading the way GitHub does,
+// suffixing a counter when pipelines with the same name are described.
+func (w *markdownWriter[T]) anchorOf(p *Pipeline[T]) string {
+	slug := markdownSlug("Pipeline " + p.Name())
+	anchor := slug
+	if count := w.slugs[slug]; count > 0 {
+		anchor = fmt.Sprintf("%s-%d", slug, count)
+	}
+	w.slugs[slug]++
+	w.anchors[p] = anchor
+	return anchor
+}
+
+func isBranchAction[T any](action Action[T]) bool {
+	_, isBranch := action.(BranchAction[T])
+	return isBranch
+}
+
+func markdownHeading(level int) string {
+	if level > 6 {
+		level = 6
+	}
+	return strings.Repeat("#", level)
+}
+
+func markdownCode(text string) string {
+	text = strings.ReplaceAll(text, "|", `\|`)
+	if strings.Contains(text, "`") {
+		return "`` " + text + " ``"
+	}
+	return "`" + text + "`"
+}
+
+func markdownSlug(heading string) string {
+	var sb strings.Builder
+	for _, r := range strings.ToLower(heading) {
+		switch {
+		case unicode.IsLetter(r), unicode.IsDigit(r), r == '-', r == '_':
+			sb.WriteRune(r)
+		case r == ' ':
+			sb.WriteRune('-')
+		}
+	}
+	return sb.String()
+}
@@ -0,0 +1,123 @@
+package chain
+
+import (
+	"github.com/stretchr/testify/assert"
+	"strings"
+	"testing"
+)
+
+func TestPipeline_ExportMarkdown(t *testing.T) {
+	newPipeline := func() *Pipeline[int] {
+		inner1 := &DirectingAction{name: "inner1"}
+		inner := NewPipeline("Inner", inner1)
+		checkNext, last := &CheckNext{}, &DirectingAction{name: "last"}
+		pipeline := NewPipeline("Outer", checkNext, inner, last)
+		pipeline.SetRunPlan(checkNext, ActionPlan[int]{
+			"even": inner,
+			"odd":  last,
+		})
+		return pipeline
+	}
+
+	t.Run("nested pipelines linked to own sections", func(t *testing.T) {
+		expected := strings.Join([]string{
+			"# Pipeline `Outer`",
+			"",
+			"```mermaid",
+			"flowchart LR",
+			"    start((Start))",
+			`    a0{{"CheckNext"}}`,
+			`    a1[["Inner"]]`,
+			`    a2["last"]`,
+			"    terminate(Terminate)",
+			"    start --> a0",
+			"    a0 -->|success| terminate",
+			"    a0 -->|error| terminate",
+			"    a0 -->|abort| terminate",
+			"    a0 -->|even| a1",
+			"    a0 -->|odd| a2",
+			"    a1 -->|success| a2",
+			"    a1 -->|error| terminate",
+			"    a1 -->|abort| terminate",
+			"    a2 -->|success| terminate",
+			"    a2 -->|error| terminate",
+			"    a2 -->|abort| terminate",
+			"```",
+			"",
+			"## `CheckNext`",
+			"",
+			"Branch action.",
+			"",
+			"| Direction | Next action |",
+			"| --- | --- |",
+			"| `success` | _terminate_ |",
+			"| `error` | _terminate_ |",
+			"| `abort` | _terminate_ |",
+			"| `even` | `Inner` |",
+			"| `odd` | `last` |",
+			"",
+			"## `Inner`",
+			"",
+			"Nested pipeline, described in [Pipeline `Inner`](#pipeline-inner).",
+			"",
+			"| Direction | Next action |",
+			"| --- | --- |",
+			"| `success` | `last` |",
+			"| `error` | _terminate_ |",
+			"| `abort` | _terminate_ |",
+			"",
+			"## `last`",
+			"",
+			"Action.",
+			"",
+			"| Direction | Next action |",
+			"| --- | --- |",
+			"| `success` | _terminate_ |",
+			"| `error` | _terminate_ |",
+			"| `abort` | _terminate_ |",
+			"",
+			"# Pipeline `Inner`",
+			"",
+			"```mermaid",
+			"flowchart LR",
+			"    start((Start))",
+			`    a0["inner1"]`,
+			"    terminate(Terminate)",
+			"    start --> a0",
+			"    a0 -->|success| terminate",
+			"    a0 -->|error| terminate",
+			"    a0 -->|abort| terminate",
+			"```",
+			"",
+			"## `inner1`",
+			"",
+			"Action.",
+			"",
+			"| Direction | Next action |",
+			"| --- | --- |",
+			"| `success` | _terminate_ |",
+			"| `error` | _terminate_ |",
+			"| `abort` | _terminate_ |",
+			"",
+		}, "\n")
+
+		assert.Equal(t, expected, newPipeline().ExportMarkdown())
+	})
+
+	t.Run("nested pipelines inlined", func(t *testing.T) {
+		document := newPipeline().ExportMarkdown(InlineNestedPipelines())
+
+		assert.Contains(t, document, "Nested pipeline, described below.")
+		assert.Contains(t, document, "\n### Pipeline `Inner`\n")
+		assert.Contains(t, document, "\n#### `inner1`\n")
+		assert.NotContains(t, document, "\n# Pipeline `Inner`\n")
+	})
+
+	t.Run("output is deterministic", func(t *testing.T) {
+		pipeline := newPipeline()
+		first := pipeline.ExportMarkdown()
+		for i := 0; i < 10; i++ {
+			assert.Equal(t, first, pipeline.ExportMarkdown())
+		}
+	})
+}
@@ -0,0 +1,50 @@
+package chain
+
+// MemoryEstimatable can be implemented by an Action to report its own memory footprint
+// to Pipeline.MemoryEstimate, such as the size of caches or buffers it holds.
+type MemoryEstimatable interface {
+	EstimateMemory() int64
+}
+
+// Approximate costs in bytes of the structures held by a Pipeline, on 64-bit platforms.
+const (
+	// pipelineCost covers the Pipeline struct itself, with the headers of its maps and slices
+	pipelineCost = 256
+	// memberCost covers the entries of a member in the member slice, member index and runPlans
+	memberCost = 96
+	// planCost covers the header of an ActionPlan map
+	planCost = 48
+	// planEntryCost covers a direction and the next Action in an ActionPlan, with map bookkeeping
+	planEntryCost = 48
+	// annotationCost covers a key and value in an annotation map, with map bookkeeping
+	annotationCost = 48
+)
+
+// MemoryEstimate returns a rough estimate in bytes of the memory the pipeline holds:
+// its plans, annotations, and the members implementing MemoryEstimatable.
+// Member pipelines are estimated recursively.
+//
+// This is a heuristic for tuning memory limits of deployments running many pipelines,
+// based on approximate costs of the data structures, not a precise measurement.
+func (p *Pipeline[T]) MemoryEstimate() int64 {
+	estimate := int64(pipelineCost + len(p.Name()))
+
+	for _, action := range p.members {
+		estimate += memberCost + planCost
+		for direction := range p.planOf(action) {
+			estimate += planEntryCost + int64(len(direction))
+		}
+		for key, value := range p.Annotations(action) {
+			estimate += annotationCost + int64(len(key)+len(value))
+		}
+
+		switch member := action.(type) {
+		case *Pipeline[T]:
+			estimate += member.MemoryEstimate()
+		case MemoryEstimatable:
+			estimate += member.EstimateMemory()
+		}
+	}
+
+	return estimate
+}
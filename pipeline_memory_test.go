@@ -0,0 +1,36 @@
+package chain
+
+import (
+	"context"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestPipeline_MemoryEstimate(t *testing.T) {
+	t.Run("grows with members and plans", func(t *testing.T) {
+		small := NewPipeline("Pipeline", &DirectingAction{name: "action1"})
+		large := NewPipeline("Pipeline", &DirectingAction{name: "action1"}, &DirectingAction{name: "action2"})
+		branching := NewPipeline("Pipeline", &CheckNext{}, &DirectingAction{name: "action2"})
+
+		assert.Greater(t, small.MemoryEstimate(), int64(0))
+		assert.Greater(t, large.MemoryEstimate(), small.MemoryEstimate())
+		assert.Greater(t, branching.MemoryEstimate(), large.MemoryEstimate())
+	})
+
+	t.Run("includes estimatable actions and nested pipelines", func(t *testing.T) {
+		plain := NewPipeline("Pipeline", &DirectingAction{name: "action1"})
+		cached := NewPipeline("Pipeline", &CachingAction{size: 1 << 20})
+		nested := NewPipeline("Pipeline", NewPipeline("Inner", &CachingAction{size: 1 << 20}))
+
+		assert.Equal(t, plain.MemoryEstimate()+1<<20, cached.MemoryEstimate())
+		assert.Greater(t, nested.MemoryEstimate(), cached.MemoryEstimate())
+	})
+}
+
+type CachingAction struct{ size int64 }
+
+func (CachingAction) Name() string { return "CachingAction" }
+func (CachingAction) Run(_ context.Context, input int) (int, error) {
+	return input, nil
+}
+func (c CachingAction) EstimateMemory() int64 { return c.size }
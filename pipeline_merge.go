@@ -0,0 +1,97 @@
+package chain
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// MergeRunPlans takes the plans of other into the pipeline without changing its members, for
+// combining the plans configured separately for the same pipeline. For each member with the same
+// name in other, the directions other planned explicitly, such as by SetRunPlan or Divert, are taken
+// when the pipeline left them on defaults. The next actions are looked up by their names as well.
+//
+// The plans are merged at once, so runs never see them partially merged. An error listing every
+// conflict is returned without changing the pipeline when both planned the same direction of
+// an action explicitly to different actions. An error is returned as well when other continues to an
+// action the pipeline has no member named after, or plans a direction the member does not support.
+// Directions other planned only as targets of its aliases are skipped when the pipeline has no such aliases.
+func (p *Pipeline[T]) MergeRunPlans(other *Pipeline[T]) error {
+	if other == nil {
+		return errors.New("cannot merge plans of nil pipeline")
+	}
+
+	terminate := Terminate[T]()
+	otherAliases := other.aliases()
+	merged := make(map[Action[T]]ActionPlan[T])
+	var conflicts []string
+	for _, otherEntry := range other.PlanEntries() {
+		if otherEntry.Source == PlanDefault {
+			continue
+		}
+		action, exists := p.ActionByName(otherEntry.Action)
+		if !exists {
+			continue
+		}
+		// Targets of the aliases of other are taken only by the pipeline aliasing them as well
+		entry := map[string]bool{otherEntry.Direction: true}
+		if len(withoutAliasTargets(entry, otherAliases, p.plannedDirectionsOf(action))) == 0 {
+			continue
+		}
+		nextAction := terminate
+		if !otherEntry.Terminates {
+			if nextAction, exists = p.ActionByName(otherEntry.Target); !exists {
+				return fmt.Errorf("setting plan from `%s` directing `%s` to non-member `%s`", action.Name(), otherEntry.Direction, otherEntry.Target)
+			}
+		}
+
+		if p.planSourceOf(action, otherEntry.Direction) != PlanDefault {
+			if p.planOf(action)[otherEntry.Direction] != nextAction {
+				conflicts = append(conflicts, fmt.Sprintf("`%s` directing `%s`", action.Name(), otherEntry.Direction))
+			}
+			continue
+		}
+		availableDirections := p.plannedDirectionsOf(action)
+		if nextAction == terminate && !contains(availableDirections, otherEntry.Direction) {
+			return fmt.Errorf("`%s` does not support direction `%s`", action.Name(), otherEntry.Direction)
+		} else if nextAction != terminate {
+			if err := p.validateEdge(action, availableDirections, otherEntry.Direction, nextAction); err != nil {
+				return err
+			}
+		}
+		if merged[action] == nil {
+			merged[action] = ActionPlan[T]{}
+		}
+		merged[action][otherEntry.Direction] = nextAction
+	}
+	if len(conflicts) > 0 {
+		sort.Strings(conflicts)
+		return fmt.Errorf("conflicting plans of %s", strings.Join(conflicts, ", "))
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	for action, mergedPlan := range merged {
+		plan := clonePlan(p.planOfLocked(action))
+		sources := make(map[string]PlanSource, len(p.planSources[action])+len(mergedPlan))
+		for plannedDirection, source := range p.planSources[action] {
+			sources[plannedDirection] = source
+		}
+		for direction, nextAction := range mergedPlan {
+			plan[direction] = nextAction
+			sources[direction] = PlanMerge
+		}
+		p.runPlans[action] = plan
+		p.planSources[action] = sources
+	}
+	p.compiled.Store(nil)
+
+	return nil
+}
+
+func (p *Pipeline[T]) planSourceOf(action Action[T], direction string) PlanSource {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	return p.planSources[action][direction]
+}
@@ -0,0 +1,53 @@
+package chain
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestPipeline_MergeRunPlans(t *testing.T) {
+	newPipeline := func() (*Pipeline[int], []Action[int]) {
+		actions := []Action[int]{&DirectingAction{name: "action1"}, &DirectingAction{name: "action2"}, &DirectingAction{name: "action3"}}
+		return NewPipeline("Pipeline", actions...), actions
+	}
+
+	t.Run("takes directions left on defaults", func(t *testing.T) {
+		pipeline, actions := newPipeline()
+		pipeline.SetRunPlan(actions[0], ActionPlan[int]{Success: actions[1], Error: actions[2]})
+		other, otherActions := newPipeline()
+		other.SetRunPlan(otherActions[0], ActionPlan[int]{Error: otherActions[2]})
+		other.SetRunPlan(otherActions[1], ActionPlan[int]{Success: otherActions[2], Error: otherActions[0]})
+
+		assert.NoError(t, pipeline.MergeRunPlans(other))
+
+		assert.Equal(t, []PlanEntry{
+			{Action: "action2", Direction: Success, Target: "action3", Source: PlanMerge},
+			{Action: "action2", Direction: Error, Target: "action1", Source: PlanMerge},
+			{Action: "action2", Direction: Abort, Terminates: true},
+		}, pipeline.PlanEntries()[3:6])
+		assert.Equal(t, PlanSetRunPlan, pipeline.PlanEntries()[1].Source, "agreeing plans keep their sources")
+	})
+
+	t.Run("lists every conflict", func(t *testing.T) {
+		pipeline, actions := newPipeline()
+		pipeline.SetRunPlan(actions[0], ActionPlan[int]{Error: actions[2], Abort: actions[1]})
+		other, otherActions := newPipeline()
+		other.SetRunPlan(otherActions[0], ActionPlan[int]{Error: otherActions[1], Abort: otherActions[2]})
+		other.SetRunPlan(otherActions[1], ActionPlan[int]{Error: otherActions[0]})
+		before := pipeline.Snapshot()
+
+		err := pipeline.MergeRunPlans(other)
+
+		assert.EqualError(t, err, "conflicting plans of `action1` directing `abort`, `action1` directing `error`")
+		assert.Equal(t, before, pipeline.Snapshot())
+	})
+
+	t.Run("rejects plans to missing actions", func(t *testing.T) {
+		pipeline := NewPipeline[int]("Pipeline", &DirectingAction{name: "action1"}, &DirectingAction{name: "action2"})
+		other, otherActions := newPipeline()
+		other.SetRunPlan(otherActions[0], ActionPlan[int]{Error: otherActions[2]})
+
+		assert.EqualError(t, pipeline.MergeRunPlans(other), "setting plan from `action1` directing `error` to non-member `action3`")
+		assert.EqualError(t, pipeline.MergeRunPlans(nil), "cannot merge plans of nil pipeline")
+	})
+}
@@ -0,0 +1,132 @@
+package chain
+
+import (
+	"fmt"
+	"strings"
+)
+
+// diagramEdge is an edge of the plans, referring to the nodes by their identifiers on diagrams.
+// The edge into initAction is from `start` without a direction,
+// and edges to termination are to `terminate`.
+type diagramEdge struct {
+	from      string
+	direction string
+	to        string
+}
+
+// diagramOf assigns identifiers to the members in the order they were given to the constructor,
+// and lists the edges of their plans following the order of directionsOf,
+// so the same pipeline always results in the same diagram.
+func (p *Pipeline[T]) diagramOf() (ids map[Action[T]]string, edges []diagramEdge) {
+	ids = make(map[Action[T]]string, len(p.members))
+	for i, action := range p.members {
+		ids[action] = fmt.Sprintf("a%d", i)
+	}
+
+	terminate := Terminate[T]()
+	edges = append(edges, diagramEdge{from: "start", to: ids[p.entryAction()]})
+	for _, action := range p.members {
+		plan := p.planOf(action)
+		for _, direction := range p.plannedDirectionsOf(action) {
+			nextAction, exists := plan[direction]
+			if !exists {
+				continue
+			}
+			target := "terminate"
+			if nextAction != terminate {
+				target = ids[nextAction]
+			}
+			edges = append(edges, diagramEdge{from: ids[action], direction: direction, to: target})
+		}
+	}
+
+	return ids, edges
+}
+
+// ToMermaidDiagram renders the plans of the pipeline as a Mermaid flowchart, which GitHub renders
+// inline in Markdown. Each member action is a node, and each plan is an arrow labeled with its direction,
+// ending at a rounded Terminate node for the plans terminating the pipeline.
+//
+// Member pipelines are drawn as subgraphs holding their own plans, and the arrows of the parent
+// point into and out of the subgraph as a whole. A pipeline containing itself is drawn
+// as a single node where it reappears. The output only depends on the structure of the pipeline.
+func (p *Pipeline[T]) ToMermaidDiagram() string {
+	var sb strings.Builder
+	sb.WriteString("flowchart LR\n")
+	p.writeMermaidGraph(&sb, "", "    ", map[*Pipeline[T]]bool{})
+	return sb.String()
+}
+
+// writeMermaidGraph writes the nodes and edges of the pipeline with prefixed identifiers,
+// so the members of nested pipelines never collide with the members of their parents.
+// expanding holds the pipelines being written, to stop at pipelines containing themselves.
+// When expanding is nil, member pipelines are drawn as single nodes instead of subgraphs.
+func (p *Pipeline[T]) writeMermaidGraph(sb *strings.Builder, prefix, indent string, expanding map[*Pipeline[T]]bool) {
+	if expanding != nil {
+		expanding[p] = true
+		defer delete(expanding, p)
+	}
+
+	ids, edges := p.diagramOf()
+	sb.WriteString(indent + prefix + "start((Start))\n")
+	for _, action := range p.members {
+		id := prefix + ids[action]
+		nested, isPipeline := action.(*Pipeline[T])
+		if !isPipeline || expanding == nil || expanding[nested] {
+			sb.WriteString(indent + mermaidNode(id, action.Name(), action) + "\n")
+			continue
+		}
+		sb.WriteString(indent + "subgraph " + id + `["` + mermaidEscape(action.Name()) + `"]` + "\n")
+		sb.WriteString(indent + "    direction LR\n")
+		nested.writeMermaidGraph(sb, id+"_", indent+"    ", expanding)
+		sb.WriteString(indent + "end\n")
+	}
+	if hasTerminateEdge(edges) {
+		sb.WriteString(indent + prefix + mermaidTerminate + "\n")
+	}
+
+	for i := range edges {
+		edges[i].from, edges[i].to = prefix+edges[i].from, prefix+edges[i].to
+	}
+	writeMermaidEdges(sb, indent, edges)
+}
+
+// mermaidTerminate declares the node the plans terminating the pipeline point to.
+const mermaidTerminate = "terminate(Terminate)"
+
+func writeMermaidEdges(sb *strings.Builder, indent string, edges []diagramEdge) {
+	for _, edge := range edges {
+		if edge.direction == "" {
+			sb.WriteString(fmt.Sprintf("%s%s --> %s\n", indent, edge.from, edge.to))
+		} else {
+			sb.WriteString(fmt.Sprintf("%s%s -->|%s| %s\n", indent, edge.from, mermaidEscape(edge.direction), edge.to))
+		}
+	}
+}
+
+func hasTerminateEdge(edges []diagramEdge) bool {
+	for _, edge := range edges {
+		if edge.to == "terminate" {
+			return true
+		}
+	}
+	return false
+}
+
+// mermaidNode declares a node with a shape telling what kind of Action it is:
+// nested pipelines are drawn as subroutines, and BranchActions as hexagons.
+func mermaidNode[T any](id, label string, action Action[T]) string {
+	label = mermaidEscape(label)
+	switch action.(type) {
+	case *Pipeline[T]:
+		return id + `[["` + label + `"]]`
+	case BranchAction[T]:
+		return id + `{{"` + label + `"}}`
+	default:
+		return id + `["` + label + `"]`
+	}
+}
+
+func mermaidEscape(text string) string {
+	return strings.NewReplacer(`"`, "#quot;", "|", "#124;").Replace(text)
+}
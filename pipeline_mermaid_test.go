@@ -0,0 +1,67 @@
+package chain
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestPipeline_ToMermaidDiagram(t *testing.T) {
+	t.Run("draws plans with terminate node", func(t *testing.T) {
+		pipeline := NewPipeline("Pipeline", &ErrorMaker{message: "error1"}, &SetTen{})
+
+		expected := `flowchart LR
+    start((Start))
+    a0["error1"]
+    a1["SetTen"]
+    terminate(Terminate)
+    start --> a0
+    a0 -->|success| a1
+    a0 -->|error| terminate
+    a0 -->|abort| terminate
+    a1 -->|success| terminate
+    a1 -->|error| terminate
+    a1 -->|abort| terminate
+`
+		assert.Equal(t, expected, pipeline.ToMermaidDiagram())
+	})
+
+	t.Run("draws member pipelines as subgraphs", func(t *testing.T) {
+		collatz := NewCollatz("Collatz")
+		outer := NewPipeline("Outer", collatz.Pipeline, &SetTen{})
+
+		expected := `flowchart LR
+    start((Start))
+    subgraph a0["Collatz"]
+        direction LR
+        a0_start((Start))
+        a0_a0{{"CheckNext"}}
+        a0_a1["OnEven"]
+        a0_a2["OnOdd"]
+        a0_terminate(Terminate)
+        a0_start --> a0_a0
+        a0_a0 -->|success| a0_terminate
+        a0_a0 -->|error| a0_terminate
+        a0_a0 -->|abort| a0_terminate
+        a0_a0 -->|even| a0_a1
+        a0_a0 -->|odd| a0_a2
+        a0_a1 -->|success| a0_terminate
+        a0_a1 -->|error| a0_terminate
+        a0_a1 -->|abort| a0_terminate
+        a0_a2 -->|success| a0_terminate
+        a0_a2 -->|error| a0_terminate
+        a0_a2 -->|abort| a0_terminate
+    end
+    a1["SetTen"]
+    terminate(Terminate)
+    start --> a0
+    a0 -->|success| a1
+    a0 -->|error| terminate
+    a0 -->|abort| terminate
+    a1 -->|success| terminate
+    a1 -->|error| terminate
+    a1 -->|abort| terminate
+`
+		assert.Equal(t, expected, outer.ToMermaidDiagram())
+		assert.Equal(t, outer.ToMermaidDiagram(), outer.ToMermaidDiagram())
+	})
+}
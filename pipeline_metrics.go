@@ -0,0 +1,160 @@
+package chain
+
+import (
+	"errors"
+	"expvar"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// metricsPublishing guards publishing the maps of EnableMetrics, as expvar panics on publishing twice,
+// and metricsUsers counting the pipelines using the maps of the actions in each of the published maps,
+// so the maps are removed only when no pipeline shares them anymore.
+var (
+	metricsPublishing sync.Mutex
+	metricsUsers      = map[*expvar.Map]map[string]int{}
+)
+
+// directionCounters names the counters of the directions counted by EnableMetrics.
+var directionCounters = map[string]string{
+	Success: "success_count",
+	Error:   "error_count",
+	Abort:   "abort_count",
+}
+
+// classCounters names the counters of the error classes counted by EnableMetrics.
+var classCounters = map[ErrorClass]string{
+	ErrorClassTransient: "transient_error_count",
+	ErrorClassPermanent: "permanent_error_count",
+}
+
+const durationMetric = "total_duration_ms"
+
+type pipelineMetrics struct {
+	vars    *expvar.Map
+	actions []string
+}
+
+// EnableMetrics publishes the metrics of the member actions with the standard expvar package,
+// served on /debug/vars by importing expvar, without depending on any metrics system.
+// The metrics are published as an expvar.Map named prefix, holding a map for each action keyed by
+// its name, with Int counters `success_count`, `error_count` and `abort_count` of the directions
+// the action directed, `transient_error_count` and `permanent_error_count` of the classes of
+// its errors, and a Float `total_duration_ms` of the time it took. Custom directions are
+// only included in the duration. Members with the same name share their metrics.
+//
+// Different prefixes let multiple pipelines coexist in a process, while pipelines enabled with
+// the same prefix share the map, along with the metrics of the actions with the same names.
+// Enabling again replaces the prefix, and DisableMetrics removes them.
+// It panics when prefix is empty, or already published by others as other than an expvar.Map.
+func (p *Pipeline[T]) EnableMetrics(prefix string) *Pipeline[T] {
+	if prefix == "" {
+		panic(errors.New("metrics prefix cannot be empty"))
+	}
+
+	metrics := &pipelineMetrics{vars: publishMetricsMap(prefix)}
+	for _, action := range p.members {
+		if !contains(metrics.actions, action.Name()) {
+			metrics.actions = append(metrics.actions, action.Name())
+		}
+	}
+	metrics.add()
+
+	p.mu.Lock()
+	previous := p.metrics
+	p.metrics = metrics
+	p.mu.Unlock()
+	if previous != nil {
+		previous.remove()
+	}
+
+	return p
+}
+
+// DisableMetrics stops the metrics published by EnableMetrics, removing the maps of the actions
+// unless other pipelines enabled with the same prefix share them, and returns the pipeline itself.
+// The map named prefix stays published, as expvar cannot unpublish.
+func (p *Pipeline[T]) DisableMetrics() *Pipeline[T] {
+	p.mu.Lock()
+	previous := p.metrics
+	p.metrics = nil
+	p.mu.Unlock()
+	if previous != nil {
+		previous.remove()
+	}
+
+	return p
+}
+
+func publishMetricsMap(name string) *expvar.Map {
+	metricsPublishing.Lock()
+	defer metricsPublishing.Unlock()
+
+	switch published := expvar.Get(name).(type) {
+	case nil:
+		return expvar.NewMap(name)
+	case *expvar.Map:
+		return published
+	default:
+		panic(fmt.Errorf("`%s` is already published as %T", name, published))
+	}
+}
+
+func (m *pipelineMetrics) record(action, direction string, class ErrorClass, elapsed time.Duration) {
+	if m == nil {
+		return
+	}
+	actionVars, _ := m.vars.Get(action).(*expvar.Map)
+	if actionVars == nil {
+		return
+	}
+	if counter, exists := directionCounters[direction]; exists {
+		actionVars.Add(counter, 1)
+	}
+	if counter, exists := classCounters[class]; exists {
+		actionVars.Add(counter, 1)
+	}
+	actionVars.AddFloat(durationMetric, float64(elapsed)/float64(time.Millisecond))
+}
+
+// add makes the maps of the actions the pipeline uses, unless shared with other pipelines.
+func (m *pipelineMetrics) add() {
+	metricsPublishing.Lock()
+	defer metricsPublishing.Unlock()
+
+	users := metricsUsers[m.vars]
+	if users == nil {
+		users = map[string]int{}
+		metricsUsers[m.vars] = users
+	}
+	for _, action := range m.actions {
+		if users[action]++; users[action] > 1 {
+			continue
+		}
+		actionVars := new(expvar.Map).Init()
+		for _, counter := range []string{
+			directionCounters[Success], directionCounters[Error], directionCounters[Abort],
+			classCounters[ErrorClassTransient], classCounters[ErrorClassPermanent],
+		} {
+			actionVars.Set(counter, new(expvar.Int))
+		}
+		actionVars.Set(durationMetric, new(expvar.Float))
+		m.vars.Set(action, actionVars)
+	}
+}
+
+// remove removes the maps of the actions the pipeline used, unless still shared with other pipelines.
+func (m *pipelineMetrics) remove() {
+	metricsPublishing.Lock()
+	defer metricsPublishing.Unlock()
+
+	users := metricsUsers[m.vars]
+	for _, action := range m.actions {
+		if users[action]--; users[action] > 0 {
+			continue
+		}
+		delete(users, action)
+		m.vars.Delete(action)
+	}
+}
@@ -0,0 +1,104 @@
+package chain
+
+import (
+	"context"
+	"expvar"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestPipeline_EnableMetrics(t *testing.T) {
+	ctx := context.Background()
+	metricOf := func(prefix, action, name string) expvar.Var {
+		actionVars, _ := expvar.Get(prefix).(*expvar.Map).Get(action).(*expvar.Map)
+		if actionVars == nil {
+			return nil
+		}
+		return actionVars.Get(name)
+	}
+
+	t.Run("counts directions of actions", func(t *testing.T) {
+		pipeline := NewPipeline("Pipeline", &SetTen{}, &ErrorMaker{message: "failing"}).EnableMetrics("test_metrics_count")
+		t.Cleanup(func() { pipeline.DisableMetrics() })
+
+		_, _ = pipeline.Run(ctx, 1)
+		_, _ = pipeline.Run(ctx, 1)
+
+		assert.Equal(t, int64(2), metricOf("test_metrics_count", "SetTen", "success_count").(*expvar.Int).Value())
+		assert.Equal(t, int64(0), metricOf("test_metrics_count", "SetTen", "error_count").(*expvar.Int).Value())
+		assert.Equal(t, int64(2), metricOf("test_metrics_count", "failing", "error_count").(*expvar.Int).Value())
+		assert.Equal(t, int64(0), metricOf("test_metrics_count", "failing", "abort_count").(*expvar.Int).Value())
+		assert.GreaterOrEqual(t, metricOf("test_metrics_count", "SetTen", "total_duration_ms").(*expvar.Float).Value(), 0.0)
+	})
+
+	t.Run("counts classes of errors", func(t *testing.T) {
+		timingOut := NewSimpleAction("TimingOut", func(_ context.Context, input int) (int, error) {
+			return input, context.DeadlineExceeded
+		})
+		pipeline := NewPipeline("Pipeline", timingOut).EnableMetrics("test_metrics_class")
+		t.Cleanup(func() { pipeline.DisableMetrics() })
+
+		_, _ = pipeline.Run(ctx, 1)
+
+		assert.Equal(t, int64(1), metricOf("test_metrics_class", "TimingOut", "transient_error_count").(*expvar.Int).Value())
+		assert.Equal(t, int64(0), metricOf("test_metrics_class", "TimingOut", "permanent_error_count").(*expvar.Int).Value())
+	})
+
+	t.Run("coexists with other prefixes", func(t *testing.T) {
+		pipeline1 := NewPipeline("Pipeline1", &SetTen{}).EnableMetrics("test_metrics_first")
+		pipeline2 := NewPipeline("Pipeline2", &SetTen{}).EnableMetrics("test_metrics_second")
+		t.Cleanup(func() { pipeline1.DisableMetrics() })
+
+		_, _ = pipeline1.Run(ctx, 1)
+
+		assert.Equal(t, int64(1), metricOf("test_metrics_first", "SetTen", "success_count").(*expvar.Int).Value())
+		assert.Equal(t, int64(0), metricOf("test_metrics_second", "SetTen", "success_count").(*expvar.Int).Value())
+		pipeline2.DisableMetrics()
+	})
+
+	t.Run("disables metrics", func(t *testing.T) {
+		pipeline := NewPipeline("Pipeline", &SetTen{}).EnableMetrics("test_metrics_disable")
+
+		pipeline.DisableMetrics()
+		_, err := pipeline.Run(ctx, 1)
+
+		assert.NoError(t, err)
+		assert.Nil(t, metricOf("test_metrics_disable", "SetTen", "success_count"))
+	})
+
+	t.Run("keeps metrics shared with other pipelines", func(t *testing.T) {
+		pipeline1 := NewPipeline("Pipeline1", &SetTen{}).EnableMetrics("test_metrics_shared")
+		pipeline2 := NewPipeline("Pipeline2", &SetTen{}).EnableMetrics("test_metrics_shared")
+		_, _ = pipeline1.Run(ctx, 1)
+
+		pipeline2.DisableMetrics()
+		_, _ = pipeline1.Run(ctx, 1)
+
+		assert.Equal(t, int64(2), metricOf("test_metrics_shared", "SetTen", "success_count").(*expvar.Int).Value())
+		pipeline1.DisableMetrics()
+		assert.Nil(t, metricOf("test_metrics_shared", "SetTen", "success_count"))
+	})
+
+	t.Run("enables again with another prefix", func(t *testing.T) {
+		pipeline := NewPipeline("Pipeline", &SetTen{}).EnableMetrics("test_metrics_before")
+
+		pipeline.EnableMetrics("test_metrics_after")
+		t.Cleanup(func() { pipeline.DisableMetrics() })
+		_, _ = pipeline.Run(ctx, 1)
+
+		assert.Nil(t, metricOf("test_metrics_before", "SetTen", "success_count"))
+		assert.Equal(t, int64(1), metricOf("test_metrics_after", "SetTen", "success_count").(*expvar.Int).Value())
+	})
+
+	t.Run("panics on invalid prefix", func(t *testing.T) {
+		if expvar.Get("test_metrics_taken") == nil {
+			expvar.NewInt("test_metrics_taken")
+		}
+		pipeline := NewPipeline("Pipeline", &SetTen{})
+
+		assert.PanicsWithError(t, "metrics prefix cannot be empty", func() { pipeline.EnableMetrics("") })
+		assert.PanicsWithError(t, "`test_metrics_taken` is already published as *expvar.Int", func() {
+			pipeline.EnableMetrics("test_metrics_taken")
+		})
+	})
+}
@@ -116,7 +116,7 @@ func TestErrorPropagation(t *testing.T) {
 		output, err := pipeline.Run(context.Background(), 5)
 
 		assert.Error(t, err)
-		assert.Equal(t, err.Error(), "error3")
+		assert.Equal(t, err.Error(), "`error2`: error2\n`error3`: error3")
 		assert.Equal(t, 16, output)
 	})
 
@@ -264,3 +264,122 @@ func (p PanicMaker) Name() string { return p.message }
 func (p PanicMaker) Run(_ context.Context, _ int) (output int, err error) {
 	panic(errors.New(p.message))
 }
+
+func TestPipeline_WithName(t *testing.T) {
+	pipeline := NewPipeline[int]("tmp", &DirectingAction{name: "action"})
+
+	renamed := pipeline.WithName("production")
+
+	assert.Same(t, pipeline, renamed)
+	assert.Equal(t, "production", pipeline.Name())
+	assert.Equal(t, "production", pipeline.RunWithTrace(context.Background(), 1).Trace.Pipeline)
+}
+
+func TestPipeline_RunnerNameOnContext(t *testing.T) {
+	var runnerNames []any
+	recording := NewSimpleAction("Recording", func(ctx context.Context, input int) (int, error) {
+		runnerNames = append(runnerNames, ctx.Value(parentRunner))
+		return input, nil
+	})
+	inner := NewPipeline[int]("Inner", recording)
+	outer := NewPipeline[int]("Outer", recording, inner)
+
+	_, err := outer.Run(context.Background(), 1)
+
+	assert.NoError(t, err)
+	assert.Equal(t, []any{"Outer", "Outer/Inner"}, runnerNames)
+}
+
+func TestPipeline_DeepContains(t *testing.T) {
+	innermost := &DirectingAction{name: "innermost"}
+	middle := &DirectingAction{name: "middle"}
+	outer := &DirectingAction{name: "outer"}
+	innerPipeline := NewPipeline[int]("Inner", innermost)
+	middlePipeline := NewPipeline[int]("Middle", middle, innerPipeline)
+	pipeline := NewPipeline[int]("Outer", outer, middlePipeline)
+
+	assert.True(t, pipeline.Contains(outer))
+	assert.True(t, pipeline.Contains(middlePipeline))
+	assert.False(t, pipeline.Contains(innermost))
+
+	assert.True(t, pipeline.DeepContains(outer))
+	assert.True(t, pipeline.DeepContains(middle))
+	assert.True(t, pipeline.DeepContains(innerPipeline))
+	assert.True(t, pipeline.DeepContains(innermost))
+	assert.False(t, pipeline.DeepContains(&DirectingAction{name: "innermost"}))
+	assert.False(t, innerPipeline.DeepContains(outer))
+}
+
+func TestPipeline_SetInitAction(t *testing.T) {
+	ctx := context.Background()
+	collatz := NewCollatz("Collatz")
+
+	assert.NoError(t, collatz.SetInitAction(collatz.OnOdd))
+	output, err := collatz.Run(ctx, 2)
+	assert.NoError(t, err)
+	assert.Equal(t, 7, output)
+	assert.Contains(t, collatz.ExportMarkdown(), "start((Start))\n    a0{{\"CheckNext\"}}\n    a1[\"OnEven\"]\n    a2[\"OnOdd\"]\n    terminate(Terminate)\n    start --> a2\n")
+
+	assert.EqualError(t, collatz.SetInitAction(&DirectingAction{name: "non-member"}), "`non-member` is not a member of this pipeline")
+	assert.EqualError(t, collatz.SetInitAction(Terminate[int]()), "cannot start with terminate")
+}
+
+func TestSingleActionPipeline(t *testing.T) {
+	ctx := context.Background()
+	passThrough := func() Action[int] {
+		return NewSimpleAction("PassThrough", func(_ context.Context, input int) (int, error) { return input, nil })
+	}
+	type testCase struct {
+		action func() Action[int]
+		plan   func() ActionPlan[int]
+	}
+	testCases := map[string]testCase{
+		"success": {
+			action: func() Action[int] { return &SetTen{} },
+		},
+		"error": {
+			action: func() Action[int] { return &ErrorMaker{message: "failure"} },
+		},
+		"panic": {
+			action: func() Action[int] { return &PanicMaker{} },
+		},
+		"custom direction routed to terminate": {
+			action: func() Action[int] { return &RoutingAction{name: "Route", directions: []string{"left"}} },
+			plan: func() ActionPlan[int] {
+				return ActionPlan[int]{"left": Terminate[int]()}
+			},
+		},
+		"custom direction without plan": {
+			action: func() Action[int] { return &RoutingAction{name: "Route", directions: []string{"left"}} },
+			plan: func() ActionPlan[int] {
+				return ActionPlan[int]{}
+			},
+		},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			action, trivial := tc.action(), passThrough()
+			// Named the same, as the errors of runs carry the names of the pipelines
+			single := NewPipeline("Pipeline", action)
+			double := NewPipeline("Pipeline", action, trivial)
+			if tc.plan != nil {
+				single.SetRunPlan(action, tc.plan())
+				plan := tc.plan()
+				plan[Success] = trivial
+				double.SetRunPlan(action, plan)
+			}
+
+			singleResult := single.RunWithTrace(ctx, 1)
+			doubleResult := double.RunWithTrace(ctx, 1)
+			_, singleRunDirection, singleRunErr := single.run(ctx, 1)
+
+			assert.Equal(t, doubleResult.Output, singleResult.Output)
+			assert.Equal(t, doubleResult.Direction, singleResult.Direction)
+			assert.Equal(t, doubleResult.Err, singleResult.Err)
+			assert.Equal(t, doubleResult.Trace.Steps[0], singleResult.Trace.Steps[0])
+			assert.Equal(t, doubleResult.Direction, singleRunDirection)
+			assert.Equal(t, doubleResult.Err, singleRunErr)
+		})
+	}
+}
@@ -0,0 +1,123 @@
+package chain
+
+import (
+	"context"
+	"github.com/sirupsen/logrus"
+)
+
+// Notifier receives the result of each run of a pipeline, for delivering it without polling,
+// such as acknowledging a message queue which drives the pipeline.
+type Notifier[T any] interface {
+	Notify(pipelineName string, output T, direction string, err error)
+}
+
+// NotifierFunc adapts an ordinary function to a Notifier.
+type NotifierFunc[T any] func(pipelineName string, output T, direction string, err error)
+
+// Notify calls f itself.
+func (f NotifierFunc[T]) Notify(pipelineName string, output T, direction string, err error) {
+	f(pipelineName, output, direction, err)
+}
+
+type registeredNotifier[T any] struct {
+	notifier Notifier[T]
+	async    bool
+}
+
+// NotifyOnComplete registers the notifier to be called right after each run of the pipeline
+// completes, before the result is returned to the caller. Notifiers are called in the order
+// they were registered. A panic on a notifier is recovered and logged,
+// without affecting the result of the run or the other notifiers.
+func (p *Pipeline[T]) NotifyOnComplete(notifier Notifier[T]) {
+	p.registerNotifier(notifier, false)
+}
+
+// NotifyOnCompleteAsync registers the notifier like NotifyOnComplete,
+// but calls it on a new goroutine so the run does not wait for the notifier.
+func (p *Pipeline[T]) NotifyOnCompleteAsync(notifier Notifier[T]) {
+	p.registerNotifier(notifier, true)
+}
+
+func (p *Pipeline[T]) registerNotifier(notifier Notifier[T], async bool) {
+	if notifier == nil {
+		return
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	// Copy on write, so notifying does not need to hold the lock
+	notifiers := make([]registeredNotifier[T], len(p.notifiers), len(p.notifiers)+1)
+	copy(notifiers, p.notifiers)
+	p.notifiers = append(notifiers, registeredNotifier[T]{notifier: notifier, async: async})
+}
+
+// OnTerminate registers fn to be called exactly once at the end of each run of the pipeline,
+// with the output, the direction and the error the run returns, for flushing buffers of the run
+// or emitting a summary of it. fn is called even when the run is aborted by the pipeline itself,
+// such as by cancellation or unplanned directions, and before the run panics with
+// UnknownDirectionPanic. Nested pipelines call their own fn for each of their runs.
+//
+// fn is called before the notifiers of NotifyOnComplete, in the order registered.
+// A panic in fn is recovered and logged, without affecting the result of the run.
+func (p *Pipeline[T]) OnTerminate(fn func(ctx context.Context, output T, direction string, err error)) {
+	if fn == nil {
+		return
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	// Copy on write, so terminating does not need to hold the lock
+	terminateHooks := make([]func(context.Context, T, string, error), len(p.terminateHooks), len(p.terminateHooks)+1)
+	copy(terminateHooks, p.terminateHooks)
+	p.terminateHooks = append(terminateHooks, fn)
+}
+
+// complete finishes a run with its result, recording it and calling the hooks of OnTerminate
+// and the notifiers of NotifyOnComplete.
+func (p *Pipeline[T]) complete(config *runConfig[T], ctx context.Context, output T, direction string, err error) {
+	p.stats.recordRun(direction)
+	p.terminate(config, ctx, output, direction, err)
+	notifyComplete(config, output, direction, err)
+}
+
+// terminate calls the hooks registered by OnTerminate, and then OnPipelineEnd of the observers.
+func (p *Pipeline[T]) terminate(config *runConfig[T], ctx context.Context, output T, direction string, err error) {
+	for _, fn := range config.terminateHooks {
+		callTerminateHook(fn, ctx, config.name, output, direction, err)
+	}
+	if config.observers != nil {
+		config.observers.OnPipelineEnd(ctx, config.name, output, direction, err)
+	}
+}
+
+func callTerminateHook[T any](fn func(context.Context, T, string, error), ctx context.Context, name string, output T, direction string, err error) {
+	defer func() {
+		if panicErr := recover(); panicErr != nil {
+			logrus.Errorf("%s: panic occurred on terminating, caused by %s", name, panicErr)
+		}
+	}()
+
+	fn(ctx, output, direction, err)
+}
+
+// notifyComplete delivers the result of a run to the registered notifiers.
+func notifyComplete[T any](config *runConfig[T], output T, direction string, err error) {
+	name := config.name
+	for _, registered := range config.notifiers {
+		if registered.async {
+			go callNotifier(registered.notifier, name, output, direction, err)
+		} else {
+			callNotifier(registered.notifier, name, output, direction, err)
+		}
+	}
+}
+
+func callNotifier[T any](notifier Notifier[T], name string, output T, direction string, err error) {
+	defer func() {
+		if panicErr := recover(); panicErr != nil {
+			logrus.Errorf("%s: panic occurred on notifying completion, caused by %s", name, panicErr)
+		}
+	}()
+
+	notifier.Notify(name, output, direction, err)
+}
@@ -0,0 +1,132 @@
+package chain
+
+import (
+	"context"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func TestPipeline_NotifyOnComplete(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("notify synchronously", func(t *testing.T) {
+		collatz := NewCollatz("Collatz")
+		var notified []string
+		collatz.NotifyOnComplete(NotifierFunc[int](func(name string, output int, direction string, err error) {
+			notified = append(notified, name+"/"+direction)
+			assert.Equal(t, 16, output)
+			assert.NoError(t, err)
+		}))
+		collatz.NotifyOnComplete(NotifierFunc[int](func(string, int, string, error) {
+			panic("broken notifier")
+		}))
+		collatz.NotifyOnComplete(NotifierFunc[int](func(name string, _ int, _ string, _ error) {
+			notified = append(notified, name)
+		}))
+
+		output, err := collatz.Run(ctx, 5)
+
+		assert.NoError(t, err)
+		assert.Equal(t, 16, output)
+		assert.Equal(t, []string{"Collatz/success", "Collatz"}, notified)
+	})
+
+	t.Run("notify errors", func(t *testing.T) {
+		pipeline := NewPipeline[int]("Pipeline", &ErrorMaker{message: "failure"})
+		var notifiedErr error
+		var notifiedDirection string
+		pipeline.NotifyOnComplete(NotifierFunc[int](func(_ string, _ int, direction string, err error) {
+			notifiedDirection, notifiedErr = direction, err
+		}))
+
+		_, err := pipeline.Run(ctx, 1)
+
+		assert.Error(t, err)
+		assert.Equal(t, Error, notifiedDirection)
+		assert.Equal(t, err, notifiedErr)
+	})
+
+	t.Run("notify asynchronously", func(t *testing.T) {
+		collatz := NewCollatz("Collatz")
+		outputs := make(chan int, 1)
+		collatz.NotifyOnCompleteAsync(NotifierFunc[int](func(_ string, output int, _ string, _ error) {
+			outputs <- output
+		}))
+
+		_, err := collatz.Run(ctx, 16)
+		assert.NoError(t, err)
+
+		select {
+		case output := <-outputs:
+			assert.Equal(t, 8, output)
+		case <-time.After(time.Second):
+			t.Fatal("notifier was not called")
+		}
+	})
+}
+
+func TestPipeline_OnTerminate(t *testing.T) {
+	ctx := context.Background()
+	type outcome struct {
+		output    int
+		direction string
+		err       string
+	}
+	record := func(outcomes *[]outcome) func(context.Context, int, string, error) {
+		return func(_ context.Context, output int, direction string, err error) {
+			o := outcome{output: output, direction: direction}
+			if err != nil {
+				o.err = err.Error()
+			}
+			*outcomes = append(*outcomes, o)
+		}
+	}
+
+	t.Run("called once after coercion", func(t *testing.T) {
+		var outcomes []outcome
+		pipeline := NewPipeline("Pipeline", &ErrorMaker{message: "error1"}, &SetTen{})
+		pipeline.SetRunPlan(pipeline.members[0], ActionPlan[int]{Error: pipeline.members[1]})
+		pipeline.OnTerminate(func(context.Context, int, string, error) { panic("broken hook") })
+		pipeline.OnTerminate(record(&outcomes))
+
+		_, _ = pipeline.Run(ctx, 1)
+
+		assert.Equal(t, []outcome{{output: 10, direction: Error, err: "error1"}}, outcomes)
+	})
+
+	t.Run("called on framework aborts", func(t *testing.T) {
+		var outcomes []outcome
+		collatz := NewCollatz("Collatz")
+		collatz.OnTerminate(record(&outcomes))
+		canceled, cancel := context.WithCancel(ctx)
+		cancel()
+
+		_, _ = collatz.Run(canceled, 5)
+
+		assert.Equal(t, []outcome{{output: 5, direction: Abort, err: "context canceled"}}, outcomes)
+	})
+
+	t.Run("called before panicking on unknown direction", func(t *testing.T) {
+		var outcomes []outcome
+		pipeline := NewPipeline("Pipeline", Action[int](&StrayAction{name: "Stray", direction: "lost"}))
+		assert.NoError(t, pipeline.SetUnknownDirectionPolicy(UnknownDirectionPanic))
+		pipeline.OnTerminate(record(&outcomes))
+
+		assert.Panics(t, func() { _, _ = pipeline.Run(ctx, 1) })
+		assert.Equal(t, []outcome{{output: 1, direction: Abort, err: "no action plan from `Stray` directing `lost`"}}, outcomes)
+	})
+
+	t.Run("called for nested pipelines", func(t *testing.T) {
+		var inner, outer []outcome
+		collatz := NewCollatz("Collatz")
+		collatz.OnTerminate(record(&inner))
+		pipeline := NewPipeline("Pipeline", collatz.Pipeline, &SetTen{})
+		pipeline.OnTerminate(record(&outer))
+
+		_, _ = pipeline.Run(ctx, 5)
+
+		assert.Equal(t, []outcome{{output: 16, direction: Success}}, inner)
+		assert.Equal(t, []outcome{{output: 10, direction: Success}}, outer)
+	})
+}
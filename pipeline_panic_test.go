@@ -31,6 +31,13 @@ func TestPanicOnConfiguration(t *testing.T) {
 			panics:  true,
 			message: "no actions were described for creating pipeline",
 		},
+		"renaming with empty name": {
+			code: func() {
+				NewPipeline[string]("Pipeline", &Blank{"action"}).WithName("")
+			},
+			panics:  true,
+			message: "pipeline must have a name",
+		},
 		"creating with terminate": {
 			code: func() {
 				action1 := &Blank{"action"}
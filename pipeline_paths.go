@@ -0,0 +1,98 @@
+package chain
+
+// Path is a possible execution path through a pipeline, from its initAction to termination.
+type Path []PathStep
+
+// PathStep is a single step of a Path: the action run, and the direction it took.
+type PathStep struct {
+	Action    string
+	Direction string
+}
+
+// PathOption customizes the enumeration of EnumeratePaths.
+type PathOption func(*pathConfig)
+
+type pathConfig struct {
+	maxVisits int
+}
+
+// MaxVisits bounds how many times a single path may visit the same action, which makes
+// EnumeratePaths go around cycles of the plans up to the given number of times.
+// The default is 1, enumerating only the simple paths.
+func MaxVisits(n int) PathOption {
+	return func(c *pathConfig) { c.maxVisits = n }
+}
+
+// EnumeratePaths lists the possible paths from initAction to termination, following every direction
+// of the plans. The paths are explored depth-first, following the directions of each action
+// in the order of Success, Error, Abort and then its custom directions, so the result is
+// deterministic for the same pipeline.
+//
+// At most maxPaths paths are returned, and the returned bool reports whether there were more paths
+// beyond the limit. A non-positive maxPaths enumerates all paths.
+func (p *Pipeline[T]) EnumeratePaths(maxPaths int, opts ...PathOption) ([]Path, bool) {
+	config := pathConfig{maxVisits: 1}
+	for _, opt := range opts {
+		opt(&config)
+	}
+
+	e := &pathEnumerator[T]{
+		pipeline:  p,
+		config:    config,
+		maxPaths:  maxPaths,
+		visits:    map[Action[T]]int{},
+		terminate: Terminate[T](),
+	}
+	e.explore(p.entryAction())
+
+	return e.paths, e.truncated
+}
+
+type pathEnumerator[T any] struct {
+	pipeline  *Pipeline[T]
+	config    pathConfig
+	maxPaths  int
+	visits    map[Action[T]]int
+	current   Path
+	paths     []Path
+	truncated bool
+	terminate Action[T]
+}
+
+// explore walks every direction from the action, returning false once the limit of paths is hit.
+func (e *pathEnumerator[T]) explore(action Action[T]) bool {
+	e.visits[action]++
+	defer func() { e.visits[action]-- }()
+
+	plan := e.pipeline.planOf(action)
+	for _, direction := range e.pipeline.plannedDirectionsOf(action) {
+		nextAction, exists := plan[direction]
+		if !exists {
+			continue
+		}
+
+		e.current = append(e.current, PathStep{Action: action.Name(), Direction: direction})
+		proceed := true
+		if nextAction == e.terminate {
+			proceed = e.record()
+		} else if e.visits[nextAction] < e.config.maxVisits {
+			proceed = e.explore(nextAction)
+		}
+		e.current = e.current[:len(e.current)-1]
+
+		if !proceed {
+			return false
+		}
+	}
+
+	return true
+}
+
+func (e *pathEnumerator[T]) record() bool {
+	if e.maxPaths > 0 && len(e.paths) == e.maxPaths {
+		e.truncated = true
+		return false
+	}
+	e.paths = append(e.paths, append(Path(nil), e.current...))
+	return true
+}
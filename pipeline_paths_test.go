@@ -0,0 +1,73 @@
+package chain
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestPipeline_EnumeratePaths(t *testing.T) {
+	t.Run("branching paths", func(t *testing.T) {
+		collatz := NewCollatz("Collatz")
+
+		paths, truncated := collatz.EnumeratePaths(0)
+
+		assert.False(t, truncated)
+		assert.Equal(t, []Path{
+			{{"CheckNext", Success}},
+			{{"CheckNext", Error}},
+			{{"CheckNext", Abort}},
+			{{"CheckNext", "even"}, {"OnEven", Success}},
+			{{"CheckNext", "even"}, {"OnEven", Error}},
+			{{"CheckNext", "even"}, {"OnEven", Abort}},
+			{{"CheckNext", "odd"}, {"OnOdd", Success}},
+			{{"CheckNext", "odd"}, {"OnOdd", Error}},
+			{{"CheckNext", "odd"}, {"OnOdd", Abort}},
+		}, paths)
+	})
+
+	t.Run("truncated by limit", func(t *testing.T) {
+		collatz := NewCollatz("Collatz")
+
+		paths, truncated := collatz.EnumeratePaths(4)
+
+		assert.True(t, truncated)
+		assert.Len(t, paths, 4)
+		assert.Equal(t, Path{{"CheckNext", "even"}, {"OnEven", Success}}, paths[3])
+	})
+
+	t.Run("exactly the limit is not truncated", func(t *testing.T) {
+		collatz := NewCollatz("Collatz")
+
+		paths, truncated := collatz.EnumeratePaths(9)
+
+		assert.False(t, truncated)
+		assert.Len(t, paths, 9)
+	})
+
+	t.Run("cycles bounded by visits", func(t *testing.T) {
+		fetch, backoff := &DirectingAction{name: "Fetch"}, &DirectingAction{name: "Backoff"}
+		pipeline := NewPipeline("Fetching", fetch, backoff)
+		pipeline.SetRunPlan(fetch, ActionPlan[int]{Error: backoff})
+		pipeline.SetRunPlan(backoff, ActionPlan[int]{Success: fetch})
+
+		simplePaths, _ := pipeline.EnumeratePaths(0)
+		loopingPaths, _ := pipeline.EnumeratePaths(0, MaxVisits(2))
+
+		assert.Equal(t, []Path{
+			{{"Fetch", Success}},
+			{{"Fetch", Error}, {"Backoff", Error}},
+			{{"Fetch", Error}, {"Backoff", Abort}},
+			{{"Fetch", Abort}},
+		}, simplePaths)
+		assert.Equal(t, []Path{
+			{{"Fetch", Success}},
+			{{"Fetch", Error}, {"Backoff", Success}, {"Fetch", Success}},
+			{{"Fetch", Error}, {"Backoff", Success}, {"Fetch", Error}, {"Backoff", Error}},
+			{{"Fetch", Error}, {"Backoff", Success}, {"Fetch", Error}, {"Backoff", Abort}},
+			{{"Fetch", Error}, {"Backoff", Success}, {"Fetch", Abort}},
+			{{"Fetch", Error}, {"Backoff", Error}},
+			{{"Fetch", Error}, {"Backoff", Abort}},
+			{{"Fetch", Abort}},
+		}, loopingPaths)
+	})
+}
@@ -0,0 +1,73 @@
+package chain
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+)
+
+// ReachableFrom analyzes which actions can possibly run when starting the pipeline from the given
+// action with RunAt, and which directions the run can terminate with.
+// The actions are listed in the order of the members, including the starting action itself.
+// The exits are the directions of the edges leading to termination, listing Success, Error and Abort
+// first, followed by custom directions in alphabetical order.
+//
+// An error is returned when the action is not a member of the pipeline.
+func (p *Pipeline[T]) ReachableFrom(action Action[T]) (actions []string, exits []string, err error) {
+	if action == nil {
+		return nil, nil, errors.New("cannot analyze reachability from terminate")
+	} else if !isMemberActionInPipeline(action, p) {
+		return nil, nil, fmt.Errorf("`%s` is not a member of this pipeline", action.Name())
+	}
+
+	reached, exitDirections := p.walkFrom(action)
+
+	for _, member := range p.members {
+		if reached[member] {
+			actions = append(actions, member.Name())
+		}
+	}
+	for _, direction := range []string{Success, Error, Abort} {
+		if exitDirections[direction] {
+			exits = append(exits, direction)
+			delete(exitDirections, direction)
+		}
+	}
+	customExits := make([]string, 0, len(exitDirections))
+	for direction := range exitDirections {
+		customExits = append(customExits, direction)
+	}
+	sort.Strings(customExits)
+	exits = append(exits, customExits...)
+
+	return actions, exits, nil
+}
+
+// walkFrom follows the plans from the action, collecting the actions reached including itself,
+// and the directions leading to termination on the way.
+func (p *Pipeline[T]) walkFrom(action Action[T]) (reached map[Action[T]]bool, exitDirections map[string]bool) {
+	terminate := Terminate[T]()
+	reached = map[Action[T]]bool{action: true}
+	exitDirections = map[string]bool{}
+	queue := []Action[T]{action}
+	for len(queue) > 0 {
+		current := queue[0]
+		queue = queue[1:]
+
+		plan := p.planOf(current)
+		for _, direction := range p.plannedDirectionsOf(current) {
+			nextAction, exists := plan[direction]
+			if !exists {
+				continue
+			}
+			if nextAction == terminate {
+				exitDirections[direction] = true
+			} else if !reached[nextAction] {
+				reached[nextAction] = true
+				queue = append(queue, nextAction)
+			}
+		}
+	}
+
+	return reached, exitDirections
+}
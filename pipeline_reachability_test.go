@@ -0,0 +1,76 @@
+package chain
+
+import (
+	"context"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestPipeline_ReachableFrom(t *testing.T) {
+	t.Run("branching graph", func(t *testing.T) {
+		validate := &DirectingAction{name: "Validate"}
+		route := &RoutingAction{name: "Route", directions: []string{"express", "standard", "hold"}}
+		express := &DirectingAction{name: "Express"}
+		standard := &DirectingAction{name: "Standard"}
+		notify := &DirectingAction{name: "Notify"}
+		pipeline := NewPipeline("Shipping", validate, route, express, standard, notify)
+		pipeline.SetRunPlan(route, ActionPlan[int]{
+			"express":  express,
+			"standard": standard,
+			"hold":     Terminate[int](),
+		})
+		pipeline.SetRunPlan(express, SuccessOnlyPlan(notify))
+		pipeline.SetRunPlan(standard, SuccessOnlyPlan(notify))
+
+		actions, exits, err := pipeline.ReachableFrom(route)
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"Route", "Express", "Standard", "Notify"}, actions)
+		assert.Equal(t, []string{Success, Error, Abort, "hold"}, exits)
+
+		actions, exits, err = pipeline.ReachableFrom(standard)
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"Standard", "Notify"}, actions)
+		assert.Equal(t, []string{Success, Error, Abort}, exits)
+	})
+
+	t.Run("cyclic graph", func(t *testing.T) {
+		action1 := &DirectingAction{name: "action1"}
+		action2 := &DirectingAction{name: "action2"}
+		action3 := &DirectingAction{name: "action3"}
+		pipeline := NewPipeline("Pipeline", action1, action2, action3)
+		// action1 -> action2 <-> action3, only action3 errors out
+		pipeline.SetRunPlan(action1, ActionPlan[int]{Success: action2})
+		pipeline.SetRunPlan(action2, ActionPlan[int]{Success: action3, Error: action3, Abort: action3})
+		pipeline.SetRunPlan(action3, ActionPlan[int]{Success: action2, Abort: action2})
+
+		actions, exits, err := pipeline.ReachableFrom(action2)
+
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"action2", "action3"}, actions)
+		assert.Equal(t, []string{Error}, exits)
+	})
+
+	t.Run("non-member", func(t *testing.T) {
+		pipeline := NewPipeline("Pipeline", &DirectingAction{name: "member"})
+
+		_, _, err := pipeline.ReachableFrom(&DirectingAction{name: "non-member"})
+		assert.EqualError(t, err, "`non-member` is not a member of this pipeline")
+
+		_, _, err = pipeline.ReachableFrom(Terminate[int]())
+		assert.EqualError(t, err, "cannot analyze reachability from terminate")
+	})
+}
+
+type RoutingAction struct {
+	name       string
+	directions []string
+}
+
+func (r RoutingAction) Name() string         { return r.name }
+func (r RoutingAction) Directions() []string { return r.directions }
+func (RoutingAction) Run(_ context.Context, input int) (int, error) {
+	return input, nil
+}
+func (r RoutingAction) NextDirection(_ context.Context, _ int) (string, error) {
+	return r.directions[0], nil
+}
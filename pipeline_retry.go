@@ -0,0 +1,61 @@
+package chain
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/sirupsen/logrus"
+)
+
+// Retry makes the pipeline run the action again with the same input, up to n more times,
+// while the action directs Error. The Error plan of the action is followed only when
+// every attempt has failed, so the retry configuration stays next to the plans of the pipeline
+// instead of wrapping the action. Retries stop early when ctx is done,
+// or the error is classified as permanent by the classifier set with SetErrorClassifier.
+// Setting n to 0 disables retrying of the action.
+//
+// An error is returned when the action is not a member of the pipeline, or n is negative.
+func (p *Pipeline[T]) Retry(action Action[T], n int) error {
+	if action == nil {
+		return errors.New("cannot retry terminate")
+	} else if !isMemberActionInPipeline(action, p) {
+		return fmt.Errorf("`%s` is not a member of this pipeline", action.Name())
+	}
+	if n < 0 {
+		return fmt.Errorf("retry count for `%s` must not be negative", action.Name())
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	// Copy on write, so runs can keep the retries without holding the lock
+	retries := make(map[Action[T]]int, len(p.retries)+1)
+	for retried, count := range p.retries {
+		retries[retried] = count
+	}
+	if n == 0 {
+		delete(retries, action)
+	} else {
+		retries[action] = n
+	}
+	p.retries = retries
+
+	return nil
+}
+
+// runMember runs a member action of the pipeline, retrying it as configured by Retry,
+// and logging it when tracing is enabled by EnableTracing.
+func (p *Pipeline[T]) runMember(config *runConfig[T], action Action[T], ctx context.Context, input T) (output T, direction string, err error, recovered any) {
+	retries, classify := config.retries[action], config.classify
+	output, direction, err, recovered = runAction(action, ctx, input, config.tracing)
+	for attempt := 1; attempt <= retries && direction == Error; attempt++ {
+		if ctx.Err() != nil || classifyError(classify, err) == ErrorClassPermanent {
+			break
+		}
+		if logrus.IsLevelEnabled(logrus.DebugLevel) {
+			logrus.Debugf("%s: Retrying (%d/%d), caused by %s", action.Name(), attempt, retries, err)
+		}
+		output, direction, err, recovered = runAction(action, ctx, input, config.tracing)
+	}
+
+	return output, direction, err, recovered
+}
@@ -0,0 +1,73 @@
+package chain
+
+import (
+	"context"
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestPipeline_Retry(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("succeeds within retries", func(t *testing.T) {
+		flaky := &FlakyAction{failures: 2}
+		pipeline := NewPipeline("Pipeline", Action[int](flaky), &DirectingAction{name: "next"})
+		assert.NoError(t, pipeline.Retry(flaky, 2))
+
+		result := pipeline.RunWithTrace(ctx, 1)
+
+		assert.NoError(t, result.Err)
+		assert.Equal(t, 3, flaky.attempts)
+		assert.Equal(t, []StepOutcome{
+			{Action: "Flaky", Direction: Success},
+			{Action: "next", Direction: Success},
+		}, result.Trace.Steps)
+	})
+
+	t.Run("follows error plan after retries", func(t *testing.T) {
+		flaky := &FlakyAction{failures: 5}
+		pipeline := NewPipeline("Pipeline", Action[int](flaky))
+		assert.NoError(t, pipeline.Retry(flaky, 2))
+
+		_, err := pipeline.Run(ctx, 1)
+
+		assert.EqualError(t, err, "flaky failure")
+		assert.Equal(t, 3, flaky.attempts)
+	})
+
+	t.Run("disable retry", func(t *testing.T) {
+		flaky := &FlakyAction{failures: 5}
+		pipeline := NewPipeline("Pipeline", Action[int](flaky))
+		assert.NoError(t, pipeline.Retry(flaky, 2))
+		assert.NoError(t, pipeline.Retry(flaky, 0))
+
+		_, err := pipeline.Run(ctx, 1)
+
+		assert.Error(t, err)
+		assert.Equal(t, 1, flaky.attempts)
+	})
+
+	t.Run("invalid configuration", func(t *testing.T) {
+		member := &DirectingAction{name: "member"}
+		pipeline := NewPipeline[int]("Pipeline", member)
+
+		assert.EqualError(t, pipeline.Retry(Terminate[int](), 1), "cannot retry terminate")
+		assert.EqualError(t, pipeline.Retry(&DirectingAction{name: "non-member"}, 1), "`non-member` is not a member of this pipeline")
+		assert.EqualError(t, pipeline.Retry(member, -1), "retry count for `member` must not be negative")
+	})
+}
+
+type FlakyAction struct {
+	failures int
+	attempts int
+}
+
+func (*FlakyAction) Name() string { return "Flaky" }
+func (f *FlakyAction) Run(_ context.Context, input int) (int, error) {
+	f.attempts++
+	if f.attempts <= f.failures {
+		return input, errors.New("flaky failure")
+	}
+	return input, nil
+}
@@ -0,0 +1,200 @@
+package chain
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/sirupsen/logrus"
+	"time"
+)
+
+// RunIf runs the pipeline only when cond returns true for the input,
+// reporting the output, the direction the run ended with, and the error.
+// When cond returns false, the input passes through unchanged with Success and no actions are run.
+// A panic in cond is recovered, and reported with Abort.
+func (p *Pipeline[T]) RunIf(ctx context.Context, input T, cond func(T) bool) (output T, direction string, err error) {
+	shouldRun, err := checkCondition(cond, input)
+	if err != nil {
+		logrus.Errorf("%s: panic occurred on checking condition, caused by %s", p.Name(), err)
+		return input, Abort, err
+	}
+	if !shouldRun {
+		return input, Success, nil
+	}
+
+	return p.runFallingBack(ctx, input)
+}
+
+func checkCondition[T any](cond func(T) bool, input T) (result bool, err error) {
+	defer func() {
+		if panicErr := recover(); panicErr != nil {
+			result, err = false, panicToError(panicErr)
+		}
+	}()
+
+	return cond(input), nil
+}
+
+// RunWithInputFunc runs the pipeline with the input made by inputFn,
+// reporting the output, the direction the run ended with, and the error.
+// inputFn is called only when the pipeline is about to run, so expensive inputs such as
+// the ones read from databases are not made for runs that would not start.
+// When ctx is already done, the run aborts as it does before any action, without calling inputFn.
+//
+// When inputFn returns an error, it is reported with Error without running any actions.
+// A panic in inputFn is recovered, and reported with Abort.
+func (p *Pipeline[T]) RunWithInputFunc(ctx context.Context, inputFn func() (T, error)) (output T, direction string, err error) {
+	if ctxErr := ctx.Err(); ctxErr != nil {
+		return output, Abort, canceledErrorOf(ctx, ctxErr)
+	}
+
+	input, panicked, err := makeInput(inputFn)
+	if err != nil {
+		logrus.Errorf("%s: failed to make input, caused by %s", p.Name(), err)
+		if panicked {
+			return input, Abort, err
+		}
+		return input, Error, err
+	}
+
+	return p.runFallingBack(ctx, input)
+}
+
+func makeInput[T any](inputFn func() (T, error)) (input T, panicked bool, err error) {
+	defer func() {
+		if panicErr := recover(); panicErr != nil {
+			panicked, err = true, panicToError(panicErr)
+		}
+	}()
+
+	input, err = inputFn()
+	return input, false, err
+}
+
+// ActionStub is the result an action is replaced with by TestRun.
+// When Direction is empty, Success is directed without Err, and Error with Err.
+type ActionStub[T any] struct {
+	Output    T
+	Direction string
+	Err       error
+}
+
+func (s ActionStub[T]) result() (output T, direction string, err error, recovered any) {
+	direction = s.Direction
+	if direction == "" {
+		direction = Success
+		if s.Err != nil {
+			direction = Error
+		}
+	}
+	return s.Output, direction, s.Err, nil
+}
+
+// TestRun runs the pipeline as Run does, but replaces the results of the actions named in
+// actionOutputs with their stubs without running them, reporting the output,
+// the direction the run ended with, and the error. It suits integration tests running
+// the plans of the pipeline as they are, while stubbing the actions calling external systems.
+// Members of nested pipelines are not replaced, while the nested pipelines themselves can be.
+func (p *Pipeline[T]) TestRun(ctx context.Context, input T, actionOutputs map[string]ActionStub[T]) (output T, direction string, err error) {
+	return p.runAt(p.entryAction(), ctx, input, &runHooks[T]{stubs: actionOutputs})
+}
+
+// RunOption customizes a single run of RunSafe.
+type RunOption[T any] func(*runHooks[T])
+
+// WithPanicHandler makes RunSafe end the run with the results of fn when a member action panics,
+// instead of continuing the run with Abort as usual. fn receives the value recovered from the panic,
+// so it can decide the direction by the type of the panic, or return the state built so far.
+// When fn panics as well, the original panic is raised again.
+//
+// Panics of the members of nested pipelines are recovered by the nested pipelines as usual,
+// so fn only handles panics of the members of the pipeline RunSafe is called on.
+func WithPanicHandler[T any](fn func(recovered any) (T, string, error)) RunOption[T] {
+	return func(h *runHooks[T]) { h.panicHandler = fn }
+}
+
+// RunSafe runs the pipeline as Run does with the options,
+// reporting the output, the direction the run ended with, and the error.
+// Without options, a panic of an action is recovered into an error, and the run continues with Abort.
+func (p *Pipeline[T]) RunSafe(ctx context.Context, input T, opts ...RunOption[T]) (output T, direction string, err error) {
+	hooks := &runHooks[T]{}
+	for _, opt := range opts {
+		opt(hooks)
+	}
+
+	output, direction, err = p.runAt(p.entryAction(), ctx, input, hooks)
+	return p.fallBack(ctx, input, output, direction, err)
+}
+
+// RunToAction runs the pipeline as Run does, but stops right before running target,
+// reporting the output of the action preceding target, the direction it directed, and the error
+// of the run so far. It suits testing and debugging a part of the pipeline without building
+// another pipeline with Slice. When target is the action runs start with,
+// the input is reported with Success without running any actions.
+//
+// An error is returned when target is not a member of the pipeline,
+// or the run ends without reaching target.
+func (p *Pipeline[T]) RunToAction(target Action[T], ctx context.Context, input T) (output T, direction string, err error) {
+	if target == nil {
+		return input, Error, errors.New("cannot run to terminate")
+	} else if !isMemberActionInPipeline(target, p) {
+		return input, Error, fmt.Errorf("`%s` is not a member of this pipeline", target.Name())
+	}
+
+	hooks := &runHooks[T]{stopBefore: target}
+	output, direction, err = p.runAt(p.entryAction(), ctx, input, hooks)
+	if !hooks.stopped {
+		notReached := fmt.Errorf("run ended without reaching `%s`", target.Name())
+		return output, direction, errors.Join(notReached, err)
+	}
+
+	return output, direction, err
+}
+
+// RunInBackground runs the pipeline on a new goroutine, and delivers the result of the run
+// on the returned channel, which suits code waiting on multiple channels with select.
+// The channel is buffered, so the goroutine finishes even if the result is never received.
+// Cancelling ctx aborts the run before its next action, delivering the result with Abort.
+// The Trace of the delivered result is left empty.
+func (p *Pipeline[T]) RunInBackground(ctx context.Context, input T) <-chan RunResult[T] {
+	resultCh := make(chan RunResult[T], 1)
+	go func() {
+		result := RunResult[T]{Trace: RunTrace{Pipeline: p.Name()}}
+		result.Output, result.Direction, result.Err = p.runFallingBack(ctx, input)
+		p.describeResult(&result)
+		resultCh <- result
+	}()
+	return resultCh
+}
+
+// RunWithDeadline runs the pipeline with ctx bounded by the absolute deadline,
+// reporting the output, the direction the run ended with, and the error.
+// It suits deadlines propagated from external systems, such as the deadline of an HTTP request.
+// When the deadline passes, the run aborts before its next action with context.DeadlineExceeded.
+func (p *Pipeline[T]) RunWithDeadline(ctx context.Context, input T, deadline time.Time) (output T, direction string, err error) {
+	ctx, cancel := context.WithDeadline(ctx, deadline)
+	defer cancel()
+
+	return p.runFallingBack(ctx, input)
+}
+
+// RunChain runs the pipeline n times in sequence, reporting the output, the direction
+// and the error of the last run. The first run takes input, and each later run takes the output
+// of the run before it, so the outputs are threaded through the runs as the outputs of actions
+// are threaded through the steps.
+// Each run falls back as Run does, and a run ending with an error, Error or Abort stops the chain,
+// reporting the result of that run.
+func (p *Pipeline[T]) RunChain(ctx context.Context, input T, n int) (output T, direction string, err error) {
+	if n < 1 {
+		return input, Error, fmt.Errorf("chain must run at least once, but %d runs were given", n)
+	}
+
+	output = input
+	for i := 0; i < n; i++ {
+		output, direction, err = p.runFallingBack(ctx, output)
+		if err != nil || direction == Error || direction == Abort {
+			break
+		}
+	}
+	return output, direction, err
+}
@@ -0,0 +1,202 @@
+package chain
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// ExportRunMermaid renders the plans of the pipeline as a Mermaid flowchart,
+// overlaid with the path a run has taken as recorded on the trace.
+// Each visited action is labeled with the order of its visits, so an action visited
+// multiple times on a loop stays as a single node listing all of its visit numbers.
+// Edges taken by the run are highlighted, actions which returned an error are marked as failed,
+// and actions and edges not taken by the run are dimmed.
+//
+// Steps are matched to the members by the names of actions,
+// so the member actions should have unique names to get an accurate overlay.
+func ExportRunMermaid[T any](p *Pipeline[T], trace RunTrace) string {
+	ids, edges := p.diagramOf()
+	overlay := overlayRun(p, ids, edges, trace)
+
+	var sb strings.Builder
+	sb.WriteString("flowchart LR\n")
+	sb.WriteString("    start((Start))\n")
+	var visited, failed, dimmed []string
+	for _, action := range p.members {
+		id := ids[action]
+		sb.WriteString("    " + mermaidNode(id, overlay.label(id, action.Name()), action) + "\n")
+		switch {
+		case overlay.failed[id]:
+			failed = append(failed, id)
+		case len(overlay.visits[id]) > 0:
+			visited = append(visited, id)
+		default:
+			dimmed = append(dimmed, id)
+		}
+	}
+	if hasTerminateEdge(edges) {
+		sb.WriteString("    " + mermaidTerminate + "\n")
+		if !overlay.terminated {
+			dimmed = append(dimmed, "terminate")
+		}
+	}
+	writeMermaidEdges(&sb, "    ", edges)
+
+	sb.WriteString("    classDef visited stroke:#2b7a0b,stroke-width:2px\n")
+	sb.WriteString("    classDef failed fill:#ffe6e6,stroke:#cc3333,stroke-width:2px\n")
+	sb.WriteString("    classDef dimmed fill:#f4f4f4,stroke:#bbbbbb,color:#999999\n")
+	writeMermaidClass(&sb, "visited", visited)
+	writeMermaidClass(&sb, "failed", failed)
+	writeMermaidClass(&sb, "dimmed", dimmed)
+
+	var taken, notTaken []string
+	for i, edge := range edges {
+		if overlay.executed[edge] {
+			taken = append(taken, strconv.Itoa(i))
+		} else {
+			notTaken = append(notTaken, strconv.Itoa(i))
+		}
+	}
+	if len(taken) > 0 {
+		sb.WriteString("    linkStyle " + strings.Join(taken, ",") + " stroke:#2b7a0b,stroke-width:3px\n")
+	}
+	if len(notTaken) > 0 {
+		sb.WriteString("    linkStyle " + strings.Join(notTaken, ",") + " stroke:#cccccc\n")
+	}
+
+	return sb.String()
+}
+
+// ExportRunDOT renders the same overlay as ExportRunMermaid in the Graphviz DOT language.
+func ExportRunDOT[T any](p *Pipeline[T], trace RunTrace) string {
+	ids, edges := p.diagramOf()
+	overlay := overlayRun(p, ids, edges, trace)
+
+	const (
+		takenStyle    = `color="#2b7a0b", penwidth=3`
+		notTakenStyle = `color="#cccccc", fontcolor="#999999"`
+		visitedStyle  = `color="#2b7a0b", penwidth=2`
+		failedStyle   = `style=filled, fillcolor="#ffe6e6", color="#cc3333", penwidth=2`
+		dimmedStyle   = `style=filled, fillcolor="#f4f4f4", color="#bbbbbb", fontcolor="#999999"`
+	)
+
+	var sb strings.Builder
+	sb.WriteString("digraph " + dotQuote(p.Name()) + " {\n")
+	sb.WriteString("    rankdir=LR;\n")
+	sb.WriteString(`    start [label="Start", shape=circle];` + "\n")
+	for _, action := range p.members {
+		id := ids[action]
+		style := dimmedStyle
+		switch {
+		case overlay.failed[id]:
+			style = failedStyle
+		case len(overlay.visits[id]) > 0:
+			style = visitedStyle
+		}
+		sb.WriteString(fmt.Sprintf("    %s [label=%s, %s, %s];\n", id, dotQuote(overlay.label(id, action.Name())), dotShape(action), style))
+	}
+	if hasTerminateEdge(edges) {
+		style := ""
+		if !overlay.terminated {
+			style = ", " + dimmedStyle
+		}
+		sb.WriteString(`    terminate [label="End", shape=doublecircle` + style + "];\n")
+	}
+	for _, edge := range edges {
+		style := notTakenStyle
+		if overlay.executed[edge] {
+			style = takenStyle
+		}
+		if edge.direction != "" {
+			style = "label=" + dotQuote(edge.direction) + ", " + style
+		}
+		sb.WriteString(fmt.Sprintf("    %s -> %s [%s];\n", edge.from, edge.to, style))
+	}
+	sb.WriteString("}\n")
+
+	return sb.String()
+}
+
+type runOverlay struct {
+	visits     map[string][]int
+	failed     map[string]bool
+	executed   map[diagramEdge]bool
+	terminated bool
+}
+
+// overlayRun follows the steps of the trace on the diagram of the pipeline.
+func overlayRun[T any](p *Pipeline[T], ids map[Action[T]]string, edges []diagramEdge, trace RunTrace) runOverlay {
+	byName := make(map[string]string, len(p.members))
+	for i := len(p.members) - 1; i >= 0; i-- {
+		byName[p.members[i].Name()] = ids[p.members[i]]
+	}
+
+	overlay := runOverlay{
+		visits:   map[string][]int{},
+		failed:   map[string]bool{},
+		executed: map[diagramEdge]bool{},
+	}
+	previous, previousDirection := "start", ""
+	for i, step := range trace.Steps {
+		id, exists := byName[step.Action]
+		if !exists {
+			// The step is not from this pipeline, so the path cannot be followed from here
+			previous = ""
+			continue
+		}
+		if previous != "" {
+			overlay.executed[diagramEdge{from: previous, direction: previousDirection, to: id}] = true
+		}
+		overlay.visits[id] = append(overlay.visits[id], i+1)
+		if step.Err != nil {
+			overlay.failed[id] = true
+		}
+		previous, previousDirection = id, step.Direction
+	}
+	if previous != "" && previous != "start" {
+		lastEdge := diagramEdge{from: previous, direction: previousDirection, to: "terminate"}
+		for _, edge := range edges {
+			if edge == lastEdge {
+				overlay.executed[lastEdge] = true
+				overlay.terminated = true
+			}
+		}
+	}
+
+	return overlay
+}
+
+// label appends the visit numbers to the name of visited actions, such as `CheckNext (1, 3)`.
+func (o runOverlay) label(id, name string) string {
+	visits := o.visits[id]
+	if len(visits) == 0 {
+		return name
+	}
+	numbers := make([]string, len(visits))
+	for i, visit := range visits {
+		numbers[i] = strconv.Itoa(visit)
+	}
+	return name + " (" + strings.Join(numbers, ", ") + ")"
+}
+
+func writeMermaidClass(sb *strings.Builder, class string, nodes []string) {
+	if len(nodes) > 0 {
+		sb.WriteString("    class " + strings.Join(nodes, ",") + " " + class + "\n")
+	}
+}
+
+func dotShape[T any](action Action[T]) string {
+	switch action.(type) {
+	case *Pipeline[T]:
+		return "shape=box, peripheries=2"
+	case BranchAction[T]:
+		return "shape=hexagon"
+	default:
+		return "shape=box"
+	}
+}
+
+func dotQuote(text string) string {
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(text) + `"`
+}
@@ -0,0 +1,411 @@
+package chain
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func TestPipeline_RunIf(t *testing.T) {
+	ctx := context.Background()
+	isPositive := func(input int) bool { return input > 0 }
+
+	t.Run("runs when condition holds", func(t *testing.T) {
+		collatz := NewCollatz("Collatz")
+
+		output, direction, err := collatz.RunIf(ctx, 5, isPositive)
+
+		assert.NoError(t, err)
+		assert.Equal(t, Success, direction)
+		assert.Equal(t, 16, output)
+	})
+
+	t.Run("passes through when condition fails", func(t *testing.T) {
+		pipeline := NewPipeline("Pipeline", &ErrorMaker{message: "never runs"})
+
+		output, direction, err := pipeline.RunIf(ctx, -5, isPositive)
+
+		assert.NoError(t, err)
+		assert.Equal(t, Success, direction)
+		assert.Equal(t, -5, output)
+	})
+
+	t.Run("reports direction of the run", func(t *testing.T) {
+		pipeline := NewPipeline("Pipeline", &ErrorMaker{message: "error1"}, &SetTen{})
+
+		output, direction, err := pipeline.RunIf(ctx, 5, isPositive)
+
+		assert.EqualError(t, err, "error1")
+		assert.Equal(t, Error, direction)
+		assert.Equal(t, 5, output)
+	})
+
+	t.Run("aborts on panicking condition", func(t *testing.T) {
+		pipeline := NewPipeline("Pipeline", &SetTen{})
+
+		output, direction, err := pipeline.RunIf(ctx, 5, func(int) bool { panic("broken condition") })
+
+		assert.EqualError(t, err, "broken condition")
+		assert.Equal(t, Abort, direction)
+		assert.Equal(t, 5, output)
+	})
+}
+
+func TestPipeline_RunInBackground(t *testing.T) {
+	t.Run("delivers result", func(t *testing.T) {
+		collatz := NewCollatz("Collatz")
+
+		result := <-collatz.RunInBackground(context.Background(), 5)
+
+		assert.NoError(t, result.Err)
+		assert.Equal(t, Success, result.Direction)
+		assert.Equal(t, 16, result.Output)
+	})
+
+	t.Run("does not block without receiver", func(t *testing.T) {
+		collatz := NewCollatz("Collatz")
+
+		resultCh := collatz.RunInBackground(context.Background(), 5)
+		time.Sleep(10 * time.Millisecond)
+
+		assert.Len(t, resultCh, 1)
+	})
+
+	t.Run("aborts on cancellation", func(t *testing.T) {
+		started, release := make(chan struct{}), make(chan struct{})
+		blocking := NewSimpleAction("Blocking", func(_ context.Context, input int) (int, error) {
+			close(started)
+			<-release
+			return input + 1, nil
+		})
+		pipeline := NewPipeline("Pipeline", blocking, &SetTen{})
+		ctx, cancel := context.WithCancel(context.Background())
+
+		resultCh := pipeline.RunInBackground(ctx, 1)
+		<-started
+		cancel()
+		close(release)
+
+		select {
+		case result := <-resultCh:
+			assert.ErrorIs(t, result.Err, context.Canceled)
+			assert.Equal(t, Abort, result.Direction)
+			assert.Equal(t, 2, result.Output)
+		case <-time.After(time.Second):
+			t.Fatal("result was not delivered")
+		}
+	})
+}
+
+func TestPipeline_RunWithDeadline(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("completes before deadline", func(t *testing.T) {
+		collatz := NewCollatz("Collatz")
+
+		output, direction, err := collatz.RunWithDeadline(ctx, 5, time.Now().Add(time.Minute))
+
+		assert.NoError(t, err)
+		assert.Equal(t, Success, direction)
+		assert.Equal(t, 16, output)
+	})
+
+	t.Run("aborts after deadline", func(t *testing.T) {
+		collatz := NewCollatz("Collatz")
+
+		output, direction, err := collatz.RunWithDeadline(ctx, 5, time.Now().Add(-time.Second))
+
+		assert.ErrorIs(t, err, context.DeadlineExceeded)
+		assert.Equal(t, Abort, direction)
+		assert.Equal(t, 5, output)
+	})
+}
+
+func TestPipeline_RunSafe(t *testing.T) {
+	ctx := context.Background()
+	newPipeline := func(panicValue any) *Pipeline[int] {
+		panicking := NewSimpleAction("Panicking", func(_ context.Context, input int) (int, error) { panic(panicValue) })
+		return NewPipeline("Pipeline", &SetTen{}, panicking)
+	}
+
+	t.Run("recovers as usual without handler", func(t *testing.T) {
+		pipeline := newPipeline("broken")
+
+		output, direction, err := pipeline.RunSafe(ctx, 1)
+
+		assert.EqualError(t, err, "broken")
+		assert.Equal(t, Abort, direction)
+		assert.Equal(t, 10, output)
+	})
+
+	t.Run("returns results of handler", func(t *testing.T) {
+		errBroken := errors.New("broken")
+		handler := WithPanicHandler(func(recovered any) (int, string, error) {
+			if err, isError := recovered.(error); isError {
+				return -1, Error, fmt.Errorf("recovered: %w", err)
+			}
+			return -2, Abort, fmt.Errorf("recovered: %v", recovered)
+		})
+
+		output, direction, err := newPipeline(errBroken).RunSafe(ctx, 1, handler)
+
+		assert.ErrorIs(t, err, errBroken)
+		assert.Equal(t, Error, direction)
+		assert.Equal(t, -1, output)
+
+		output, direction, err = newPipeline(42).RunSafe(ctx, 1, handler)
+
+		assert.EqualError(t, err, "recovered: 42")
+		assert.Equal(t, Abort, direction)
+		assert.Equal(t, -2, output)
+	})
+
+	t.Run("keeps results of handler as they are", func(t *testing.T) {
+		handler := WithPanicHandler(func(any) (int, string, error) {
+			return 3, Success, errors.New("partially done")
+		})
+
+		output, direction, err := newPipeline("broken").RunSafe(ctx, 1, handler)
+
+		assert.EqualError(t, err, "partially done")
+		assert.Equal(t, Success, direction)
+		assert.Equal(t, 3, output)
+	})
+
+	t.Run("raises original panic when handler panics", func(t *testing.T) {
+		handler := WithPanicHandler(func(any) (int, string, error) { panic("handler broken") })
+
+		assert.PanicsWithValue(t, "broken", func() {
+			_, _, _ = newPipeline("broken").RunSafe(ctx, 1, handler)
+		})
+	})
+}
+
+func TestPipeline_RunToAction(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("stops before target", func(t *testing.T) {
+		collatz := NewCollatz("Collatz")
+
+		output, direction, err := collatz.RunToAction(collatz.OnOdd, ctx, 5)
+
+		assert.NoError(t, err)
+		assert.Equal(t, "odd", direction)
+		assert.Equal(t, 5, output)
+	})
+
+	t.Run("passes through input before first action", func(t *testing.T) {
+		collatz := NewCollatz("Collatz")
+
+		output, direction, err := collatz.RunToAction(collatz.CheckNext, ctx, 5)
+
+		assert.NoError(t, err)
+		assert.Equal(t, Success, direction)
+		assert.Equal(t, 5, output)
+	})
+
+	t.Run("reports errors before target", func(t *testing.T) {
+		setTen := &SetTen{}
+		pipeline := NewPipeline("Pipeline", &ErrorMaker{message: "error1"}, setTen)
+		pipeline.SetRunPlan(pipeline.members[0], ActionPlan[int]{Error: setTen})
+
+		output, direction, err := pipeline.RunToAction(setTen, ctx, 5)
+
+		assert.EqualError(t, err, "error1")
+		assert.Equal(t, Error, direction)
+		assert.Equal(t, 5, output)
+	})
+
+	t.Run("terminates once", func(t *testing.T) {
+		collatz := NewCollatz("Collatz")
+		var terminated []string
+		collatz.OnTerminate(func(_ context.Context, output int, direction string, _ error) {
+			terminated = append(terminated, fmt.Sprintf("%s:%d", direction, output))
+		})
+
+		_, _, err := collatz.RunToAction(collatz.OnOdd, ctx, 5)
+
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"odd:5"}, terminated)
+		assert.Equal(t, map[string]int64{"odd": 1}, collatz.Stats().Runs())
+	})
+
+	t.Run("fails when target is not reached", func(t *testing.T) {
+		collatz := NewCollatz("Collatz")
+
+		output, direction, err := collatz.RunToAction(collatz.OnEven, ctx, 5)
+
+		assert.EqualError(t, err, "run ended without reaching `OnEven`")
+		assert.Equal(t, Success, direction)
+		assert.Equal(t, 16, output)
+	})
+
+	t.Run("fails with non-member", func(t *testing.T) {
+		collatz := NewCollatz("Collatz")
+
+		_, _, err := collatz.RunToAction(&SetTen{}, ctx, 5)
+
+		assert.EqualError(t, err, "`SetTen` is not a member of this pipeline")
+		_, _, err = collatz.RunToAction(Terminate[int](), ctx, 5)
+		assert.EqualError(t, err, "cannot run to terminate")
+	})
+}
+
+func TestPipeline_RunWithInputFunc(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("runs with made input", func(t *testing.T) {
+		collatz := NewCollatz("Collatz")
+
+		output, direction, err := collatz.RunWithInputFunc(ctx, func() (int, error) { return 5, nil })
+
+		assert.NoError(t, err)
+		assert.Equal(t, Success, direction)
+		assert.Equal(t, 16, output)
+	})
+
+	t.Run("reports error of input", func(t *testing.T) {
+		errUnavailable := errors.New("database unavailable")
+		pipeline := NewPipeline("Pipeline", &ErrorMaker{message: "never runs"})
+
+		_, direction, err := pipeline.RunWithInputFunc(ctx, func() (int, error) { return 0, errUnavailable })
+
+		assert.ErrorIs(t, err, errUnavailable)
+		assert.Equal(t, Error, direction)
+	})
+
+	t.Run("aborts on panicking input", func(t *testing.T) {
+		pipeline := NewPipeline("Pipeline", &SetTen{})
+
+		_, direction, err := pipeline.RunWithInputFunc(ctx, func() (int, error) { panic("broken input") })
+
+		assert.EqualError(t, err, "broken input")
+		assert.Equal(t, Abort, direction)
+	})
+
+	t.Run("does not make input for cancelled run", func(t *testing.T) {
+		pipeline := NewPipeline("Pipeline", &SetTen{})
+		canceled, cancel := context.WithCancel(ctx)
+		cancel()
+
+		_, direction, err := pipeline.RunWithInputFunc(canceled, func() (int, error) {
+			t.Fatal("input must not be made")
+			return 0, nil
+		})
+
+		assert.ErrorIs(t, err, context.Canceled)
+		assert.Equal(t, Abort, direction)
+	})
+}
+
+func TestPipeline_TestRun(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("follows plans with stubbed results", func(t *testing.T) {
+		collatz := NewCollatz("Collatz")
+
+		output, direction, err := collatz.TestRun(ctx, 5, map[string]ActionStub[int]{
+			"CheckNext": {Output: 6, Direction: "even"},
+		})
+
+		assert.NoError(t, err)
+		assert.Equal(t, Success, direction)
+		assert.Equal(t, 3, output)
+	})
+
+	t.Run("directs by error of stub", func(t *testing.T) {
+		errUnavailable := errors.New("unavailable")
+		setTen := &SetTen{}
+		pipeline := NewPipeline("Pipeline", &DirectingAction{name: "Remote"}, setTen)
+		pipeline.SetRunPlan(pipeline.members[0], ActionPlan[int]{Success: Terminate[int](), Error: setTen})
+
+		output, direction, err := pipeline.TestRun(ctx, 1, map[string]ActionStub[int]{
+			"Remote": {Output: 2, Err: errUnavailable},
+		})
+
+		assert.ErrorIs(t, err, errUnavailable)
+		assert.Equal(t, Error, direction)
+		assert.Equal(t, 10, output)
+	})
+
+	t.Run("runs actions without stubs", func(t *testing.T) {
+		collatz := NewCollatz("Collatz")
+
+		output, _, err := collatz.TestRun(ctx, 5, nil)
+
+		assert.NoError(t, err)
+		assert.Equal(t, 16, output)
+	})
+}
+
+func TestPipeline_RunChain(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("threads outputs through runs", func(t *testing.T) {
+		collatz := NewCollatz("Collatz")
+
+		output, direction, err := collatz.RunChain(ctx, 5, 3)
+
+		assert.NoError(t, err)
+		assert.Equal(t, Success, direction)
+		assert.Equal(t, 4, output)
+		assert.Equal(t, map[string]int64{Success: 3}, collatz.Stats().Runs())
+	})
+
+	t.Run("stops on error", func(t *testing.T) {
+		runs := 0
+		increment := NewSimpleAction("Increment", func(_ context.Context, input int) (int, error) {
+			runs++
+			if input == 3 {
+				return input, errors.New("reached three")
+			}
+			return input + 1, nil
+		})
+		pipeline := NewPipeline[int]("Pipeline", increment)
+
+		output, direction, err := pipeline.RunChain(ctx, 1, 5)
+
+		assert.ErrorContains(t, err, "reached three")
+		assert.Equal(t, Error, direction)
+		assert.Equal(t, 3, output)
+		assert.Equal(t, 3, runs)
+	})
+
+	t.Run("falls back on each run", func(t *testing.T) {
+		failing := NewPipeline[int]("Failing", ErrorMaker{"broken"})
+		failing.WithFallback(NewCollatz("Collatz").Pipeline)
+
+		output, direction, err := failing.RunChain(ctx, 5, 3)
+
+		assert.NoError(t, err)
+		assert.Equal(t, Success, direction)
+		assert.Equal(t, 4, output)
+	})
+
+	t.Run("stops on abort", func(t *testing.T) {
+		canceled, cancel := context.WithCancel(ctx)
+		cancel()
+		collatz := NewCollatz("Collatz")
+
+		output, direction, err := collatz.RunChain(canceled, 5, 3)
+
+		assert.ErrorIs(t, err, context.Canceled)
+		assert.Equal(t, Abort, direction)
+		assert.Equal(t, 5, output)
+		assert.Equal(t, map[string]int64{Abort: 1}, collatz.Stats().Runs())
+	})
+
+	t.Run("no runs", func(t *testing.T) {
+		collatz := NewCollatz("Collatz")
+
+		output, direction, err := collatz.RunChain(ctx, 5, 0)
+
+		assert.EqualError(t, err, "chain must run at least once, but 0 runs were given")
+		assert.Equal(t, Error, direction)
+		assert.Equal(t, 5, output)
+		assert.Empty(t, collatz.Stats().Runs())
+	})
+}
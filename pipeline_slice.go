@@ -0,0 +1,86 @@
+package chain
+
+import (
+	"errors"
+	"fmt"
+)
+
+// Slice extracts a new pipeline named name, containing only the members on the paths from the
+// action `from` to the action `to`, which is useful for testing a part of a pipeline or building
+// smaller tools from it. The new pipeline starts with `from`, followed by the rest of the members
+// in their original order. Plans between the members of the slice are preserved, including the
+// custom directions of BranchActions, while edges leaving the slice are rewritten to terminate.
+//
+// The slice shares the action instances with the original pipeline, so the state of the actions
+// is shared as well. Plans are copied, so changing the plans of either pipeline afterward
+// does not affect the other.
+//
+// An error is returned when `from` or `to` is not a member, or `to` is unreachable from `from`.
+func (p *Pipeline[T]) Slice(from, to Action[T], name string) (*Pipeline[T], error) {
+	if name == "" {
+		return nil, errors.New("pipeline must have a name")
+	}
+	for _, action := range []Action[T]{from, to} {
+		if action == nil {
+			return nil, errors.New("cannot slice from or to terminate")
+		} else if !isMemberActionInPipeline(action, p) {
+			return nil, fmt.Errorf("`%s` is not a member of this pipeline", action.Name())
+		}
+	}
+
+	reached, _ := p.walkFrom(from)
+	if !reached[to] {
+		return nil, fmt.Errorf("`%s` is unreachable from `%s`", to.Name(), from.Name())
+	}
+
+	// Narrow down the reached actions to the ones which can continue to `to`
+	plans := make(map[Action[T]]ActionPlan[T], len(reached))
+	for action := range reached {
+		plans[action] = p.planOf(action)
+	}
+	inSlice := map[Action[T]]bool{to: true}
+	for changed := true; changed; {
+		changed = false
+		for action, plan := range plans {
+			if inSlice[action] {
+				continue
+			}
+			for _, nextAction := range plan {
+				if inSlice[nextAction] {
+					inSlice[action] = true
+					changed = true
+					break
+				}
+			}
+		}
+	}
+
+	members := []Action[T]{from}
+	for _, action := range p.members {
+		if inSlice[action] && action != from {
+			members = append(members, action)
+		}
+	}
+
+	// The slice routes the aliased directions as the pipeline does, as the plans carry their targets
+	slice := NewPipeline(name, members...)
+	slice.directionAliases = p.aliases()
+	terminate := Terminate[T]()
+	slicedPlans := make(map[Action[T]]ActionPlan[T], len(members))
+	for _, action := range members {
+		plan := ActionPlan[T]{}
+		for direction, nextAction := range plans[action] {
+			if inSlice[nextAction] {
+				plan[direction] = nextAction
+			} else {
+				plan[direction] = terminate
+			}
+		}
+		slicedPlans[action] = plan
+	}
+	if err := slice.swapPlans(slicedPlans); err != nil {
+		return nil, err
+	}
+
+	return slice, nil
+}
@@ -0,0 +1,65 @@
+package chain
+
+import (
+	"context"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestPipeline_Slice(t *testing.T) {
+	load := &DirectingAction{name: "Load"}
+	route := &RoutingAction{name: "Route", directions: []string{"express", "standard"}}
+	express := &DirectingAction{name: "Express"}
+	standard := &DirectingAction{name: "Standard"}
+	notify := &DirectingAction{name: "Notify"}
+	archive := &DirectingAction{name: "Archive"}
+	pipeline := NewPipeline("Shipping", load, route, express, standard, notify, archive)
+	pipeline.SetRunPlan(route, ActionPlan[int]{"express": express, "standard": standard, Error: archive})
+	pipeline.SetRunPlan(express, SuccessOnlyPlan(notify))
+	pipeline.SetRunPlan(standard, SuccessOnlyPlan(notify))
+
+	t.Run("members between actions", func(t *testing.T) {
+		slice, err := pipeline.Slice(route, notify, "Delivery")
+
+		assert.NoError(t, err)
+		assert.Equal(t, "Delivery", slice.Name())
+		assert.Equal(t, []string{"Route", "Express", "Standard", "Notify"}, slice.Graph().Nodes())
+		assert.Equal(t, []Edge{
+			{FromName: "Route", Direction: Success, Terminates: true},
+			{FromName: "Route", Direction: Error, Terminates: true},
+			{FromName: "Route", Direction: Abort, Terminates: true},
+			{FromName: "Route", Direction: "express", ToName: "Express"},
+			{FromName: "Route", Direction: "standard", ToName: "Standard"},
+		}, slice.Graph().Successors("Route"))
+		assert.True(t, slice.Graph().Successors("Notify")[0].Terminates)
+
+		result := slice.RunWithTrace(context.Background(), 1)
+		assert.NoError(t, result.Err)
+		assert.Equal(t, []StepOutcome{
+			{Action: "Route", Direction: "express"},
+			{Action: "Express", Direction: Success},
+			{Action: "Notify", Direction: Success},
+		}, result.Trace.Steps)
+	})
+
+	t.Run("plans are not shared", func(t *testing.T) {
+		slice, err := pipeline.Slice(express, notify, "Express")
+		assert.NoError(t, err)
+
+		slice.SetRunPlan(express, TerminationPlan[int]())
+
+		successors, _ := pipeline.Successors("Express")
+		assert.Equal(t, "Notify", successors[0].ToName)
+	})
+
+	t.Run("invalid slices", func(t *testing.T) {
+		_, err := pipeline.Slice(express, standard, "Slice")
+		assert.EqualError(t, err, "`Standard` is unreachable from `Express`")
+
+		_, err = pipeline.Slice(load, &DirectingAction{name: "non-member"}, "Slice")
+		assert.EqualError(t, err, "`non-member` is not a member of this pipeline")
+
+		_, err = pipeline.Slice(load, notify, "")
+		assert.EqualError(t, err, "pipeline must have a name")
+	})
+}
@@ -0,0 +1,179 @@
+package chain
+
+import (
+	"errors"
+	"fmt"
+)
+
+// PipelineSnapshot is a copy of the plans of a pipeline taken by Snapshot,
+// referring to the actions by their names.
+// Plans maps the names of actions to their plans, which map directions to the names of
+// the next actions. An empty name of the next action means termination.
+//
+// Aliases are the aliases of directions added by AliasDirection, whose targets are planned in Plans.
+type PipelineSnapshot struct {
+	Pipeline string
+	Plans    map[string]map[string]string
+	Aliases  map[string]string
+}
+
+// Snapshot copies the current plans of the pipeline, to be restored by RestoreFromSnapshot
+// after risky changes such as Divert or SetRunPlan on a running pipeline.
+// It is safe to call while the pipeline is running.
+func (p *Pipeline[T]) Snapshot() PipelineSnapshot {
+	snapshot := PipelineSnapshot{
+		Pipeline: p.Name(),
+		Plans:    make(map[string]map[string]string, len(p.members)),
+	}
+	if aliases := p.aliases(); len(aliases) > 0 {
+		snapshot.Aliases = make(map[string]string, len(aliases))
+		for from, to := range aliases {
+			snapshot.Aliases[from] = to
+		}
+	}
+
+	terminate := Terminate[T]()
+	for _, action := range p.members {
+		plan := p.planOf(action)
+		namedPlan := make(map[string]string, len(plan))
+		for direction, nextAction := range plan {
+			if nextAction == terminate {
+				namedPlan[direction] = ""
+			} else {
+				namedPlan[direction] = nextAction.Name()
+			}
+		}
+		snapshot.Plans[action.Name()] = namedPlan
+	}
+
+	return snapshot
+}
+
+// RestoreFromSnapshot applies the plans of the snapshot to the pipeline as SetRunPlan does,
+// looking up the actions by their names with ActionByName.
+// Actions not described in the snapshot keep their current plans, and directions planned only as
+// targets of the aliases of the snapshot are dropped when the pipeline has no such aliases.
+//
+// The plans are replaced at once, so runs never see the snapshot partially restored.
+// An error is returned without changing the pipeline when the snapshot refers to an unknown action,
+// or describes a plan SetRunPlan would reject.
+func RestoreFromSnapshot[T any](p *Pipeline[T], snapshot PipelineSnapshot) error {
+	if p == nil {
+		return errors.New("cannot restore snapshot to nil pipeline")
+	}
+
+	namedPlans := snapshot.Plans
+	if len(snapshot.Aliases) > 0 {
+		namedPlans = make(map[string]map[string]string, len(snapshot.Plans))
+		for name, namedPlan := range snapshot.Plans {
+			if action, exists := p.ActionByName(name); exists {
+				namedPlan = withoutAliasTargets(namedPlan, snapshot.Aliases, p.plannedDirectionsOf(action))
+			}
+			namedPlans[name] = namedPlan
+		}
+	}
+	plans, err := p.resolveNamedPlans(namedPlans)
+	if err != nil {
+		return err
+	}
+	return p.swapPlans(plans)
+}
+
+// CopyPlanFrom applies the plans of the actions of source to the actions of the pipeline with
+// the same names, for keeping the routing of a pipeline while replacing the implementations of
+// its actions. Plans continuing to actions the pipeline has no member named after continue
+// to termination instead, and members without counterparts in source keep their current plans.
+//
+// The plans are replaced at once, as RestoreFromSnapshot does. Directions planned only as targets of
+// the aliases of source are dropped when the pipeline has no such aliases. Directions planned to terminate
+// which the members do not support are dropped, as they are the default. An error is returned
+// without changing the pipeline when source is nil, or a plan directs a direction the member
+// does not support.
+func (p *Pipeline[T]) CopyPlanFrom(source *Pipeline[T]) error {
+	if source == nil {
+		return errors.New("cannot copy plans from nil pipeline")
+	}
+
+	terminate := Terminate[T]()
+	sourceAliases := source.aliases()
+	plans := make(map[Action[T]]ActionPlan[T], len(source.members))
+	for _, sourceAction := range source.members {
+		action, exists := p.ActionByName(sourceAction.Name())
+		if _, copied := plans[action]; !exists || copied {
+			continue
+		}
+
+		sourcePlan := withoutAliasTargets(source.planOf(sourceAction), sourceAliases, p.plannedDirectionsOf(action))
+		plan := make(ActionPlan[T], len(sourcePlan))
+		for direction, sourceNext := range sourcePlan {
+			plan[direction] = terminate
+			if sourceNext == terminate {
+				continue
+			}
+			if nextAction, exists := p.ActionByName(sourceNext.Name()); exists {
+				plan[direction] = nextAction
+			}
+		}
+		plans[action] = p.planFor(action, plan)
+	}
+
+	return p.swapPlans(plans)
+}
+
+// resolveNamedPlans looks up the actions of the plans referring to them by their names,
+// where an empty name of the next action means termination.
+func (p *Pipeline[T]) resolveNamedPlans(namedPlans map[string]map[string]string) (map[Action[T]]ActionPlan[T], error) {
+	terminate := Terminate[T]()
+	plans := make(map[Action[T]]ActionPlan[T], len(namedPlans))
+	for name, namedPlan := range namedPlans {
+		action, exists := p.ActionByName(name)
+		if !exists {
+			return nil, fmt.Errorf("`%s` is not a member of this pipeline", name)
+		}
+
+		availableDirections := p.plannedDirectionsOf(action)
+		plan := make(ActionPlan[T], len(namedPlan))
+		for direction, nextName := range namedPlan {
+			nextAction := terminate
+			if nextName != "" {
+				if nextAction, exists = p.ActionByName(nextName); !exists {
+					return nil, fmt.Errorf("setting plan from `%s` directing `%s` to non-member `%s`", name, direction, nextName)
+				}
+			} else if !contains(availableDirections, direction) {
+				return nil, fmt.Errorf("`%s` does not support direction `%s`", name, direction)
+			}
+			plan[direction] = nextAction
+		}
+		plans[action] = plan
+	}
+
+	return plans, nil
+}
+
+// ActionByName finds the member action with the name.
+// When multiple members have the same name, the one given to the constructor first is returned.
+func (p *Pipeline[T]) ActionByName(name string) (Action[T], bool) {
+	for _, action := range p.members {
+		if action.Name() == name {
+			return action, true
+		}
+	}
+	return nil, false
+}
+
+// ActionExists tells whether any member action has the name, for validating names given
+// by external configuration before the actions are at hand.
+func (p *Pipeline[T]) ActionExists(name string) bool {
+	_, exists := p.ActionByName(name)
+	return exists
+}
+
+// ActionOrder lists the names of the member actions in the order they were given to the constructor,
+// which serializations and displays of the pipeline follow.
+func (p *Pipeline[T]) ActionOrder() []string {
+	names := make([]string, len(p.members))
+	for i, action := range p.members {
+		names[i] = action.Name()
+	}
+	return names
+}
@@ -0,0 +1,113 @@
+package chain
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestPipeline_Snapshot(t *testing.T) {
+	t.Run("restore after changes", func(t *testing.T) {
+		collatz := NewCollatz("Collatz")
+		snapshot := collatz.Snapshot()
+
+		assert.Equal(t, "Collatz", snapshot.Pipeline)
+		assert.Equal(t, map[string]string{
+			Success: "",
+			Error:   "",
+			Abort:   "",
+			"even":  "OnEven",
+			"odd":   "OnOdd",
+		}, snapshot.Plans["CheckNext"])
+
+		assert.NoError(t, collatz.Divert(collatz.CheckNext, "even", collatz.OnOdd))
+		assert.NoError(t, RestoreFromSnapshot(collatz.Pipeline, snapshot))
+
+		assert.Equal(t, snapshot, collatz.Snapshot())
+	})
+
+	t.Run("invalid snapshots", func(t *testing.T) {
+		collatz := NewCollatz("Collatz")
+		diverted := collatz.Snapshot()
+		diverted.Plans["CheckNext"]["even"] = "OnOdd"
+		diverted.Plans["OnEven"] = map[string]string{"unknown": ""}
+
+		err := RestoreFromSnapshot(collatz.Pipeline, diverted)
+
+		assert.EqualError(t, err, "`OnEven` does not support direction `unknown`")
+		successors, _ := collatz.Successors("CheckNext")
+		assert.Equal(t, "OnEven", successors[3].ToName, "pipeline must not change on errors")
+
+		err = RestoreFromSnapshot(collatz.Pipeline, PipelineSnapshot{Plans: map[string]map[string]string{"Unknown": {}}})
+		assert.EqualError(t, err, "`Unknown` is not a member of this pipeline")
+
+		err = RestoreFromSnapshot(collatz.Pipeline, PipelineSnapshot{Plans: map[string]map[string]string{"OnOdd": {Success: "Unknown"}}})
+		assert.EqualError(t, err, "setting plan from `OnOdd` directing `success` to non-member `Unknown`")
+	})
+
+	t.Run("action by name", func(t *testing.T) {
+		collatz := NewCollatz("Collatz")
+
+		action, exists := collatz.ActionByName("OnOdd")
+		assert.True(t, exists)
+		assert.Equal(t, collatz.OnOdd, action)
+
+		_, exists = collatz.ActionByName("Unknown")
+		assert.False(t, exists)
+	})
+
+	t.Run("action exists", func(t *testing.T) {
+		collatz := NewCollatz("Collatz")
+
+		assert.True(t, collatz.ActionExists("OnOdd"))
+		assert.False(t, collatz.ActionExists("Unknown"))
+		assert.False(t, collatz.ActionExists("Collatz"))
+	})
+}
+
+func TestPipeline_CopyPlanFrom(t *testing.T) {
+	t.Run("copies plans by names", func(t *testing.T) {
+		source := NewCollatz("Source")
+		source.SetRunPlan(source.OnEven, ActionPlan[int]{Error: source.OnOdd})
+		onOdd, setTen := &DirectingAction{name: "OnOdd"}, &SetTen{}
+		upgraded := NewPipeline("Upgraded", Action[int](&CheckNext{}), onOdd, &DirectingAction{name: "OnEven"}, setTen)
+		upgraded.SetRunPlan(setTen, ActionPlan[int]{Error: onOdd})
+
+		assert.NoError(t, upgraded.CopyPlanFrom(source.Pipeline))
+
+		plans := upgraded.Snapshot().Plans
+		assert.Equal(t, "OnEven", plans["CheckNext"]["even"])
+		assert.Equal(t, "OnOdd", plans["CheckNext"]["odd"])
+		assert.Equal(t, map[string]string{Success: "", Error: "OnOdd", Abort: ""}, plans["OnEven"])
+		assert.Equal(t, "", plans["OnOdd"][Success])
+		assert.Equal(t, "OnOdd", plans["SetTen"][Error], "members without counterparts keep their plans")
+	})
+
+	t.Run("terminates instead of missing actions", func(t *testing.T) {
+		source := NewCollatz("Source")
+		upgraded := NewPipeline("Upgraded", Action[int](&CheckNext{}), &DirectingAction{name: "OnEven"})
+
+		assert.NoError(t, upgraded.CopyPlanFrom(source.Pipeline))
+
+		plans := upgraded.Snapshot().Plans
+		assert.Equal(t, "OnEven", plans["CheckNext"]["even"])
+		assert.Equal(t, "", plans["CheckNext"]["odd"])
+	})
+
+	t.Run("rejects unsupported directions", func(t *testing.T) {
+		source := NewCollatz("Source")
+		upgraded := NewPipeline("Upgraded", &DirectingAction{name: "CheckNext"}, &DirectingAction{name: "OnEven"})
+		before := upgraded.Snapshot()
+
+		assert.EqualError(t, upgraded.CopyPlanFrom(source.Pipeline), "`CheckNext` does not support direction `even`")
+		assert.Equal(t, before, upgraded.Snapshot())
+		assert.EqualError(t, upgraded.CopyPlanFrom(nil), "cannot copy plans from nil pipeline")
+	})
+}
+
+func TestPipeline_ActionOrder(t *testing.T) {
+	collatz := NewCollatz("Collatz")
+	assert.NoError(t, collatz.SetInitAction(collatz.OnOdd))
+
+	assert.Equal(t, []string{"CheckNext", "OnEven", "OnOdd"}, collatz.ActionOrder())
+	assert.Equal(t, []string{"Inner", "SetTen"}, NewPipeline("Outer", collatz.Pipeline.WithName("Inner"), &SetTen{}).ActionOrder())
+}
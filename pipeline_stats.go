@@ -0,0 +1,328 @@
+package chain
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"runtime"
+	"strings"
+	"sync"
+	"sync/atomic"
+)
+
+// statsSchema is the version of the encoding of Stats.Snapshot,
+// to be increased on incompatible changes of statsSnapshot.
+const statsSchema = 1
+
+// Stats accumulates the counts of the runs of a pipeline since it was constructed,
+// by the directions the runs ended with, and by the directions each action directed.
+// It is safe to use while the pipeline is running.
+type Stats struct {
+	mu       sync.Mutex
+	runs     map[string]int64
+	steps    map[string]map[string]int64
+	identity func() (name, fingerprint string)
+
+	// The runs and the steps of the members are counted apart without locking. On reading, the counters are
+	// replaced by new ones, and merged into the maps once the updates in progress on them are done,
+	// so the maps hold every count as of the replacement.
+	counters    atomic.Pointer[statsCounters]
+	memberNames func() []string
+}
+
+// statsCounters counts the runs by the directions they ended with, and the steps by the indexes of the members.
+type statsCounters struct {
+	ended   directionCounts
+	members []stepCounts
+	// writers counts the updates in progress, which are waited for before merging the counts
+	writers atomic.Int64
+}
+
+func newStats(identity func() (name, fingerprint string), memberNames func() []string) *Stats {
+	return &Stats{
+		runs:        map[string]int64{},
+		steps:       map[string]map[string]int64{},
+		identity:    identity,
+		memberNames: memberNames,
+	}
+}
+
+// commonDirections are the directions counted by directionCounts.
+var commonDirections = [...]string{Success, Error, Abort}
+
+type directionCounts [len(commonDirections)]atomic.Int64
+
+type stepCounts struct {
+	action string
+	counts directionCounts
+	// others counts the other directions, replaced by a copy with the direction on its first count
+	others atomic.Pointer[map[string]*atomic.Int64]
+}
+
+// add counts the direction, reporting false when it is not one of commonDirections.
+func (c *directionCounts) add(direction string) bool {
+	for i, common := range commonDirections {
+		if direction == common {
+			c[i].Add(1)
+			return true
+		}
+	}
+	return false
+}
+
+// addTo adds the counts to counts, reporting whether any was counted.
+func (c *directionCounts) addTo(counts map[string]int64) bool {
+	counted := false
+	for i := range c {
+		if count := c[i].Load(); count > 0 {
+			counts[commonDirections[i]] += count
+			counted = true
+		}
+	}
+	return counted
+}
+
+// Stats returns the statistics accumulated by the runs of the pipeline.
+func (p *Pipeline[T]) Stats() *Stats {
+	return p.stats
+}
+
+// fingerprint identifies the structure of the pipeline, by the names of the members and the
+// directions they support. Plans are not included, as they can be changed while running.
+func (p *Pipeline[T]) fingerprint() string {
+	hash := sha256.New()
+	for _, action := range p.members {
+		fmt.Fprintf(hash, "%s:%s\n", action.Name(), strings.Join(directionsOf(action), ","))
+	}
+	return hex.EncodeToString(hash.Sum(nil))
+}
+
+// memberNames returns the names of the members in the order given to the constructor.
+func (p *Pipeline[T]) memberNames() []string {
+	names := make([]string, len(p.members))
+	for i, action := range p.members {
+		names[i] = action.Name()
+	}
+	return names
+}
+
+// Runs returns the number of runs by the directions they ended with.
+func (s *Stats) Runs() map[string]int64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.mergeLocked()
+	return copyCounts(s.runs)
+}
+
+// Steps returns the number of times each action directed each direction, keyed by the names of actions.
+func (s *Stats) Steps() map[string]map[string]int64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.mergeLocked()
+	return s.copyStepsLocked()
+}
+
+func (s *Stats) copyStepsLocked() map[string]map[string]int64 {
+	steps := make(map[string]map[string]int64, len(s.steps))
+	for action, counts := range s.steps {
+		steps[action] = copyCounts(counts)
+	}
+	return steps
+}
+
+// mergeLocked replaces the counters by new ones, and merges the replaced ones into the maps
+// once the updates in progress on them are done. s.mu should be held, which keeps the counters in place.
+func (s *Stats) mergeLocked() {
+	merged := s.counters.Swap(s.newCounters())
+	if merged == nil {
+		return
+	}
+	for merged.writers.Load() > 0 {
+		runtime.Gosched()
+	}
+
+	merged.ended.addTo(s.runs)
+	for i := range merged.members {
+		member := &merged.members[i]
+		counts := s.steps[member.action]
+		if counts == nil {
+			counts = map[string]int64{}
+		}
+		counted := member.counts.addTo(counts)
+		if others := member.others.Load(); others != nil {
+			for direction, count := range *others {
+				counts[direction] += count.Load()
+				counted = true
+			}
+		}
+		if counted {
+			s.steps[member.action] = counts
+		}
+	}
+}
+
+func (s *Stats) newCounters() *statsCounters {
+	names := s.memberNames()
+	counters := &statsCounters{members: make([]stepCounts, len(names))}
+	for i, name := range names {
+		counters.members[i].action = name
+	}
+	return counters
+}
+
+// acquireCounters returns the current counters for an update, which must be released when done.
+func (s *Stats) acquireCounters() *statsCounters {
+	for {
+		counters := s.counters.Load()
+		if counters == nil {
+			s.counters.CompareAndSwap(nil, s.newCounters())
+			continue
+		}
+		// Replaced counters are being merged, which must not miss the update
+		if counters.writers.Add(1); s.counters.Load() == counters {
+			return counters
+		}
+		counters.writers.Add(-1)
+	}
+}
+
+func (s *Stats) recordStep(action, direction string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.steps[action] == nil {
+		s.steps[action] = map[string]int64{}
+	}
+	s.steps[action][direction]++
+}
+
+// recordMemberStep counts the step of the member at index.
+// Only the first count of each direction other than commonDirections on the counters takes the lock.
+func (s *Stats) recordMemberStep(index int, direction string) {
+	counters := s.acquireCounters()
+	member := &counters.members[index]
+	if member.counts.add(direction) {
+		counters.writers.Add(-1)
+		return
+	} else if others := member.others.Load(); others != nil {
+		if count, exists := (*others)[direction]; exists {
+			count.Add(1)
+			counters.writers.Add(-1)
+			return
+		}
+	}
+	counters.writers.Add(-1)
+
+	// Counters are only replaced while holding the lock, so they stay in place until unlocked
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	member = &s.counters.Load().members[index]
+	others := map[string]*atomic.Int64{}
+	if current := member.others.Load(); current != nil {
+		if count, exists := (*current)[direction]; exists {
+			count.Add(1)
+			return
+		}
+		for other, count := range *current {
+			others[other] = count
+		}
+	}
+	count := new(atomic.Int64)
+	count.Add(1)
+	others[direction] = count
+	member.others.Store(&others)
+}
+
+func (s *Stats) recordRun(direction string) {
+	counters := s.acquireCounters()
+	counted := counters.ended.add(direction)
+	counters.writers.Add(-1)
+	if counted {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.runs[direction]++
+}
+
+type statsSnapshot struct {
+	Schema      int                         `json:"schema"`
+	Pipeline    string                      `json:"pipeline"`
+	Fingerprint string                      `json:"fingerprint"`
+	Runs        map[string]int64            `json:"runs"`
+	Steps       map[string]map[string]int64 `json:"steps"`
+}
+
+// Snapshot encodes the counts as of now in JSON, to be restored by Restore after a restart.
+// The counts are copied at once, so runs in progress never make the snapshot inconsistent.
+// The snapshot identifies the pipeline by its name and a fingerprint of its members.
+func (s *Stats) Snapshot() ([]byte, error) {
+	name, fingerprint := s.identity()
+
+	s.mu.Lock()
+	s.mergeLocked()
+	snapshot := statsSnapshot{
+		Schema:      statsSchema,
+		Pipeline:    name,
+		Fingerprint: fingerprint,
+		Runs:        copyCounts(s.runs),
+		Steps:       s.copyStepsLocked(),
+	}
+	s.mu.Unlock()
+
+	return json.Marshal(snapshot)
+}
+
+// Restore adds the counts of the snapshot taken by Snapshot to the counts accumulated so far.
+// An error is returned when the snapshot is from a pipeline with a different name or members,
+// which ForceRestore allows.
+func (s *Stats) Restore(data []byte) error {
+	return s.restore(data, false)
+}
+
+// ForceRestore adds the counts of the snapshot as Restore does,
+// even when the snapshot is from a pipeline with a different name or members.
+func (s *Stats) ForceRestore(data []byte) error {
+	return s.restore(data, true)
+}
+
+func (s *Stats) restore(data []byte, force bool) error {
+	var snapshot statsSnapshot
+	if err := json.Unmarshal(data, &snapshot); err != nil {
+		return fmt.Errorf("invalid stats snapshot: %w", err)
+	}
+	if snapshot.Schema != statsSchema {
+		return fmt.Errorf("unsupported stats snapshot schema %d", snapshot.Schema)
+	}
+	if name, fingerprint := s.identity(); !force {
+		if snapshot.Pipeline != name {
+			return fmt.Errorf("stats snapshot is from pipeline `%s`, not `%s`", snapshot.Pipeline, name)
+		} else if snapshot.Fingerprint != fingerprint {
+			return fmt.Errorf("stats snapshot is from pipeline `%s` with different members", snapshot.Pipeline)
+		}
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for direction, count := range snapshot.Runs {
+		s.runs[direction] += count
+	}
+	for action, counts := range snapshot.Steps {
+		if s.steps[action] == nil {
+			s.steps[action] = map[string]int64{}
+		}
+		for direction, count := range counts {
+			s.steps[action][direction] += count
+		}
+	}
+
+	return nil
+}
+
+func copyCounts(counts map[string]int64) map[string]int64 {
+	copied := make(map[string]int64, len(counts))
+	for key, count := range counts {
+		copied[key] = count
+	}
+	return copied
+}
@@ -0,0 +1,114 @@
+package chain
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/stretchr/testify/assert"
+	"sync"
+	"testing"
+)
+
+func TestPipeline_Stats(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("count runs and steps", func(t *testing.T) {
+		collatz := NewCollatz("Collatz")
+		for _, input := range []int{5, 16, 3} {
+			_, _ = collatz.Run(ctx, input)
+		}
+
+		assert.Equal(t, map[string]int64{Success: 3}, collatz.Stats().Runs())
+		assert.Equal(t, map[string]map[string]int64{
+			"CheckNext": {"odd": 2, "even": 1},
+			"OnEven":    {Success: 1},
+			"OnOdd":     {Success: 2},
+		}, collatz.Stats().Steps())
+	})
+
+	t.Run("restore merges counts", func(t *testing.T) {
+		previous := NewCollatz("Collatz")
+		_, _ = previous.Run(ctx, 5)
+		snapshot, err := previous.Stats().Snapshot()
+		assert.NoError(t, err)
+
+		collatz := NewCollatz("Collatz")
+		_, _ = collatz.Run(ctx, 16)
+		assert.NoError(t, collatz.Stats().Restore(snapshot))
+
+		assert.Equal(t, map[string]int64{Success: 2}, collatz.Stats().Runs())
+		assert.Equal(t, map[string]map[string]int64{
+			"CheckNext": {"odd": 1, "even": 1},
+			"OnEven":    {Success: 1},
+			"OnOdd":     {Success: 1},
+		}, collatz.Stats().Steps())
+	})
+
+	t.Run("reject snapshots of other pipelines", func(t *testing.T) {
+		snapshot, err := NewCollatz("Collatz").Stats().Snapshot()
+		assert.NoError(t, err)
+
+		renamed := NewCollatz("Renamed")
+		assert.EqualError(t, renamed.Stats().Restore(snapshot), "stats snapshot is from pipeline `Collatz`, not `Renamed`")
+		assert.NoError(t, renamed.Stats().ForceRestore(snapshot))
+
+		different := NewPipeline[int]("Collatz", &SetTen{})
+		assert.EqualError(t, different.Stats().Restore(snapshot), "stats snapshot is from pipeline `Collatz` with different members")
+
+		var decoded map[string]any
+		assert.NoError(t, json.Unmarshal(snapshot, &decoded))
+		decoded["schema"] = 0
+		unsupported, _ := json.Marshal(decoded)
+		assert.EqualError(t, renamed.Stats().ForceRestore(unsupported), "unsupported stats snapshot schema 0")
+	})
+
+	t.Run("snapshot while running", func(t *testing.T) {
+		collatz := NewCollatz("Collatz")
+		var wg sync.WaitGroup
+		for i := 0; i < 8; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				for input := 1; input < 50; input++ {
+					_, _ = collatz.Run(ctx, input)
+				}
+			}()
+		}
+		for i := 0; i < 10; i++ {
+			snapshot, err := collatz.Stats().Snapshot()
+			assert.NoError(t, err)
+			assert.NoError(t, NewCollatz("Collatz").Stats().Restore(snapshot))
+		}
+		wg.Wait()
+
+		total := int64(0)
+		for _, count := range collatz.Stats().Runs() {
+			total += count
+		}
+		assert.Equal(t, int64(8*49), total)
+	})
+	t.Run("snapshot at a point in time", func(t *testing.T) {
+		first, second := &DirectingAction{name: "First"}, &DirectingAction{name: "Second"}
+		pipeline := NewPipeline("Pipeline", first, second)
+		var wg sync.WaitGroup
+		for i := 0; i < 8; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				for input := 0; input < 200; input++ {
+					_, _ = pipeline.Run(ctx, input)
+				}
+			}()
+		}
+
+		// Every run counts the first step before the second one, and the second one before the run
+		for i := 0; i < 50; i++ {
+			data, err := pipeline.Stats().Snapshot()
+			assert.NoError(t, err)
+			var snapshot statsSnapshot
+			assert.NoError(t, json.Unmarshal(data, &snapshot))
+			assert.GreaterOrEqual(t, snapshot.Steps["First"][Success], snapshot.Steps["Second"][Success])
+			assert.GreaterOrEqual(t, snapshot.Steps["Second"][Success], snapshot.Runs[Success])
+		}
+		wg.Wait()
+	})
+}
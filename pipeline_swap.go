@@ -0,0 +1,46 @@
+package chain
+
+import (
+	"errors"
+	"fmt"
+)
+
+// SwapPlans exchanges the plans of two member actions, so each continues where the other did,
+// for setting up A/B tests and test scenarios. The plans are exchanged at once,
+// so runs never see only one of them swapped.
+//
+// Directions planned to terminate which the new owner does not support are dropped, as they are
+// the default. An error is returned without changing the pipeline when either is not a member,
+// or a plan directs a direction the new owner does not support, or would make it continue to itself.
+func (p *Pipeline[T]) SwapPlans(a, b Action[T]) error {
+	for _, action := range []Action[T]{a, b} {
+		if action == nil {
+			return errors.New("cannot swap plan of terminate")
+		} else if !isMemberActionInPipeline(action, p) {
+			return fmt.Errorf("`%s` is not a member of this pipeline", action.Name())
+		}
+	}
+	if a == b {
+		return nil
+	}
+
+	return p.swapPlans(map[Action[T]]ActionPlan[T]{
+		a: p.planFor(a, p.planOf(b)),
+		b: p.planFor(b, p.planOf(a)),
+	})
+}
+
+// planFor copies the plan for the action, dropping directions planned to terminate
+// which the action does not support.
+func (p *Pipeline[T]) planFor(action Action[T], plan ActionPlan[T]) ActionPlan[T] {
+	terminate := Terminate[T]()
+	availableDirections := p.plannedDirectionsOf(action)
+	copied := make(ActionPlan[T], len(plan))
+	for direction, nextAction := range plan {
+		if nextAction == terminate && !contains(availableDirections, direction) {
+			continue
+		}
+		copied[direction] = nextAction
+	}
+	return copied
+}
@@ -0,0 +1,99 @@
+package chain
+
+import (
+	"context"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestPipeline_SwapPlans(t *testing.T) {
+	t.Run("exchanges plans", func(t *testing.T) {
+		action1 := &DirectingAction{name: "action1"}
+		action2 := &DirectingAction{name: "action2"}
+		action3 := &DirectingAction{name: "action3"}
+		action4 := &DirectingAction{name: "action4"}
+		pipeline := NewPipeline("Pipeline", action1, action2, action3, action4)
+		pipeline.SetRunPlan(action1, SuccessOnlyPlan(action2))
+		pipeline.SetRunPlan(action2, SuccessOnlyPlan(action4))
+		pipeline.SetRunPlan(action3, DefaultPlan(action4, action1))
+
+		assert.NoError(t, pipeline.SwapPlans(action2, action3))
+
+		assert.Equal(t, action4, pipeline.planOf(action2)[Success])
+		assert.Equal(t, action1, pipeline.planOf(action2)[Error])
+		assert.Equal(t, Terminate[int](), pipeline.planOf(action3)[Error])
+		result := pipeline.RunWithTrace(context.Background(), 1)
+		assert.Len(t, result.Trace.Steps, 3)
+		assert.Equal(t, "action4", result.Trace.Steps[2].Action)
+	})
+
+	t.Run("drops unsupported default terminations", func(t *testing.T) {
+		collatz := NewCollatz("Collatz")
+
+		assert.NoError(t, collatz.SwapPlans(collatz.OnEven, collatz.OnOdd))
+
+		assert.Equal(t, []string{Abort, Error, Success}, collatz.ListDirections()["OnEven"])
+	})
+
+	t.Run("rejects incompatible plans", func(t *testing.T) {
+		collatz := NewCollatz("Collatz")
+		planBefore := collatz.planOf(collatz.OnEven)
+
+		err := collatz.SwapPlans(collatz.CheckNext, collatz.OnEven)
+
+		assert.ErrorContains(t, err, "`OnEven` does not support direction")
+		assert.Equal(t, planBefore, collatz.planOf(collatz.OnEven))
+	})
+
+	t.Run("rejects self loops", func(t *testing.T) {
+		action1 := &DirectingAction{name: "action1"}
+		action2 := &DirectingAction{name: "action2"}
+		pipeline := NewPipeline("Pipeline", action1, action2)
+
+		assert.EqualError(t, pipeline.SwapPlans(action1, action2), "setting self loop plan with `action2` directing `success`")
+	})
+
+	t.Run("rejects non-members", func(t *testing.T) {
+		collatz := NewCollatz("Collatz")
+
+		assert.EqualError(t, collatz.SwapPlans(collatz.OnEven, &SetTen{}), "`SetTen` is not a member of this pipeline")
+		assert.EqualError(t, collatz.SwapPlans(nil, collatz.OnEven), "cannot swap plan of terminate")
+	})
+}
+
+func TestPipeline_SetRunPlanBulk(t *testing.T) {
+	t.Run("replaces plans at once", func(t *testing.T) {
+		action1 := &DirectingAction{name: "action1"}
+		action2 := &DirectingAction{name: "action2"}
+		action3 := &DirectingAction{name: "action3"}
+		pipeline := NewPipeline("Pipeline", action1, action2, action3)
+
+		err := pipeline.SetRunPlanBulk(map[Action[int]]ActionPlan[int]{
+			action1: SuccessOnlyPlan(action3),
+			action3: SuccessOnlyPlan(action2),
+			action2: TerminationPlan[int](),
+		})
+
+		assert.NoError(t, err)
+		result := pipeline.RunWithTrace(context.Background(), 1)
+		assert.Len(t, result.Trace.Steps, 3)
+		assert.Equal(t, "action3", result.Trace.Steps[1].Action)
+		assert.Equal(t, "action2", result.Trace.Steps[2].Action)
+		assert.Equal(t, PlanSetRunPlan, pipeline.planSourceOf(action3, Success))
+	})
+
+	t.Run("applies none of invalid plans", func(t *testing.T) {
+		collatz := NewCollatz("Collatz")
+		plansBefore := collatz.Snapshot().Plans
+
+		err := collatz.SetRunPlanBulk(map[Action[int]]ActionPlan[int]{
+			collatz.OnEven: SuccessOnlyPlan(collatz.OnOdd),
+			collatz.OnOdd:  SuccessOnlyPlan(collatz.OnOdd),
+		})
+
+		assert.EqualError(t, err, "setting self loop plan with `OnOdd` directing `success`")
+		assert.Equal(t, plansBefore, collatz.Snapshot().Plans)
+		assert.EqualError(t, collatz.SetRunPlanBulk(map[Action[int]]ActionPlan[int]{&SetTen{}: nil}), "`SetTen` is not a member of this pipeline")
+		assert.EqualError(t, collatz.SetRunPlanBulk(map[Action[int]]ActionPlan[int]{nil: nil}), "cannot set plan for terminate")
+	})
+}
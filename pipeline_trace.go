@@ -0,0 +1,181 @@
+package chain
+
+import (
+	"context"
+	"errors"
+	"math/rand"
+)
+
+// RunResult holds everything a single run of a Pipeline produced:
+// the output, the direction the run ended with, the error, and the trace of the run.
+// When the error wraps an AbortError, AbortCode holds its code.
+// ErrorClass is the class of the error by the classifier of the pipeline, ErrorClassUnknown without errors.
+// LastAction is the name of the last action run, such as the one whose direction had no plan,
+// or empty when no action was run or the trace was not recorded.
+// StepErrors lists the errors of every failed step in order, including those cleared on recovery,
+// among the steps the trace kept.
+type RunResult[T any] struct {
+	Output     T
+	Direction  string
+	Err        error
+	AbortCode  string
+	ErrorClass ErrorClass
+	LastAction string
+	StepErrors []*StepError
+	Trace      RunTrace
+
+	outcomeClassifier *OutcomeClassifier
+	traced            bool
+}
+
+// SampledTrace returns the trace of the run, or nil when the trace was not recorded,
+// such as for the runs RunWithSampling did not sample.
+func (r *RunResult[T]) SampledTrace() *RunTrace {
+	if !r.traced {
+		return nil
+	}
+	return &r.Trace
+}
+
+// RunTrace records the steps taken by a single run of a Pipeline, in the order they were run.
+// Member pipelines are recorded as a single step, as they are a single Action for their parent.
+// Truncated tells the oldest steps were discarded, keeping the last steps as SetTraceCapacity bounds.
+type RunTrace struct {
+	Pipeline  string
+	Steps     []StepOutcome
+	Truncated bool
+}
+
+// StepOutcome records how a member action finished in a run:
+// the direction it selected, the error it returned, and the data it attached with WithBranchData, if any.
+// When the direction was rewritten by AliasDirection, OriginalDirection holds the one the action directed.
+// Class is the class of the error by the classifier of the pipeline, ErrorClassUnknown without errors.
+type StepOutcome struct {
+	Action            string
+	Direction         string
+	OriginalDirection string
+	Err               error
+	Class             ErrorClass
+	BranchData        any
+}
+
+// RunWithTrace runs the pipeline the same way as Run, recording the outcome of each step on the result.
+// When the run falls back, the result is of the fallback, while the steps recorded are of the pipeline.
+func (p *Pipeline[T]) RunWithTrace(ctx context.Context, input T) RunResult[T] {
+	recorder := p.acquireRecorder()
+	defer p.releaseRecorder(recorder)
+
+	result := RunResult[T]{Trace: RunTrace{Pipeline: p.Name()}}
+	result.Output, result.Direction, result.Err = p.runAt(p.entryAction(), ctx, input, &recorder.hooks)
+	result.Output, result.Direction, result.Err = p.fallBack(ctx, input, result.Output, result.Direction, result.Err)
+	result.Trace.Steps, result.Trace.Truncated = recorder.steps(), recorder.trace.Truncated
+	p.describeResult(&result)
+	result.StepErrors = result.Trace.stepErrors()
+	result.traced = true
+	return result
+}
+
+// RunWithSampling runs the pipeline as RunWithTrace does for about sampleRate of the runs,
+// chosen at random, and as Run does for the others without the overhead of tracing,
+// as head-based sampling of distributed tracing does. SampledTrace of the result tells
+// whether the run was sampled. A sampleRate of 1 or more samples every run,
+// and 0 or less samples none.
+func (p *Pipeline[T]) RunWithSampling(ctx context.Context, input T, sampleRate float64) RunResult[T] {
+	if rand.Float64() < sampleRate {
+		return p.RunWithTrace(ctx, input)
+	}
+
+	result := RunResult[T]{Trace: RunTrace{Pipeline: p.Name()}}
+	result.Output, result.Direction, result.Err = p.runFallingBack(ctx, input)
+	p.describeResult(&result)
+	return result
+}
+
+// describeResult fills the fields of the result describing its error and outcome.
+func (p *Pipeline[T]) describeResult(result *RunResult[T]) {
+	result.AbortCode = abortCodeOf(result.Err)
+	result.ErrorClass = classifyError(p.classifier(), result.Err)
+	result.outcomeClassifier = p.currentOutcomeClassifier()
+	if steps := result.Trace.Steps; len(steps) > 0 {
+		result.LastAction = steps[len(steps)-1].Action
+	}
+}
+
+// stepErrors lists the errors of the failed steps.
+func (t RunTrace) stepErrors() []*StepError {
+	var stepErrs []*StepError
+	for _, step := range t.Steps {
+		if step.Err != nil {
+			stepErrs = append(stepErrs, &StepError{Action: step.Action, Err: step.Err})
+		}
+	}
+	return stepErrs
+}
+
+// traceRecorder records the steps of a run into a buffer reused across the runs of RunWithTrace,
+// which are copied out to the result, so the results never share the buffer.
+type traceRecorder[T any] struct {
+	hooks runHooks[T]
+	trace RunTrace
+}
+
+// maxPooledSteps bounds the buffers kept for reuse, so a single long run does not hold memory forever.
+const maxPooledSteps = 1024
+
+func (p *Pipeline[T]) acquireRecorder() *traceRecorder[T] {
+	recorder, _ := p.recorders.Get().(*traceRecorder[T])
+	if recorder == nil {
+		recorder = &traceRecorder[T]{}
+	}
+	recorder.hooks = runHooks[T]{trace: &recorder.trace, traceCapacity: p.tracedSteps()}
+	recorder.trace.Steps, recorder.trace.Truncated = recorder.trace.Steps[:0], false
+	return recorder
+}
+
+// steps copies out the recorded steps from the oldest one, or nil without steps.
+func (r *traceRecorder[T]) steps() []StepOutcome {
+	recorded := r.trace.Steps
+	if len(recorded) == 0 {
+		return nil
+	}
+	// Once wrapped, the oldest step is the next one to be overwritten
+	oldest := r.hooks.traceNext
+	steps := make([]StepOutcome, 0, len(recorded))
+	return append(append(steps, recorded[oldest:]...), recorded[:oldest]...)
+}
+
+func (p *Pipeline[T]) releaseRecorder(recorder *traceRecorder[T]) {
+	if cap(recorder.trace.Steps) > maxPooledSteps {
+		return
+	}
+	// Cleared, so the pooled buffer neither holds the errors and data of the steps,
+	// nor hands them to the next run
+	clear(recorder.trace.Steps[:cap(recorder.trace.Steps)])
+	recorder.trace.Steps, recorder.trace.Truncated = recorder.trace.Steps[:0], false
+	recorder.hooks = runHooks[T]{}
+	p.recorders.Put(recorder)
+}
+
+// SetTraceCapacity bounds the steps traces of the pipeline keep to the last capacity steps,
+// discarding the oldest ones and marking the traces Truncated, and returns the pipeline itself
+// for chaining. It keeps the steps before failures of long runs at a fixed cost,
+// such as for tracing every run along with RunWithSampling. Setting 0 keeps all the steps.
+// The change takes effect from the next run.
+//
+// If capacity is negative, a panic will occur.
+func (p *Pipeline[T]) SetTraceCapacity(capacity int) *Pipeline[T] {
+	if capacity < 0 {
+		panic(errors.New("trace capacity must not be negative"))
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.traceCapacity = capacity
+	return p
+}
+
+func (p *Pipeline[T]) tracedSteps() int {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	return p.traceCapacity
+}
@@ -0,0 +1,314 @@
+package chain
+
+import (
+	"context"
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"strings"
+	"testing"
+)
+
+func TestPipeline_RunWithTrace(t *testing.T) {
+	t.Run("records each step", func(t *testing.T) {
+		collatz := NewCollatz("Collatz")
+
+		result := collatz.RunWithTrace(context.Background(), 5)
+
+		assert.NoError(t, result.Err)
+		assert.Equal(t, 16, result.Output)
+		assert.Equal(t, Success, result.Direction)
+		assert.Equal(t, RunTrace{
+			Pipeline: "Collatz",
+			Steps: []StepOutcome{
+				{Action: "CheckNext", Direction: "odd"},
+				{Action: "OnOdd", Direction: Success},
+			},
+		}, result.Trace)
+		assert.Equal(t, "OnOdd", result.LastAction)
+	})
+
+	t.Run("records action with unplanned direction as last", func(t *testing.T) {
+		stray := &StrayAction{name: "Stray", direction: "lost"}
+		pipeline := NewPipeline("Pipeline", &DirectingAction{name: "action1"}, Action[int](stray), &SetTen{})
+
+		result := pipeline.RunWithTrace(context.Background(), 5)
+
+		assert.Equal(t, Abort, result.Direction)
+		assert.Equal(t, "Stray", result.LastAction)
+		assert.Equal(t, []StepOutcome{
+			{Action: "action1", Direction: Success},
+			{Action: "Stray", Direction: "lost"},
+		}, result.Trace.Steps)
+	})
+
+	t.Run("records no last action without steps", func(t *testing.T) {
+		collatz := NewCollatz("Collatz")
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		result := collatz.RunWithTrace(ctx, 5)
+
+		assert.Empty(t, result.LastAction)
+		assert.Empty(t, result.Trace.Steps)
+	})
+
+	t.Run("records errors", func(t *testing.T) {
+		action1 := NewCollatz("action1")
+		action2 := &ErrorMaker{message: "error2"}
+		action3 := NewCollatz("action3")
+		pipeline := NewPipeline("Pipeline", action1, action2, action3)
+		pipeline.SetRunPlan(action2, DefaultPlan(action3, action3))
+
+		result := pipeline.RunWithTrace(context.Background(), 5)
+
+		assert.EqualError(t, result.Err, "error2")
+		assert.Equal(t, Error, result.Direction)
+		assert.Equal(t, []StepOutcome{
+			{Action: "action1", Direction: Success},
+			{Action: "error2", Direction: Error, Err: errors.New("error2")},
+			{Action: "action3", Direction: Success},
+		}, result.Trace.Steps)
+	})
+}
+
+func TestPipeline_RunWithTrace_ReusesBuffers(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("does not share steps between results", func(t *testing.T) {
+		collatz := NewCollatz("Collatz")
+
+		odd := collatz.RunWithTrace(ctx, 5)
+		even := collatz.RunWithTrace(ctx, 6)
+
+		assert.Equal(t, []StepOutcome{{Action: "CheckNext", Direction: "odd"}, {Action: "OnOdd", Direction: Success}}, odd.Trace.Steps)
+		assert.Equal(t, []StepOutcome{{Action: "CheckNext", Direction: "even"}, {Action: "OnEven", Direction: Success}}, even.Trace.Steps)
+	})
+
+	t.Run("does not leak steps of previous runs", func(t *testing.T) {
+		pipeline := NewPipeline("Pipeline", &ErrorMaker{message: "error1"}, &SetTen{})
+		pipeline.SetRunPlan(pipeline.members[0], ActionPlan[int]{Error: pipeline.members[1]})
+		_ = pipeline.RunWithTrace(ctx, 1)
+
+		canceled, cancel := context.WithCancel(ctx)
+		cancel()
+		result := pipeline.RunWithTrace(canceled, 1)
+
+		assert.Nil(t, result.Trace.Steps)
+		assert.Empty(t, result.StepErrors)
+		assert.Empty(t, result.LastAction)
+	})
+
+	t.Run("clears buffers on release", func(t *testing.T) {
+		pipeline := NewPipeline("Pipeline", &ErrorMaker{message: "error1"})
+		recorder := pipeline.acquireRecorder()
+		recorder.trace.Steps = append(recorder.trace.Steps, StepOutcome{Action: "error1", Err: errors.New("error1")})
+
+		pipeline.releaseRecorder(recorder)
+
+		assert.Empty(t, recorder.trace.Steps)
+		assert.Equal(t, StepOutcome{}, recorder.trace.Steps[:1][0])
+		assert.Nil(t, recorder.hooks.trace)
+	})
+}
+
+func TestPipeline_SetTraceCapacity(t *testing.T) {
+	ctx := context.Background()
+	// Counts up to 4 by passing the input between the actions, taking 8 steps
+	newCounter := func() *Pipeline[int] {
+		increment := NewSimpleAction("Increment", func(_ context.Context, input int) (int, error) { return input + 1, nil })
+		check := NewSimpleBranchAction[int]("Check", nil, []string{"again"}, func(_ context.Context, output int) (string, error) {
+			if output < 4 {
+				return "again", nil
+			}
+			return Success, nil
+		})
+		pipeline := NewPipeline("Counter", increment, check)
+		pipeline.SetRunPlan(check, ActionPlan[int]{"again": increment})
+		return pipeline
+	}
+
+	t.Run("keeps last steps in order", func(t *testing.T) {
+		counter := newCounter().SetTraceCapacity(3)
+
+		result := counter.RunWithTrace(ctx, 0)
+
+		assert.Equal(t, 4, result.Output)
+		assert.True(t, result.Trace.Truncated)
+		assert.Equal(t, []StepOutcome{
+			{Action: "Check", Direction: "again"},
+			{Action: "Increment", Direction: Success},
+			{Action: "Check", Direction: Success},
+		}, result.Trace.Steps)
+		assert.Equal(t, "Check", result.LastAction)
+	})
+
+	t.Run("does not truncate steps within capacity", func(t *testing.T) {
+		counter := newCounter().SetTraceCapacity(8)
+
+		result := counter.RunWithTrace(ctx, 0)
+
+		assert.False(t, result.Trace.Truncated)
+		assert.Len(t, result.Trace.Steps, 8)
+		assert.Equal(t, "Increment", result.Trace.Steps[0].Action)
+	})
+
+	t.Run("resets bounds of reused buffers", func(t *testing.T) {
+		counter := newCounter().SetTraceCapacity(3)
+		_ = counter.RunWithTrace(ctx, 0)
+		counter.SetTraceCapacity(0)
+
+		result := counter.RunWithTrace(ctx, 0)
+
+		assert.False(t, result.Trace.Truncated)
+		assert.Len(t, result.Trace.Steps, 8)
+		assert.Equal(t, "Increment", result.Trace.Steps[0].Action)
+	})
+
+	t.Run("bounds sampled traces", func(t *testing.T) {
+		counter := newCounter().SetTraceCapacity(2)
+
+		result := counter.RunWithSampling(ctx, 0, 1)
+
+		assert.True(t, result.SampledTrace().Truncated)
+		assert.Len(t, result.SampledTrace().Steps, 2)
+	})
+
+	t.Run("panics with negative capacity", func(t *testing.T) {
+		assert.PanicsWithError(t, "trace capacity must not be negative", func() { newCounter().SetTraceCapacity(-1) })
+	})
+}
+
+func TestPipeline_RunWithSampling(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("traces sampled runs", func(t *testing.T) {
+		collatz := NewCollatz("Collatz")
+
+		result := collatz.RunWithSampling(ctx, 5, 1)
+
+		assert.NoError(t, result.Err)
+		assert.Equal(t, 16, result.Output)
+		if assert.NotNil(t, result.SampledTrace()) {
+			assert.Len(t, result.SampledTrace().Steps, 2)
+		}
+		assert.Equal(t, "OnOdd", result.LastAction)
+	})
+
+	t.Run("runs without trace when not sampled", func(t *testing.T) {
+		collatz := NewCollatz("Collatz")
+
+		result := collatz.RunWithSampling(ctx, 5, 0)
+
+		assert.NoError(t, result.Err)
+		assert.Equal(t, Success, result.Direction)
+		assert.Equal(t, 16, result.Output)
+		assert.Nil(t, result.SampledTrace())
+		assert.Empty(t, result.Trace.Steps)
+	})
+
+	t.Run("samples about the rate", func(t *testing.T) {
+		collatz := NewCollatz("Collatz")
+
+		sampled := 0
+		for i := 0; i < 1000; i++ {
+			if result := collatz.RunWithSampling(ctx, 5, 0.3); result.SampledTrace() != nil {
+				sampled++
+			}
+		}
+
+		assert.InDelta(t, 300, sampled, 100)
+	})
+
+	t.Run("results of RunWithTrace are sampled", func(t *testing.T) {
+		collatz := NewCollatz("Collatz")
+
+		result := collatz.RunWithTrace(ctx, 5)
+
+		assert.Same(t, &result.Trace, result.SampledTrace())
+	})
+}
+
+func TestExportRunDiagram(t *testing.T) {
+	t.Run("mermaid overlay of branching", func(t *testing.T) {
+		collatz := NewCollatz("Collatz")
+		result := collatz.RunWithTrace(context.Background(), 5)
+
+		expected := strings.Join([]string{
+			"flowchart LR",
+			"    start((Start))",
+			`    a0{{"CheckNext (1)"}}`,
+			`    a1["OnEven"]`,
+			`    a2["OnOdd (2)"]`,
+			"    terminate(Terminate)",
+			"    start --> a0",
+			"    a0 -->|success| terminate",
+			"    a0 -->|error| terminate",
+			"    a0 -->|abort| terminate",
+			"    a0 -->|even| a1",
+			"    a0 -->|odd| a2",
+			"    a1 -->|success| terminate",
+			"    a1 -->|error| terminate",
+			"    a1 -->|abort| terminate",
+			"    a2 -->|success| terminate",
+			"    a2 -->|error| terminate",
+			"    a2 -->|abort| terminate",
+			"    classDef visited stroke:#2b7a0b,stroke-width:2px",
+			"    classDef failed fill:#ffe6e6,stroke:#cc3333,stroke-width:2px",
+			"    classDef dimmed fill:#f4f4f4,stroke:#bbbbbb,color:#999999",
+			"    class a0,a2 visited",
+			"    class a1 dimmed",
+			"    linkStyle 0,5,9 stroke:#2b7a0b,stroke-width:3px",
+			"    linkStyle 1,2,3,4,6,7,8,10,11 stroke:#cccccc",
+			"",
+		}, "\n")
+
+		assert.Equal(t, expected, ExportRunMermaid(collatz.Pipeline, result.Trace))
+	})
+
+	t.Run("dot overlay of loop with failure", func(t *testing.T) {
+		attempts := 0
+		fetch := NewSimpleAction("Fetch", func(_ context.Context, input int) (int, error) {
+			attempts++
+			if attempts == 1 {
+				return input, errors.New("temporarily unavailable")
+			}
+			return input, nil
+		})
+		backoff := NewSimpleAction("Backoff", func(_ context.Context, input int) (int, error) {
+			return input, nil
+		})
+		pipeline := NewPipeline("Fetching", fetch, backoff)
+		pipeline.SetRunPlan(fetch, DefaultPlan(Terminate[int](), backoff))
+		pipeline.SetRunPlan(backoff, SuccessOnlyPlan(fetch))
+		result := pipeline.RunWithTrace(context.Background(), 1)
+
+		expected := strings.Join([]string{
+			`digraph "Fetching" {`,
+			"    rankdir=LR;",
+			`    start [label="Start", shape=circle];`,
+			`    a0 [label="Fetch (1, 3)", shape=box, style=filled, fillcolor="#ffe6e6", color="#cc3333", penwidth=2];`,
+			`    a1 [label="Backoff (2)", shape=box, color="#2b7a0b", penwidth=2];`,
+			`    terminate [label="End", shape=doublecircle];`,
+			`    start -> a0 [color="#2b7a0b", penwidth=3];`,
+			`    a0 -> terminate [label="success", color="#2b7a0b", penwidth=3];`,
+			`    a0 -> a1 [label="error", color="#2b7a0b", penwidth=3];`,
+			`    a0 -> terminate [label="abort", color="#cccccc", fontcolor="#999999"];`,
+			`    a1 -> a0 [label="success", color="#2b7a0b", penwidth=3];`,
+			`    a1 -> terminate [label="error", color="#cccccc", fontcolor="#999999"];`,
+			`    a1 -> terminate [label="abort", color="#cccccc", fontcolor="#999999"];`,
+			"}",
+			"",
+		}, "\n")
+
+		assert.Equal(t, expected, ExportRunDOT(pipeline, result.Trace))
+	})
+
+	t.Run("unfinished trace leaves termination dimmed", func(t *testing.T) {
+		collatz := NewCollatz("Collatz")
+		trace := RunTrace{Pipeline: "Collatz", Steps: []StepOutcome{{Action: "CheckNext", Direction: "even"}}}
+
+		diagram := ExportRunMermaid(collatz.Pipeline, trace)
+
+		assert.Contains(t, diagram, "    class a1,a2,terminate dimmed\n")
+	})
+}
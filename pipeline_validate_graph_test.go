@@ -256,3 +256,98 @@ func (d DirectingAction) Name() string { return d.name }
 func (d DirectingAction) Run(_ context.Context, _ int) (int, error) {
 	return 0, nil
 }
+
+func TestPipeline_ValidateGraph_Containment(t *testing.T) {
+	// NewPipeline cannot make a pipeline containing itself,
+	// so the members are assembled directly to simulate one
+	addMember := func(p *Pipeline[int], member Action[int]) {
+		p.memberIndex[member] = len(p.members)
+		p.members = append(p.members, member)
+		p.runPlans[member] = TerminationPlan[int]()
+	}
+
+	t.Run("direct", func(t *testing.T) {
+		p1 := NewPipeline[int]("P1", &DirectingAction{name: "action1"})
+		p2 := NewPipeline[int]("P2", p1)
+		addMember(p1, p2)
+
+		assert.EqualError(t, p1.ValidateGraph(), "pipeline contains itself: `P1` -> `P2` -> `P1`")
+		assert.EqualError(t, p2.ValidateGraph(), "pipeline contains itself: `P2` -> `P1` -> `P2`")
+	})
+
+	t.Run("three levels", func(t *testing.T) {
+		p1 := NewPipeline[int]("P1", &DirectingAction{name: "action1"})
+		p2 := NewPipeline[int]("P2", p1)
+		p3 := NewPipeline[int]("P3", p2)
+		addMember(p1, p3)
+		outer := NewPipeline[int]("Outer", &DirectingAction{name: "action0"}, p1)
+
+		assert.EqualError(t, p1.ValidateGraph(), "pipeline contains itself: `P1` -> `P3` -> `P2` -> `P1`")
+		assert.EqualError(t, outer.ValidateGraph(), "pipeline contains itself: `P1` -> `P3` -> `P2` -> `P1`")
+	})
+
+	t.Run("nested without cycle", func(t *testing.T) {
+		inner := NewPipeline[int]("Inner", &DirectingAction{name: "action1"})
+		outer := NewPipeline[int]("Outer", inner, NewPipeline[int]("Middle", inner))
+
+		assert.NoError(t, outer.ValidateGraph())
+	})
+}
+
+func TestPipeline_ValidateGraph_Reports(t *testing.T) {
+	t.Run("same cycle on every call", func(t *testing.T) {
+		route := &RoutingAction{name: "Route", directions: []string{"express", "standard", "hold"}}
+		action1 := &DirectingAction{name: "action1"}
+		action2 := &DirectingAction{name: "action2"}
+		pipeline := NewPipeline[int]("Pipeline", route, action1, action2)
+		pipeline.SetRunPlan(route, ActionPlan[int]{"express": action1, "standard": action2, "hold": action2})
+		pipeline.SetRunPlan(action1, TerminationPlan[int]())
+		pipeline.SetRunPlan(action2, SuccessOnlyPlan[int](route))
+
+		for i := 0; i < 20; i++ {
+			assert.EqualError(t, pipeline.ValidateGraph(), "cycle detected: [`Route` -hold-> `action2` -success-> `Route`]")
+		}
+	})
+
+	t.Run("disconnection after walks from other members", func(t *testing.T) {
+		action1 := &DirectingAction{name: "action1"}
+		action2 := &DirectingAction{name: "action2"}
+		action3 := &DirectingAction{name: "action3"}
+		action4 := &DirectingAction{name: "action4"}
+		pipeline := NewPipeline[int]("Pipeline", action1, action2, action3, action4)
+		pipeline.SetRunPlan(action1, TerminationPlan[int]())
+		pipeline.SetRunPlan(action2, SuccessOnlyPlan[int](action1))
+		pipeline.SetRunPlan(action3, TerminationPlan[int]())
+
+		assert.EqualError(t, pipeline.ValidateGraph(), "disconnect detected: action `action3` cannot reach the graph started from initAction `action1`")
+	})
+}
+
+func TestPipeline_CycleEdges(t *testing.T) {
+	t.Run("lists every cycle", func(t *testing.T) {
+		action1 := &DirectingAction{name: "action1"}
+		action2 := &DirectingAction{name: "action2"}
+		action3 := &DirectingAction{name: "action3"}
+		action4 := &DirectingAction{name: "action4"}
+		action5 := &DirectingAction{name: "action5"}
+		pipeline := NewPipeline("Pipeline", action1, action2, action3, action4, action5)
+		// action1 <-> action2 -> action3 <-> action4 -> action5
+		pipeline.SetRunPlan(action1, SuccessOnlyPlan(action2))
+		pipeline.SetRunPlan(action2, DefaultPlan(action3, action1))
+		pipeline.SetRunPlan(action3, SuccessOnlyPlan(action4))
+		pipeline.SetRunPlan(action4, ActionPlan[int]{Success: action5, Abort: action3})
+
+		assert.Equal(t, []CycleEdge[int]{
+			{From: action1, Direction: Success, To: action2},
+			{From: action2, Direction: Error, To: action1},
+			{From: action3, Direction: Success, To: action4},
+			{From: action4, Direction: Abort, To: action3},
+		}, pipeline.CycleEdges())
+	})
+
+	t.Run("acyclic", func(t *testing.T) {
+		collatz := NewCollatz("Collatz")
+
+		assert.Empty(t, collatz.CycleEdges())
+	})
+}
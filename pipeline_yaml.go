@@ -0,0 +1,142 @@
+package chain
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"gopkg.in/yaml.v3"
+)
+
+// PipelineDocument describes a whole pipeline in YAML, made by ExportYAML and read by NewPipelineFromYAML:
+// the name of the pipeline, the names of its members in order, the member runs start with,
+// and the plans of the members as PlanSpec describes them.
+//
+//	pipeline: Collatz
+//	actions:
+//	  - CheckNext
+//	  - OnEven
+//	  - OnOdd
+//	init: CheckNext
+//	plans:
+//	  CheckNext:
+//	    abort: ""
+//	    error: ""
+//	    even: OnEven
+//	    odd: OnOdd
+//	    success: ""
+type PipelineDocument struct {
+	Pipeline   string                       `yaml:"pipeline"`
+	Actions    []string                     `yaml:"actions"`
+	InitAction string                       `yaml:"init,omitempty"`
+	Plans      map[string]map[string]string `yaml:"plans"`
+}
+
+// ExportYAML encodes the pipeline as a PipelineDocument in YAML, indented for editing by hand,
+// to be turned back into a pipeline by NewPipelineFromYAML. Every planned direction is written,
+// with an empty name for termination, and the keys are sorted, so the same pipeline always
+// results in the same document.
+func (p *Pipeline[T]) ExportYAML() ([]byte, error) {
+	snapshot := p.Snapshot()
+	document := PipelineDocument{
+		Pipeline:   snapshot.Pipeline,
+		Actions:    p.ActionOrder(),
+		InitAction: p.entryAction().Name(),
+		Plans:      snapshot.Plans,
+	}
+
+	var buf bytes.Buffer
+	encoder := yaml.NewEncoder(&buf)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(document); err != nil {
+		return nil, err
+	}
+	if err := encoder.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// ActionRegistry resolves the names of actions in documents such as PipelineDocument to the actions.
+type ActionRegistry[T any] struct {
+	actions map[string]Action[T]
+}
+
+// NewActionRegistry creates a registry of the actions, as Register does for each of them.
+// An error is returned when multiple actions have the same name.
+func NewActionRegistry[T any](actions ...Action[T]) (*ActionRegistry[T], error) {
+	r := &ActionRegistry[T]{actions: make(map[string]Action[T], len(actions))}
+	for _, action := range actions {
+		if err := r.Register(action); err != nil {
+			return nil, err
+		}
+	}
+	return r, nil
+}
+
+// Register adds the action to the registry by its name.
+// An error is returned when the action is terminate, or another action has the same name.
+func (r *ActionRegistry[T]) Register(action Action[T]) error {
+	if action == nil {
+		return errors.New("cannot register terminate")
+	} else if _, exists := r.actions[action.Name()]; exists {
+		return fmt.Errorf("`%s` is already registered", action.Name())
+	}
+	r.actions[action.Name()] = action
+	return nil
+}
+
+// Lookup finds the registered action with the name.
+func (r *ActionRegistry[T]) Lookup(name string) (Action[T], bool) {
+	action, exists := r.actions[name]
+	return action, exists
+}
+
+// NewPipelineFromYAML creates a pipeline from a PipelineDocument in YAML, such as the one exported by
+// ExportYAML, looking up the actions by their names from the registry. The members are given to
+// NewPipeline in the order of the document, and the plans are applied as ApplyPlanSpec does.
+//
+// An error is returned when the document has unknown fields, refers to actions not registered or
+// not listed as members, lists a member multiple times, or describes plans SetRunPlan would reject.
+func NewPipelineFromYAML[T any](data []byte, registry *ActionRegistry[T]) (*Pipeline[T], error) {
+	var document PipelineDocument
+	decoder := yaml.NewDecoder(bytes.NewReader(data))
+	decoder.KnownFields(true)
+	if err := decoder.Decode(&document); err != nil {
+		return nil, fmt.Errorf("invalid pipeline document: %w", err)
+	}
+	if document.Pipeline == "" {
+		return nil, errors.New("invalid pipeline document: pipeline must have a name")
+	} else if len(document.Actions) == 0 {
+		return nil, errors.New("invalid pipeline document: no actions were described")
+	} else if registry == nil {
+		return nil, errors.New("cannot resolve actions without registry")
+	}
+
+	members := make([]Action[T], len(document.Actions))
+	listed := make(map[string]bool, len(document.Actions))
+	for i, name := range document.Actions {
+		action, exists := registry.Lookup(name)
+		if !exists {
+			return nil, fmt.Errorf("`%s` is not registered", name)
+		} else if listed[name] {
+			return nil, fmt.Errorf("`%s` is listed multiple times", name)
+		}
+		members[i], listed[name] = action, true
+	}
+
+	p := NewPipeline(document.Pipeline, members...)
+	if document.InitAction != "" {
+		initAction, exists := p.ActionByName(document.InitAction)
+		if !exists {
+			return nil, fmt.Errorf("`%s` is not a member of this pipeline", document.InitAction)
+		}
+		if err := p.SetInitAction(initAction); err != nil {
+			return nil, err
+		}
+	}
+	if err := ApplyPlanSpec(p, PlanSpec{Plans: document.Plans}); err != nil {
+		return nil, err
+	}
+
+	return p, nil
+}
@@ -0,0 +1,111 @@
+package chain
+
+import (
+	"context"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestPipeline_ExportYAML(t *testing.T) {
+	collatz := NewCollatz("Collatz")
+
+	data, err := collatz.ExportYAML()
+
+	assert.NoError(t, err)
+	assert.Equal(t, `pipeline: Collatz
+actions:
+  - CheckNext
+  - OnEven
+  - OnOdd
+init: CheckNext
+plans:
+  CheckNext:
+    abort: ""
+    error: ""
+    even: OnEven
+    odd: OnOdd
+    success: ""
+  OnEven:
+    abort: ""
+    error: ""
+    success: ""
+  OnOdd:
+    abort: ""
+    error: ""
+    success: ""
+`, string(data))
+}
+
+func TestNewPipelineFromYAML(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("inverse of export", func(t *testing.T) {
+		collatz := NewCollatz("Collatz")
+		collatz.SetInitAction(collatz.OnOdd)
+		collatz.SetRunPlan(collatz.OnOdd, ActionPlan[int]{Success: collatz.CheckNext})
+		data, err := collatz.ExportYAML()
+		assert.NoError(t, err)
+		registry, err := NewActionRegistry[int](collatz.CheckNext, collatz.OnEven, collatz.OnOdd)
+		assert.NoError(t, err)
+
+		imported, err := NewPipelineFromYAML(data, registry)
+
+		assert.NoError(t, err)
+		reexported, err := imported.ExportYAML()
+		assert.NoError(t, err)
+		assert.Equal(t, string(data), string(reexported))
+		expectedOutput, expectedErr := collatz.Run(ctx, 3)
+		output, err := imported.Run(ctx, 3)
+		assert.Equal(t, expectedOutput, output)
+		assert.Equal(t, expectedErr, err)
+	})
+
+	t.Run("rejects invalid documents", func(t *testing.T) {
+		collatz := NewCollatz("Collatz")
+		registry, _ := NewActionRegistry[int](collatz.CheckNext, collatz.OnEven, collatz.OnOdd)
+		testCases := map[string]struct {
+			document string
+			errMsg   string
+		}{
+			"unknown field": {
+				document: "pipeline: P\nactions: [CheckNext]\nmembers: [OnOdd]\n",
+				errMsg:   "invalid pipeline document: yaml: unmarshal errors:\n  line 3: field members not found in type chain.PipelineDocument",
+			},
+			"no name":        {document: "actions: [CheckNext]\n", errMsg: "invalid pipeline document: pipeline must have a name"},
+			"no actions":     {document: "pipeline: P\n", errMsg: "invalid pipeline document: no actions were described"},
+			"not registered": {document: "pipeline: P\nactions: [Unknown]\n", errMsg: "`Unknown` is not registered"},
+			"listed twice":   {document: "pipeline: P\nactions: [OnOdd, OnOdd]\n", errMsg: "`OnOdd` is listed multiple times"},
+			"init not member": {
+				document: "pipeline: P\nactions: [OnOdd]\ninit: OnEven\n",
+				errMsg:   "`OnEven` is not a member of this pipeline",
+			},
+			"plan not member": {
+				document: "pipeline: P\nactions: [OnOdd]\nplans:\n  OnOdd:\n    success: OnEven\n",
+				errMsg:   "setting plan from `OnOdd` directing `success` to non-member `OnEven`",
+			},
+		}
+
+		for name, tc := range testCases {
+			t.Run(name, func(t *testing.T) {
+				pipeline, err := NewPipelineFromYAML([]byte(tc.document), registry)
+
+				assert.Nil(t, pipeline)
+				assert.EqualError(t, err, tc.errMsg)
+			})
+		}
+	})
+}
+
+func TestNewActionRegistry(t *testing.T) {
+	_, err := NewActionRegistry[int](&SetTen{}, &SetTen{})
+	assert.EqualError(t, err, "`SetTen` is already registered")
+
+	_, err = NewActionRegistry[int](Terminate[int]())
+	assert.EqualError(t, err, "cannot register terminate")
+
+	registry, err := NewActionRegistry[int](&SetTen{})
+	assert.NoError(t, err)
+	action, exists := registry.Lookup("SetTen")
+	assert.True(t, exists)
+	assert.Equal(t, "SetTen", action.Name())
+}
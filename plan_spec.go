@@ -0,0 +1,116 @@
+package chain
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"gopkg.in/yaml.v3"
+)
+
+// PlanSpec describes the plans of a pipeline in YAML, to configure the wiring of the actions
+// without changing code. The actions are referred to by their names, and an empty name of the
+// next action means termination:
+//
+//	version: "2"
+//	plans:
+//	  CheckNext:
+//	    even: OnEven
+//	    odd: OnOdd
+//	    success: ""
+//
+// Each described plan is applied as SetRunPlan does, so the directions not described lead to
+// termination. Actions not described keep their current plans.
+type PlanSpec struct {
+	// Version identifies the revision of the spec, to be reported on reloading.
+	Version string `yaml:"version"`
+	// Plans maps the names of actions to their plans, which map directions to the names of the next actions.
+	Plans map[string]map[string]string `yaml:"plans"`
+}
+
+// ParsePlanSpec decodes a PlanSpec from YAML, rejecting unknown fields.
+func ParsePlanSpec(data []byte) (PlanSpec, error) {
+	var spec PlanSpec
+	decoder := yaml.NewDecoder(bytes.NewReader(data))
+	decoder.KnownFields(true)
+	if err := decoder.Decode(&spec); err != nil {
+		return PlanSpec{}, fmt.Errorf("invalid plan spec: %w", err)
+	}
+	if len(spec.Plans) == 0 {
+		return PlanSpec{}, errors.New("invalid plan spec: no plans were described")
+	}
+	return spec, nil
+}
+
+// ApplyPlanSpec validates the spec against the members of the pipeline, and applies its plans at once,
+// so runs never see the spec partially applied. An error is returned without changing the pipeline
+// when the spec refers to an unknown action, or describes a plan SetRunPlan would reject.
+func ApplyPlanSpec[T any](p *Pipeline[T], spec PlanSpec) error {
+	plans, err := p.resolveNamedPlans(spec.Plans)
+	if err != nil {
+		return err
+	}
+	return p.swapPlans(plans)
+}
+
+// SetPlanMatrix applies plans described as a table, which is compact and diff-friendly in
+// configuration files. The first row lists the directions from its second cell, and each following
+// row describes the plan of the action named in its first cell, with the names of the next actions
+// under the directions. An empty name means termination, and nil leaves the direction undescribed.
+// The first cell of the first row is ignored:
+//
+//	[][]interface{}{
+//		{"", "even", "odd"},
+//		{"CheckNext", "OnEven", "OnOdd"},
+//	}
+//
+// Each described plan is applied as ApplyPlanSpec does, at once. An error is returned without
+// changing the pipeline when a cell is neither a string nor nil, a row has more cells than the
+// directions, an action has multiple rows, or the plans are invalid as ApplyPlanSpec describes.
+func (p *Pipeline[T]) SetPlanMatrix(matrix [][]interface{}) error {
+	if len(matrix) == 0 {
+		return errors.New("plan matrix must have a row of directions")
+	}
+
+	directions := make([]string, len(matrix[0]))
+	for j := 1; j < len(matrix[0]); j++ {
+		direction, isString := matrix[0][j].(string)
+		if !isString || direction == "" {
+			return fmt.Errorf("direction of column %d must be a non-empty string", j)
+		}
+		directions[j] = direction
+	}
+
+	namedPlans := make(map[string]map[string]string, len(matrix)-1)
+	for i := 1; i < len(matrix); i++ {
+		row := matrix[i]
+		if len(row) == 0 {
+			return fmt.Errorf("row %d must start with the name of an action", i)
+		} else if len(row) > len(directions) {
+			return fmt.Errorf("row %d has more cells than directions", i)
+		}
+		name, isString := row[0].(string)
+		if !isString {
+			return fmt.Errorf("row %d must start with the name of an action", i)
+		} else if _, exists := namedPlans[name]; exists {
+			return fmt.Errorf("plan of `%s` is described multiple times", name)
+		}
+
+		namedPlan := make(map[string]string, len(row)-1)
+		for j := 1; j < len(row); j++ {
+			switch cell := row[j].(type) {
+			case nil:
+			case string:
+				namedPlan[directions[j]] = cell
+			default:
+				return fmt.Errorf("cell at row %d, column %d must be the name of an action", i, j)
+			}
+		}
+		namedPlans[name] = namedPlan
+	}
+
+	plans, err := p.resolveNamedPlans(namedPlans)
+	if err != nil {
+		return err
+	}
+	return p.swapPlans(plans)
+}
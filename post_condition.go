@@ -0,0 +1,112 @@
+package chain
+
+import (
+	"errors"
+	"fmt"
+	"github.com/sirupsen/logrus"
+)
+
+// PostConditionError is the error of a run whose output violates the condition set by SetPostCondition.
+// Direction is the direction the run ended with before the violation was found.
+type PostConditionError struct {
+	Pipeline  string
+	Direction string
+	Err       error
+}
+
+func (e *PostConditionError) Error() string {
+	return fmt.Sprintf("post condition violated on `%s`: %s", e.Direction, e.Err)
+}
+
+func (e *PostConditionError) Unwrap() error { return e.Err }
+
+// PostConditionOption customizes the condition set with SetPostCondition.
+type PostConditionOption func(*postConditionConfig)
+
+type postConditionConfig struct {
+	checkFailed bool
+	alert       func(err *PostConditionError)
+}
+
+// CheckFailedRuns makes the condition set with SetPostCondition checked on runs ending with
+// Error or Abort as well. Their violations join the errors of the runs, keeping their directions.
+func CheckFailedRuns() PostConditionOption {
+	return func(c *postConditionConfig) { c.checkFailed = true }
+}
+
+// AlertOnViolation makes fn called with every violation of the condition set with SetPostCondition,
+// for alerting on outputs in impossible states. A panic on fn is recovered and logged.
+func AlertOnViolation(fn func(err *PostConditionError)) PostConditionOption {
+	return func(c *postConditionConfig) { c.alert = fn }
+}
+
+type postCondition[T any] struct {
+	cond func(output T, direction string) error
+	postConditionConfig
+}
+
+// SetPostCondition sets cond to be checked at the end of each run with the output and the direction
+// the run ended with, as a safety net for outputs left in impossible states by complex plans.
+// When cond returns an error for a run ending with Success or a custom direction, the run ends with
+// Error and a *PostConditionError wrapping the error instead. Runs ending with Error or Abort are
+// not checked, unless CheckFailedRuns is given. A panic in cond is a violation as well.
+// Setting nil removes the condition. The change takes effect from the next run.
+func (p *Pipeline[T]) SetPostCondition(cond func(output T, direction string) error, opts ...PostConditionOption) {
+	var condition *postCondition[T]
+	if cond != nil {
+		condition = &postCondition[T]{cond: cond}
+		for _, opt := range opts {
+			opt(&condition.postConditionConfig)
+		}
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.postCondition = condition
+}
+
+// check checks the result of a run, returning the result to end the run with.
+func (c *postCondition[T]) check(frame *runFrame, output T, direction string, err error) (string, error) {
+	if c == nil {
+		return direction, err
+	}
+	failed := direction == Error || direction == Abort
+	if failed && !c.checkFailed {
+		return direction, err
+	}
+
+	condErr := callPostCondition(c.cond, output, direction)
+	if condErr == nil {
+		return direction, err
+	}
+	violation := &PostConditionError{Pipeline: frame.runnerName(), Direction: direction, Err: condErr}
+	logrus.Errorf("%s: %s", frame.runnerName(), violation)
+	if c.alert != nil {
+		callViolationAlert(c.alert, violation)
+	}
+
+	if failed {
+		return direction, errors.Join(err, violation)
+	}
+	return Error, violation
+}
+
+func callPostCondition[T any](cond func(T, string) error, output T, direction string) (err error) {
+	defer func() {
+		if panicErr := recover(); panicErr != nil {
+			err = panicToError(panicErr)
+		}
+	}()
+
+	return cond(output, direction)
+}
+
+func callViolationAlert(alert func(*PostConditionError), violation *PostConditionError) {
+	defer func() {
+		if panicErr := recover(); panicErr != nil {
+			logrus.Errorf("%s: panic occurred on alerting violation, caused by %s", violation.Pipeline, panicErr)
+		}
+	}()
+
+	alert(violation)
+}
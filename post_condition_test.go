@@ -0,0 +1,88 @@
+package chain
+
+import (
+	"context"
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestPipeline_SetPostCondition(t *testing.T) {
+	ctx := context.Background()
+	errOdd := errors.New("output must be even")
+	mustBeEven := func(output int, _ string) error {
+		if output%2 != 0 {
+			return errOdd
+		}
+		return nil
+	}
+
+	t.Run("passes valid outputs", func(t *testing.T) {
+		collatz := NewCollatz("Collatz")
+		collatz.SetPostCondition(mustBeEven)
+
+		output, direction, err := collatz.run(ctx, 5)
+
+		assert.NoError(t, err)
+		assert.Equal(t, Success, direction)
+		assert.Equal(t, 16, output)
+	})
+
+	t.Run("downgrades violations to error", func(t *testing.T) {
+		var alerted []*PostConditionError
+		collatz := NewCollatz("Collatz")
+		collatz.SetPostCondition(mustBeEven, AlertOnViolation(func(err *PostConditionError) {
+			alerted = append(alerted, err)
+		}))
+
+		output, direction, err := collatz.run(ctx, 10)
+
+		assert.EqualError(t, err, "post condition violated on `success`: output must be even")
+		assert.ErrorIs(t, err, errOdd)
+		assert.Equal(t, Error, direction)
+		assert.Equal(t, 5, output)
+		assert.Equal(t, []*PostConditionError{{Pipeline: "Collatz", Direction: Success, Err: errOdd}}, alerted)
+	})
+
+	t.Run("skips failed runs by default", func(t *testing.T) {
+		pipeline := NewPipeline("Pipeline", &ErrorMaker{message: "error1"})
+		pipeline.SetPostCondition(mustBeEven)
+
+		_, direction, err := pipeline.run(ctx, 1)
+
+		assert.EqualError(t, err, "error1")
+		assert.Equal(t, Error, direction)
+	})
+
+	t.Run("checks failed runs when configured", func(t *testing.T) {
+		pipeline := NewPipeline("Pipeline", &ErrorMaker{message: "error1"})
+		pipeline.SetPostCondition(mustBeEven, CheckFailedRuns())
+
+		_, direction, err := pipeline.run(ctx, 1)
+
+		assert.ErrorIs(t, err, errOdd)
+		assert.ErrorContains(t, err, "error1")
+		assert.Equal(t, Error, direction)
+	})
+
+	t.Run("treats panics as violations", func(t *testing.T) {
+		pipeline := NewPipeline("Pipeline", &SetTen{})
+		pipeline.SetPostCondition(func(int, string) error { panic("broken condition") },
+			AlertOnViolation(func(*PostConditionError) { panic("broken alert") }))
+
+		_, direction, err := pipeline.run(ctx, 1)
+
+		assert.EqualError(t, err, "post condition violated on `success`: broken condition")
+		assert.Equal(t, Error, direction)
+	})
+
+	t.Run("removes condition", func(t *testing.T) {
+		collatz := NewCollatz("Collatz")
+		collatz.SetPostCondition(mustBeEven)
+		collatz.SetPostCondition(nil)
+
+		_, _, err := collatz.run(ctx, 10)
+
+		assert.NoError(t, err)
+	})
+}
@@ -0,0 +1,20 @@
+package chain
+
+import (
+	"context"
+	"time"
+)
+
+// RemainingBudget reports the time left until the deadline of ctx, for actions sizing the timeouts
+// of their own calls to the rest of the run, such as giving an HTTP call min(3s, remaining-500ms).
+// It reports false when ctx has no deadline, and zero once the deadline has passed.
+//
+// The deadline of a run is the deadline of its ctx, such as the one given to RunWithDeadline,
+// and nested pipelines run with the ctx of their parents, so the budget stays accurate inside them.
+func RemainingBudget(ctx context.Context) (time.Duration, bool) {
+	deadline, exists := ctx.Deadline()
+	if !exists {
+		return 0, false
+	}
+	return max(time.Until(deadline), 0), true
+}
@@ -0,0 +1,61 @@
+package chain
+
+import (
+	"context"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func TestRemainingBudget(t *testing.T) {
+	t.Run("reports false without deadline", func(t *testing.T) {
+		remaining, exists := RemainingBudget(context.Background())
+
+		assert.False(t, exists)
+		assert.Zero(t, remaining)
+	})
+
+	t.Run("reports zero after deadline", func(t *testing.T) {
+		ctx, cancel := context.WithDeadline(context.Background(), time.Now().Add(-time.Second))
+		defer cancel()
+
+		remaining, exists := RemainingBudget(ctx)
+
+		assert.True(t, exists)
+		assert.Zero(t, remaining)
+	})
+
+	t.Run("splits budget in nested pipeline", func(t *testing.T) {
+		// Lookup gives its first call at most half of the remaining budget,
+		// and the rest of it to the second call.
+		var budgets []time.Duration
+		call := func(ctx context.Context) {
+			remaining, _ := RemainingBudget(ctx)
+			budgets = append(budgets, remaining)
+		}
+		lookup := NewSimpleAction("Lookup", func(ctx context.Context, input int) (int, error) {
+			remaining, exists := RemainingBudget(ctx)
+			if !exists {
+				t.Fatal("budget is not available")
+			}
+
+			firstCtx, cancel := context.WithTimeout(ctx, remaining/2)
+			call(firstCtx)
+			cancel()
+
+			call(ctx)
+			return input + 1, nil
+		})
+		pipeline := NewPipeline("Outer", NewPipeline("Inner", lookup))
+
+		output, direction, err := pipeline.RunWithDeadline(context.Background(), 1, time.Now().Add(time.Minute))
+
+		assert.NoError(t, err)
+		assert.Equal(t, Success, direction)
+		assert.Equal(t, 2, output)
+		if assert.Len(t, budgets, 2) {
+			assert.InDelta(t, 30*time.Second, budgets[0], float64(time.Second))
+			assert.InDelta(t, time.Minute, budgets[1], float64(time.Second))
+		}
+	})
+}
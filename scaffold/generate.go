@@ -0,0 +1,245 @@
+// Package scaffold generates Go source code of a pipeline from a declarative spec.
+// The generated code has a stub struct for each action, to be filled with the actual logic,
+// and a constructor wiring them into a chain.Pipeline as the spec describes.
+package scaffold
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"github.com/JSYoo5B/chain"
+	"go/format"
+	"go/token"
+	"strings"
+	"text/template"
+	"unicode"
+)
+
+// Generate renders the Go files for the given spec, keyed by their file names.
+// Each action gets its own file, and the constructor of the pipeline is in `<pipeline>_pipeline.go`.
+// The returned sources are gofmt-ed, and the spec is validated as chain.Pipeline would do on construction.
+func Generate(spec PipelineSpec) (map[string][]byte, error) {
+	data, err := newTemplateData(spec)
+	if err != nil {
+		return nil, err
+	}
+
+	files := make(map[string][]byte, len(data.Actions)+1)
+	for _, action := range data.Actions {
+		source, err := render(actionTemplate, struct {
+			*templateData
+			Action actionData
+		}{data, action})
+		if err != nil {
+			return nil, fmt.Errorf("generating action `%s`: %w", action.Name, err)
+		}
+		files[snakeCase(action.TypeName)+".go"] = source
+	}
+
+	source, err := render(pipelineTemplate, data)
+	if err != nil {
+		return nil, fmt.Errorf("generating pipeline `%s`: %w", spec.Name, err)
+	}
+	files[snakeCase(data.Constructor[len("New"):])+".go"] = source
+
+	return files, nil
+}
+
+type templateData struct {
+	Package     string
+	Name        string
+	Type        string
+	Imports     []string
+	Constructor string
+	Actions     []actionData
+	Plans       []planData
+}
+
+type actionData struct {
+	Name       string
+	TypeName   string
+	VarName    string
+	Directions []string
+}
+
+type planData struct {
+	VarName string
+	Edges   []edgeData
+}
+
+type edgeData struct {
+	Direction string
+	Target    string
+}
+
+func newTemplateData(spec PipelineSpec) (*templateData, error) {
+	if !token.IsIdentifier(spec.Package) {
+		return nil, fmt.Errorf("invalid package name `%s`", spec.Package)
+	}
+	if spec.Name == "" {
+		return nil, errors.New("pipeline must have a name")
+	}
+	if spec.Type == "" {
+		return nil, errors.New("pipeline must have a type")
+	}
+	if len(spec.Actions) == 0 {
+		return nil, errors.New("no actions were described for creating pipeline")
+	}
+
+	data := &templateData{
+		Package:     spec.Package,
+		Name:        spec.Name,
+		Type:        spec.Type,
+		Imports:     spec.Imports,
+		Constructor: "New" + identifier(spec.Name) + "Pipeline",
+	}
+
+	actions := map[string]actionData{}
+	typeNames := map[string]string{}
+	for i, action := range spec.Actions {
+		if action.Name == "" {
+			return nil, fmt.Errorf("action %d must have a name", i+1)
+		}
+		if _, exists := actions[action.Name]; exists {
+			return nil, fmt.Errorf("duplicate action `%s` specified", action.Name)
+		}
+		typeName := identifier(action.Name) + "Action"
+		if other, exists := typeNames[typeName]; exists {
+			return nil, fmt.Errorf("actions `%s` and `%s` generate the same type `%s`", other, action.Name, typeName)
+		}
+		for _, direction := range action.Directions {
+			if direction == "" || isCommonDirection(direction) {
+				return nil, fmt.Errorf("`%s` declares invalid custom direction `%s`", action.Name, direction)
+			}
+		}
+
+		typeNames[typeName] = action.Name
+		actions[action.Name] = actionData{
+			Name:       action.Name,
+			TypeName:   typeName,
+			VarName:    lowerFirst(typeName),
+			Directions: action.Directions,
+		}
+		data.Actions = append(data.Actions, actions[action.Name])
+	}
+
+	planIndex := map[string]int{}
+	for _, edge := range spec.Edges {
+		from, exists := actions[edge.From]
+		if !exists {
+			return nil, fmt.Errorf("edge from unknown action `%s`", edge.From)
+		}
+		if !isCommonDirection(edge.Direction) && !contains(from.Directions, edge.Direction) {
+			return nil, fmt.Errorf("`%s` does not support direction `%s`", edge.From, edge.Direction)
+		}
+
+		target := "chain.Terminate[" + spec.Type + "]()"
+		if edge.To != "" {
+			to, exists := actions[edge.To]
+			if !exists {
+				return nil, fmt.Errorf("edge from `%s` directing `%s` to unknown action `%s`", edge.From, edge.Direction, edge.To)
+			}
+			if to.Name == from.Name {
+				return nil, fmt.Errorf("self loop edge with `%s` directing `%s`", edge.From, edge.Direction)
+			}
+			target = to.VarName
+		}
+
+		i, exists := planIndex[edge.From]
+		if !exists {
+			i = len(data.Plans)
+			planIndex[edge.From] = i
+			data.Plans = append(data.Plans, planData{VarName: from.VarName})
+		}
+		for _, planned := range data.Plans[i].Edges {
+			if planned.Direction == directionLiteral(edge.Direction) {
+				return nil, fmt.Errorf("duplicate edge from `%s` directing `%s`", edge.From, edge.Direction)
+			}
+		}
+		data.Plans[i].Edges = append(data.Plans[i].Edges, edgeData{
+			Direction: directionLiteral(edge.Direction),
+			Target:    target,
+		})
+	}
+
+	return data, nil
+}
+
+func render(tmpl *template.Template, data any) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, data); err != nil {
+		return nil, err
+	}
+	return format.Source(buf.Bytes())
+}
+
+func isCommonDirection(direction string) bool {
+	return direction == chain.Success || direction == chain.Error || direction == chain.Abort
+}
+
+// directionLiteral refers the common directions by their constants in the chain package.
+func directionLiteral(direction string) string {
+	switch direction {
+	case chain.Success:
+		return "chain.Success"
+	case chain.Error:
+		return "chain.Error"
+	case chain.Abort:
+		return "chain.Abort"
+	default:
+		return fmt.Sprintf("%q", direction)
+	}
+}
+
+func contains(directions []string, direction string) bool {
+	for _, dir := range directions {
+		if dir == direction {
+			return true
+		}
+	}
+	return false
+}
+
+// identifier converts a name such as `charge-card` or `charge card` into an exported Go identifier `ChargeCard`.
+func identifier(name string) string {
+	var sb strings.Builder
+	upperNext := true
+	for _, r := range name {
+		if !unicode.IsLetter(r) && !unicode.IsDigit(r) {
+			upperNext = true
+			continue
+		}
+		if upperNext {
+			r = unicode.ToUpper(r)
+			upperNext = false
+		}
+		sb.WriteRune(r)
+	}
+
+	ident := sb.String()
+	if ident == "" || !unicode.IsLetter([]rune(ident)[0]) {
+		ident = "X" + ident
+	}
+	return ident
+}
+
+func lowerFirst(ident string) string {
+	runes := []rune(ident)
+	runes[0] = unicode.ToLower(runes[0])
+	return string(runes)
+}
+
+func snakeCase(ident string) string {
+	var sb strings.Builder
+	runes := []rune(ident)
+	for i, r := range runes {
+		if unicode.IsUpper(r) {
+			if i > 0 && (unicode.IsLower(runes[i-1]) || (i+1 < len(runes) && unicode.IsLower(runes[i+1]))) {
+				sb.WriteRune('_')
+			}
+			r = unicode.ToLower(r)
+		}
+		sb.WriteRune(r)
+	}
+	return sb.String()
+}
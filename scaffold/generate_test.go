@@ -0,0 +1,150 @@
+package scaffold
+
+import (
+	"github.com/stretchr/testify/assert"
+	"go/format"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+)
+
+func sampleSpec() PipelineSpec {
+	return PipelineSpec{
+		Package: "checkout",
+		Name:    "Checkout",
+		Type:    "*time.Time",
+		Imports: []string{"time"},
+		Actions: []ActionSpec{
+			{Name: "validate-order"},
+			{Name: "ChargeCard", Directions: []string{"OutOfStock", "Declined"}},
+			{Name: "ship order"},
+			{Name: "Refund"},
+		},
+		Edges: []EdgeSpec{
+			{From: "validate-order", Direction: "success", To: "ChargeCard"},
+			{From: "ChargeCard", Direction: "success", To: "ship order"},
+			{From: "ChargeCard", Direction: "error", To: "Refund"},
+			{From: "ChargeCard", Direction: "OutOfStock", To: "Refund"},
+			{From: "ChargeCard", Direction: "Declined"},
+		},
+	}
+}
+
+func TestGenerate(t *testing.T) {
+	t.Run("files for each action and pipeline", func(t *testing.T) {
+		files, err := Generate(sampleSpec())
+
+		assert.NoError(t, err)
+		names := make([]string, 0, len(files))
+		for name := range files {
+			names = append(names, name)
+		}
+		assert.ElementsMatch(t, []string{
+			"validate_order_action.go",
+			"charge_card_action.go",
+			"ship_order_action.go",
+			"refund_action.go",
+			"checkout_pipeline.go",
+		}, names)
+		assert.Contains(t, string(files["charge_card_action.go"]), "func (ChargeCardAction) NextDirection(")
+		assert.Contains(t, string(files["checkout_pipeline.go"]), `"OutOfStock":  refundAction,`)
+	})
+
+	t.Run("generated code is gofmt-ed", func(t *testing.T) {
+		files, err := Generate(sampleSpec())
+
+		assert.NoError(t, err)
+		for name, source := range files {
+			formatted, err := format.Source(source)
+			assert.NoError(t, err, name)
+			assert.Equal(t, string(formatted), string(source), name)
+		}
+	})
+
+	t.Run("generated code compiles", func(t *testing.T) {
+		if testing.Short() {
+			t.Skip("skipping compilation in short mode")
+		}
+		goBinary, err := exec.LookPath("go")
+		if err != nil {
+			t.Skip("go command is not available")
+		}
+		files, err := Generate(sampleSpec())
+		assert.NoError(t, err)
+
+		// Build inside this module, so the generated code resolves the chain package
+		dir, err := os.MkdirTemp(".", "generated")
+		assert.NoError(t, err)
+		defer func() { _ = os.RemoveAll(dir) }()
+		for name, source := range files {
+			assert.NoError(t, os.WriteFile(filepath.Join(dir, name), source, 0o644))
+		}
+
+		output, err := exec.Command(goBinary, "vet", "./"+filepath.Base(dir)).CombinedOutput()
+		assert.NoError(t, err, string(output))
+	})
+}
+
+func TestGenerateInvalidSpec(t *testing.T) {
+	testCases := map[string]struct {
+		modify  func(spec *PipelineSpec)
+		message string
+	}{
+		"invalid package": {
+			modify:  func(spec *PipelineSpec) { spec.Package = "check-out" },
+			message: "invalid package name `check-out`",
+		},
+		"without name": {
+			modify:  func(spec *PipelineSpec) { spec.Name = "" },
+			message: "pipeline must have a name",
+		},
+		"without actions": {
+			modify:  func(spec *PipelineSpec) { spec.Actions = nil },
+			message: "no actions were described for creating pipeline",
+		},
+		"duplicate action": {
+			modify:  func(spec *PipelineSpec) { spec.Actions = append(spec.Actions, ActionSpec{Name: "Refund"}) },
+			message: "duplicate action `Refund` specified",
+		},
+		"conflicting type names": {
+			modify:  func(spec *PipelineSpec) { spec.Actions = append(spec.Actions, ActionSpec{Name: "refund"}) },
+			message: "actions `Refund` and `refund` generate the same type `RefundAction`",
+		},
+		"unsupported direction": {
+			modify: func(spec *PipelineSpec) {
+				spec.Edges = append(spec.Edges, EdgeSpec{From: "Refund", Direction: "OutOfStock"})
+			},
+			message: "`Refund` does not support direction `OutOfStock`",
+		},
+		"unknown target": {
+			modify: func(spec *PipelineSpec) {
+				spec.Edges = append(spec.Edges, EdgeSpec{From: "Refund", Direction: "success", To: "Notify"})
+			},
+			message: "edge from `Refund` directing `success` to unknown action `Notify`",
+		},
+		"self loop": {
+			modify: func(spec *PipelineSpec) {
+				spec.Edges = append(spec.Edges, EdgeSpec{From: "Refund", Direction: "error", To: "Refund"})
+			},
+			message: "self loop edge with `Refund` directing `error`",
+		},
+		"duplicate edge": {
+			modify: func(spec *PipelineSpec) {
+				spec.Edges = append(spec.Edges, EdgeSpec{From: "ChargeCard", Direction: "Declined", To: "Refund"})
+			},
+			message: "duplicate edge from `ChargeCard` directing `Declined`",
+		},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			spec := sampleSpec()
+			tc.modify(&spec)
+
+			_, err := Generate(spec)
+
+			assert.EqualError(t, err, tc.message)
+		})
+	}
+}
@@ -0,0 +1,36 @@
+package scaffold
+
+// PipelineSpec declares a pipeline to generate code for.
+// It describes the member actions in the order they are passed to chain.NewPipeline,
+// and the edges which are turned into chain.SetRunPlan calls.
+type PipelineSpec struct {
+	// Package is the name of the package the generated files belong to.
+	Package string
+	// Name is the name of the pipeline, also used to name its constructor.
+	Name string
+	// Type is the Go type processed by the pipeline, e.g. `int` or `*order.Order`.
+	Type string
+	// Imports lists additional import paths needed by Type.
+	Imports []string
+	// Actions lists the member actions of the pipeline.
+	Actions []ActionSpec
+	// Edges lists the plan of the pipeline. When any edge is given from an action,
+	// directions of the action without an edge lead to termination,
+	// as chain.SetRunPlan does.
+	Edges []EdgeSpec
+}
+
+// ActionSpec declares a member action of the pipeline.
+// An action with custom Directions is generated as a chain.BranchAction.
+type ActionSpec struct {
+	Name       string
+	Directions []string
+}
+
+// EdgeSpec declares that the action named From continues to the action named To,
+// when it directs Direction. Leaving To empty directs to termination.
+type EdgeSpec struct {
+	From      string
+	Direction string
+	To        string
+}
@@ -0,0 +1,76 @@
+package scaffold
+
+import "text/template"
+
+var actionTemplate = template.Must(template.New("action").Parse(`// Code generated by scaffold from the pipeline spec; fill in the TODOs.
+
+package {{.Package}}
+
+import (
+	"context"
+{{- range .Imports}}
+	"{{.}}"
+{{- end}}
+{{- if .Action.Directions}}
+
+	"github.com/JSYoo5B/chain"
+{{- end}}
+)
+
+// {{.Action.TypeName}} implements the ` + "`{{.Action.Name}}`" + ` step of the {{.Name}} pipeline.
+type {{.Action.TypeName}} struct{}
+
+func ({{.Action.TypeName}}) Name() string { return {{printf "%q" .Action.Name}} }
+{{- if .Action.Directions}}
+func ({{.Action.TypeName}}) Directions() []string {
+	return []string{ {{- range $i, $d := .Action.Directions}}{{if $i}}, {{end}}{{printf "%q" $d}}{{end -}} }
+}
+{{- end}}
+func ({{.Action.TypeName}}) Run(_ context.Context, input {{.Type}}) (output {{.Type}}, err error) {
+	// TODO: implement {{.Action.Name}}
+	return input, nil
+}
+{{- if .Action.Directions}}
+func ({{.Action.TypeName}}) NextDirection(_ context.Context, output {{.Type}}) (direction string, err error) {
+	// TODO: select the direction for the output
+	return chain.Success, nil
+}
+{{- end}}
+`))
+
+var pipelineTemplate = template.Must(template.New("pipeline").Parse(`// Code generated by scaffold from the pipeline spec.
+
+package {{.Package}}
+
+import (
+{{- range .Imports}}
+	"{{.}}"
+{{- end}}
+{{- if .Imports}}
+{{end}}
+	"github.com/JSYoo5B/chain"
+)
+
+// {{.Constructor}} creates the {{.Name}} pipeline, wiring its actions as the spec describes.
+func {{.Constructor}}() *chain.Pipeline[{{.Type}}] {
+{{- range .Actions}}
+	{{.VarName}} := &{{.TypeName}}{}
+{{- end}}
+
+	pipeline := chain.NewPipeline(
+		{{printf "%q" .Name}},
+{{- range .Actions}}
+		{{.VarName}},
+{{- end}}
+	)
+{{- range .Plans}}
+	pipeline.SetRunPlan({{.VarName}}, chain.ActionPlan[{{$.Type}}]{
+{{- range .Edges}}
+		{{.Direction}}: {{.Target}},
+{{- end}}
+	})
+{{- end}}
+
+	return pipeline
+}
+`))
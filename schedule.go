@@ -0,0 +1,133 @@
+package chain
+
+import (
+	"context"
+	"errors"
+	"github.com/sirupsen/logrus"
+	"math/rand"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Clock provides the passage of time to Schedule, which can be replaced on tests.
+type Clock interface {
+	// After delivers the current time on the returned channel after the duration.
+	After(d time.Duration) <-chan time.Time
+}
+
+type realClock struct{}
+
+func (realClock) After(d time.Duration) <-chan time.Time { return time.After(d) }
+
+// ScheduleOptions configures how Schedule runs a pipeline.
+type ScheduleOptions struct {
+	// Every is the interval between runs, which must be positive.
+	Every time.Duration
+	// Jitter adds a random duration up to itself to each interval,
+	// to spread runs of multiple instances of a service.
+	Jitter time.Duration
+	// SkipIfRunning guarantees runs never overlap, by skipping the schedule while a run is in progress.
+	// The next interval starts when the run finishes.
+	// Otherwise, each run starts on its own goroutine, regardless of the previous runs.
+	SkipIfRunning bool
+	// Backoff adds a delay to the interval after consecutive runs ended with an error,
+	// given the number of those runs. No delay is added when Backoff is nil.
+	Backoff func(consecutiveFailures int) time.Duration
+	// Clock provides the passage of time, which is the real time when nil.
+	Clock Clock
+}
+
+// ScheduleHandle controls the runs started by Schedule.
+type ScheduleHandle struct {
+	cancel context.CancelFunc
+	done   chan struct{}
+}
+
+// Stop stops scheduling further runs, cancelling the context given to the runs in progress,
+// which abort before their next action. It returns after every run has finished.
+func (h *ScheduleHandle) Stop() {
+	h.cancel()
+	<-h.done
+}
+
+// Schedule runs the pipeline repeatedly on the interval of the options, until ctx is done or
+// the returned handle is stopped. Each run takes its input from the input function.
+// The outcome of each run is logged, and delivered to the notifiers of the pipeline
+// registered with NotifyOnComplete.
+//
+// An error is returned when the options are invalid.
+func Schedule[T any](ctx context.Context, p *Pipeline[T], input func() T, opts ScheduleOptions) (*ScheduleHandle, error) {
+	if p == nil {
+		return nil, errors.New("cannot schedule nil pipeline")
+	} else if input == nil {
+		return nil, errors.New("input function must be given for scheduling")
+	} else if opts.Every <= 0 {
+		return nil, errors.New("schedule interval must be positive")
+	} else if opts.Jitter < 0 {
+		return nil, errors.New("schedule jitter must not be negative")
+	}
+	if opts.Clock == nil {
+		opts.Clock = realClock{}
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	h := &ScheduleHandle{cancel: cancel, done: make(chan struct{})}
+	go func() {
+		defer close(h.done)
+		var (
+			wg       sync.WaitGroup
+			failures atomic.Int64
+		)
+		defer wg.Wait()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-opts.Clock.After(opts.nextInterval(int(failures.Load()))):
+			}
+
+			if opts.SkipIfRunning {
+				runScheduled(ctx, p, input, &failures)
+				continue
+			}
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				runScheduled(ctx, p, input, &failures)
+			}()
+		}
+	}()
+
+	return h, nil
+}
+
+func (opts ScheduleOptions) nextInterval(consecutiveFailures int) time.Duration {
+	interval := opts.Every
+	if opts.Jitter > 0 {
+		interval += time.Duration(rand.Int63n(int64(opts.Jitter) + 1))
+	}
+	if opts.Backoff != nil && consecutiveFailures > 0 {
+		interval += opts.Backoff(consecutiveFailures)
+	}
+	return interval
+}
+
+func runScheduled[T any](ctx context.Context, p *Pipeline[T], input func() T, failures *atomic.Int64) {
+	defer func() {
+		if panicErr := recover(); panicErr != nil {
+			logrus.Errorf("%s: panic occurred on scheduled input, caused by %s", p.Name(), panicErr)
+			failures.Add(1)
+		}
+	}()
+
+	_, direction, err := p.runFallingBack(ctx, input())
+	if err != nil {
+		logrus.Errorf("%s: scheduled run ended with `%s`, caused by %s", p.Name(), direction, err)
+		failures.Add(1)
+		return
+	}
+	logrus.Debugf("%s: scheduled run ended with `%s`", p.Name(), direction)
+	failures.Store(0)
+}
@@ -0,0 +1,140 @@
+package chain
+
+import (
+	"context"
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func TestSchedule(t *testing.T) {
+	t.Run("backoff after consecutive failures", func(t *testing.T) {
+		clock := newFakeClock()
+		inputs := []int{1, 1, 2, 1}
+		next := 0
+		ran := make(chan int, len(inputs))
+		pipeline := NewPipeline("Pipeline", NewSimpleAction("Even", func(_ context.Context, input int) (int, error) {
+			ran <- input
+			if input%2 != 0 {
+				return input, errors.New("odd input")
+			}
+			return input, nil
+		}))
+
+		handle, err := Schedule(context.Background(), pipeline, func() int { next++; return inputs[next-1] }, ScheduleOptions{
+			Every:         5 * time.Minute,
+			SkipIfRunning: true,
+			Backoff:       func(failures int) time.Duration { return time.Duration(failures) * time.Minute },
+			Clock:         clock,
+		})
+		assert.NoError(t, err)
+
+		var intervals []time.Duration
+		for range inputs {
+			intervals = append(intervals, clock.tick())
+			<-ran
+		}
+		intervals = append(intervals, clock.next().duration)
+		handle.Stop()
+
+		assert.Equal(t, []time.Duration{5 * time.Minute, 6 * time.Minute, 7 * time.Minute, 5 * time.Minute, 6 * time.Minute}, intervals)
+	})
+
+	t.Run("skip while running", func(t *testing.T) {
+		clock := newFakeClock()
+		started, release := make(chan struct{}, 2), make(chan struct{})
+		pipeline := NewPipeline("Pipeline", NewSimpleAction("Block", func(_ context.Context, input int) (int, error) {
+			started <- struct{}{}
+			<-release
+			return input, nil
+		}))
+
+		handle, err := Schedule(context.Background(), pipeline, func() int { return 1 }, ScheduleOptions{
+			Every:         time.Minute,
+			SkipIfRunning: true,
+			Clock:         clock,
+		})
+		assert.NoError(t, err)
+
+		clock.tick()
+		<-started
+		select {
+		case <-clock.waits:
+			t.Fatal("must not schedule while running")
+		case <-time.After(10 * time.Millisecond):
+		}
+		close(release)
+		clock.next()
+		handle.Stop()
+	})
+
+	t.Run("overlap without skipping", func(t *testing.T) {
+		clock := newFakeClock()
+		started, release := make(chan struct{}, 2), make(chan struct{})
+		pipeline := NewPipeline("Pipeline", NewSimpleAction("Block", func(_ context.Context, input int) (int, error) {
+			started <- struct{}{}
+			<-release
+			return input, nil
+		}))
+
+		handle, err := Schedule(context.Background(), pipeline, func() int { return 1 }, ScheduleOptions{
+			Every: time.Minute,
+			Clock: clock,
+		})
+		assert.NoError(t, err)
+
+		clock.tick()
+		clock.tick()
+		<-started
+		<-started
+		close(release)
+		handle.Stop()
+	})
+
+	t.Run("invalid options", func(t *testing.T) {
+		pipeline := NewPipeline[int]("Pipeline", &SetTen{})
+		input := func() int { return 1 }
+
+		_, err := Schedule(context.Background(), pipeline, input, ScheduleOptions{})
+		assert.EqualError(t, err, "schedule interval must be positive")
+
+		_, err = Schedule(context.Background(), pipeline, input, ScheduleOptions{Every: time.Minute, Jitter: -time.Second})
+		assert.EqualError(t, err, "schedule jitter must not be negative")
+
+		_, err = Schedule(context.Background(), pipeline, nil, ScheduleOptions{Every: time.Minute})
+		assert.EqualError(t, err, "input function must be given for scheduling")
+	})
+}
+
+type fakeWait struct {
+	duration time.Duration
+	ch       chan time.Time
+}
+
+// fakeClock lets tests decide when the waits requested by After pass.
+type fakeClock struct {
+	waits chan fakeWait
+}
+
+func newFakeClock() *fakeClock {
+	return &fakeClock{waits: make(chan fakeWait)}
+}
+
+func (c *fakeClock) After(d time.Duration) <-chan time.Time {
+	ch := make(chan time.Time, 1)
+	go func() { c.waits <- fakeWait{duration: d, ch: ch} }()
+	return ch
+}
+
+// next receives the next wait requested by After.
+func (c *fakeClock) next() fakeWait {
+	return <-c.waits
+}
+
+// tick passes the next wait requested by After, and reports its duration.
+func (c *fakeClock) tick() time.Duration {
+	wait := c.next()
+	wait.ch <- time.Time{}
+	return wait.duration
+}
@@ -14,6 +14,8 @@ type RunFunc[T any] func(ctx context.Context, input T) (output T, err error)
 //
 // This allows for the creation of simple Actions without manually defining a separate struct
 // that implements the Action interface.
+// The Action continues with Success when runFunc returns no error, and with Error otherwise,
+// so its plan takes the directions Success, Error and Abort.
 func NewSimpleAction[T any](name string, runFunc RunFunc[T]) Action[T] {
 	return &simpleAction[T]{
 		name:    name,
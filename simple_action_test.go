@@ -0,0 +1,39 @@
+package chain
+
+import (
+	"context"
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestNewSimpleAction(t *testing.T) {
+	ctx := context.Background()
+	errOdd := errors.New("odd input")
+	halve := NewSimpleAction("Halve", func(_ context.Context, input int) (int, error) {
+		if input%2 != 0 {
+			return input, errOdd
+		}
+		return input / 2, nil
+	})
+
+	t.Run("plans basic directions", func(t *testing.T) {
+		assert.Equal(t, []string{Success, Error, Abort}, directionsOf(halve))
+	})
+
+	t.Run("continues with success on nil error", func(t *testing.T) {
+		output, direction, err, _ := runAction(halve, ctx, 4, false)
+
+		assert.NoError(t, err)
+		assert.Equal(t, Success, direction)
+		assert.Equal(t, 2, output)
+	})
+
+	t.Run("continues with error on error", func(t *testing.T) {
+		output, direction, err, _ := runAction(halve, ctx, 3, false)
+
+		assert.ErrorIs(t, err, errOdd)
+		assert.Equal(t, Error, direction)
+		assert.Equal(t, 3, output)
+	})
+}
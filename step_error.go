@@ -0,0 +1,48 @@
+package chain
+
+import (
+	"errors"
+	"fmt"
+)
+
+// StepError is an error a member action returned during a run, naming the action.
+//
+// When multiple actions fail in a single run, such as a fallback planned on Error failing as well,
+// the run returns their StepErrors joined with errors.Join in the order they occurred,
+// so the root cause is kept along with the later ones. errors.Is and errors.As see through both.
+// A run with a single error returns the error without joining.
+type StepError struct {
+	Action string
+	Err    error
+}
+
+func (e *StepError) Error() string {
+	return fmt.Sprintf("`%s`: %s", e.Action, e.Err)
+}
+
+func (e *StepError) Unwrap() error {
+	return e.Err
+}
+
+// joinRunErrors combines the errors of the steps and the error stopping the run, if any.
+// A single error is returned as is, without being wrapped in a StepError.
+func joinRunErrors(stepErrs []*StepError, stopErr error) error {
+	errs := make([]error, 0, len(stepErrs)+1)
+	for _, stepErr := range stepErrs {
+		errs = append(errs, stepErr)
+	}
+	if stopErr != nil {
+		errs = append(errs, stopErr)
+	}
+
+	switch {
+	case len(errs) == 0:
+		return nil
+	case len(errs) > 1:
+		return errors.Join(errs...)
+	case stopErr != nil:
+		return stopErr
+	default:
+		return stepErrs[0].Err
+	}
+}
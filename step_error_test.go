@@ -0,0 +1,76 @@
+package chain
+
+import (
+	"context"
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestPipeline_StepErrors(t *testing.T) {
+	ctx := context.Background()
+	errFetch, errFallback := errors.New("fetch failed"), errors.New("fallback failed")
+
+	t.Run("error then recovery error then success", func(t *testing.T) {
+		fetch := NewSimpleAction("Fetch", func(_ context.Context, input int) (int, error) { return input, errFetch })
+		fallback := NewSimpleAction("Fallback", func(_ context.Context, input int) (int, error) { return input, errFallback })
+		report := &SetTen{}
+		pipeline := NewPipeline("Pipeline", fetch, fallback, report)
+		pipeline.SetRunPlan(fetch, ActionPlan[int]{Error: fallback})
+		pipeline.SetRunPlan(fallback, ActionPlan[int]{Error: report})
+
+		result := pipeline.RunWithTrace(ctx, 1)
+
+		assert.ErrorIs(t, result.Err, errFetch)
+		assert.ErrorIs(t, result.Err, errFallback)
+		assert.EqualError(t, result.Err, "`Fetch`: fetch failed\n`Fallback`: fallback failed")
+		assert.Equal(t, Error, result.Direction)
+		assert.Equal(t, 10, result.Output)
+		assert.Equal(t, []*StepError{
+			{Action: "Fetch", Err: errFetch},
+			{Action: "Fallback", Err: errFallback},
+		}, result.StepErrors)
+	})
+
+	t.Run("error then error", func(t *testing.T) {
+		fetch := NewSimpleAction("Fetch", func(_ context.Context, input int) (int, error) { return input, errFetch })
+		fallback := NewSimpleAction("Fallback", func(_ context.Context, input int) (int, error) { return input, errFallback })
+		pipeline := NewPipeline("Pipeline", fetch, fallback)
+		pipeline.SetRunPlan(fetch, ActionPlan[int]{Error: fallback})
+
+		result := pipeline.RunWithTrace(ctx, 1)
+
+		var stepErr *StepError
+		assert.True(t, errors.As(result.Err, &stepErr))
+		assert.Equal(t, "Fetch", stepErr.Action)
+		assert.ErrorIs(t, result.Err, errFallback)
+		assert.Equal(t, Error, result.Direction)
+		assert.Len(t, result.StepErrors, 2)
+	})
+
+	t.Run("single error is not joined", func(t *testing.T) {
+		fetch := NewSimpleAction("Fetch", func(_ context.Context, input int) (int, error) { return input, errFetch })
+		pipeline := NewPipeline("Pipeline", fetch, &SetTen{})
+
+		result := pipeline.RunWithTrace(ctx, 1)
+
+		assert.Equal(t, errFetch, errors.Unwrap(result.Err))
+		assert.Equal(t, []*StepError{{Action: "Fetch", Err: errFetch}}, result.StepErrors)
+	})
+
+	t.Run("cleared on recovery", func(t *testing.T) {
+		fetch := NewSimpleAction("Fetch", func(_ context.Context, input int) (int, error) { return input, errFetch })
+		fallback := NewSimpleAction("Fallback", func(_ context.Context, input int) (int, error) { return input, errFallback })
+		report := &SetTen{}
+		pipeline := NewPipeline("Pipeline", fetch, fallback, report)
+		pipeline.SetRunPlan(fetch, ActionPlan[int]{Error: fallback})
+		pipeline.SetRunPlan(fallback, ActionPlan[int]{Error: report})
+		assert.NoError(t, pipeline.SetFinalDirectionPolicy(ClearedOnRecovery))
+
+		result := pipeline.RunWithTrace(ctx, 1)
+
+		assert.NoError(t, result.Err)
+		assert.Equal(t, Success, result.Direction)
+		assert.Len(t, result.StepErrors, 2)
+	})
+}
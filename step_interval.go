@@ -0,0 +1,36 @@
+package chain
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// WithStepInterval makes runs wait for d after each action before running the next one,
+// spreading the calls of the actions to downstream services, and returns the pipeline itself
+// for chaining. Runs do not wait after their last action. Cancelling ctx stops the wait,
+// aborting the run as it does before any action. Setting 0 stops waiting.
+// The change takes effect from the next run.
+//
+// If d is negative, a panic will occur.
+func (p *Pipeline[T]) WithStepInterval(d time.Duration) *Pipeline[T] {
+	if d < 0 {
+		panic(errors.New("step interval must not be negative"))
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.stepInterval = d
+	return p
+}
+
+// waitInterval waits for d, or until ctx is done.
+func waitInterval(ctx context.Context, d time.Duration) {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-timer.C:
+	case <-ctx.Done():
+	}
+}
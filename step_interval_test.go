@@ -0,0 +1,42 @@
+package chain
+
+import (
+	"context"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func TestPipeline_WithStepInterval(t *testing.T) {
+	t.Run("waits between actions", func(t *testing.T) {
+		pipeline := NewPipeline("Pipeline", &DirectingAction{name: "action1"}, &DirectingAction{name: "action2"}, &SetTen{}).
+			WithStepInterval(50 * time.Millisecond)
+
+		started := time.Now()
+		output, err := pipeline.Run(context.Background(), 1)
+
+		assert.NoError(t, err)
+		assert.Equal(t, 10, output)
+		elapsed := time.Since(started)
+		assert.GreaterOrEqual(t, elapsed, 100*time.Millisecond)
+		assert.Less(t, elapsed, 150*time.Millisecond)
+	})
+
+	t.Run("aborts on cancellation while waiting", func(t *testing.T) {
+		pipeline := NewPipeline("Pipeline", &DirectingAction{name: "action1"}, &SetTen{}).WithStepInterval(time.Minute)
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+		defer cancel()
+
+		result := pipeline.RunWithTrace(ctx, 1)
+
+		assert.ErrorIs(t, result.Err, context.DeadlineExceeded)
+		assert.Equal(t, Abort, result.Direction)
+		assert.Len(t, result.Trace.Steps, 1)
+	})
+
+	t.Run("panics on negative interval", func(t *testing.T) {
+		pipeline := NewPipeline("Pipeline", &SetTen{})
+
+		assert.PanicsWithError(t, "step interval must not be negative", func() { pipeline.WithStepInterval(-time.Second) })
+	})
+}
@@ -0,0 +1,57 @@
+package chain
+
+import (
+	"errors"
+	"fmt"
+	"github.com/sirupsen/logrus"
+)
+
+// TeeAction taps the output of the action, calling sink with the output each time the action runs,
+// as io.TeeReader does for readers. The output is passed to the next action unchanged,
+// and the tap does not appear on the plans, traces and graphs of the pipeline.
+// Multiple sinks on the same action are called in the order they were added.
+// An error or a panic of sink is logged, without affecting the run.
+// The change takes effect from the next run.
+//
+// An error is returned when action is not a member of the pipeline, or sink is nil.
+func (p *Pipeline[T]) TeeAction(action Action[T], sink func(T) error) error {
+	if action == nil {
+		return errors.New("cannot tee terminate")
+	} else if !isMemberActionInPipeline(action, p) {
+		return fmt.Errorf("`%s` is not a member of this pipeline", action.Name())
+	} else if sink == nil {
+		return fmt.Errorf("cannot tee `%s` to nil sink", action.Name())
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	// Copy on write, so running does not need to hold the lock
+	taps := make(map[Action[T]][]func(T) error, len(p.taps)+1)
+	for tapped, sinks := range p.taps {
+		taps[tapped] = sinks
+	}
+	sinks := taps[action]
+	taps[action] = append(sinks[:len(sinks):len(sinks)], sink)
+	p.taps = taps
+
+	return nil
+}
+
+// tee calls the sinks with the output of the action.
+func tee[T any](sinks []func(T) error, actionName string, output T) {
+	for _, sink := range sinks {
+		if err := callSink(sink, output); err != nil {
+			logrus.Errorf("%s: failed to tee output, caused by %s", actionName, err)
+		}
+	}
+}
+
+func callSink[T any](sink func(T) error, output T) (err error) {
+	defer func() {
+		if panicErr := recover(); panicErr != nil {
+			err = panicToError(panicErr)
+		}
+	}()
+
+	return sink(output)
+}
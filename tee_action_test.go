@@ -0,0 +1,68 @@
+package chain
+
+import (
+	"context"
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestPipeline_TeeAction(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("taps outputs of action", func(t *testing.T) {
+		collatz := NewCollatz("Collatz")
+		var tapped []int
+		assert.NoError(t, collatz.TeeAction(collatz.OnOdd, func(output int) error {
+			tapped = append(tapped, output)
+			return nil
+		}))
+
+		output, err := collatz.Run(ctx, 5)
+		_, _ = collatz.Run(ctx, 6)
+		_, _ = collatz.Run(ctx, 7)
+
+		assert.NoError(t, err)
+		assert.Equal(t, 16, output)
+		assert.Equal(t, []int{16, 22}, tapped)
+	})
+
+	t.Run("does not affect runs", func(t *testing.T) {
+		collatz := NewCollatz("Collatz")
+		expected := collatz.RunWithTrace(ctx, 5)
+		assert.NoError(t, collatz.TeeAction(collatz.CheckNext, func(int) error { return errors.New("sink unavailable") }))
+		assert.NoError(t, collatz.TeeAction(collatz.OnOdd, func(int) error { panic("broken sink") }))
+
+		result := collatz.RunWithTrace(ctx, 5)
+
+		assert.Equal(t, expected.Output, result.Output)
+		assert.Equal(t, expected.Direction, result.Direction)
+		assert.NoError(t, result.Err)
+		assert.Equal(t, expected.Trace, result.Trace)
+	})
+
+	t.Run("calls sinks in order", func(t *testing.T) {
+		pipeline := NewPipeline("Pipeline", &SetTen{})
+		var calls []string
+		for _, name := range []string{"first", "second"} {
+			name := name
+			assert.NoError(t, pipeline.TeeAction(pipeline.members[0], func(int) error {
+				calls = append(calls, name)
+				return nil
+			}))
+		}
+
+		_, _ = pipeline.Run(ctx, 1)
+
+		assert.Equal(t, []string{"first", "second"}, calls)
+	})
+
+	t.Run("fails with invalid arguments", func(t *testing.T) {
+		collatz := NewCollatz("Collatz")
+		sink := func(int) error { return nil }
+
+		assert.EqualError(t, collatz.TeeAction(&SetTen{}, sink), "`SetTen` is not a member of this pipeline")
+		assert.EqualError(t, collatz.TeeAction(Terminate[int](), sink), "cannot tee terminate")
+		assert.EqualError(t, collatz.TeeAction(collatz.OnOdd, nil), "cannot tee `OnOdd` to nil sink")
+	})
+}
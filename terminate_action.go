@@ -0,0 +1,32 @@
+package chain
+
+import (
+	"errors"
+	"fmt"
+)
+
+// SetTerminateAction makes runs call term whenever they would terminate by their plans,
+// for finalizing the work of the run such as closing a transaction.
+// It is as if every plan directing termination directed term instead, and term terminated on any direction.
+// Runs stopped without reaching the end of their plans, such as by cancellation or by
+// an unplanned direction aborting the run, end without calling term.
+//
+// The run ends with the direction that led to termination, so term never hides the direction of
+// a failed run. Errors of term are reported along with the errors of the run.
+// term is not a member, so it does not appear on the plans and graphs of the pipeline.
+// Setting Terminate restores the default termination. The change takes effect from the next run.
+//
+// An error is returned when term is a member of the pipeline.
+func (p *Pipeline[T]) SetTerminateAction(term Action[T]) error {
+	if term != nil && isMemberActionInPipeline(term, p) {
+		return fmt.Errorf("`%s` is a member of this pipeline", term.Name())
+	} else if term == p {
+		return errors.New("cannot set pipeline itself as terminate action")
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.terminateAction = term
+
+	return nil
+}
@@ -0,0 +1,87 @@
+package chain
+
+import (
+	"context"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestPipeline_SetTerminateAction(t *testing.T) {
+	ctx := context.Background()
+	newFinalizer := func(calls *[]int) Action[int] {
+		return NewSimpleAction("Finalizer", func(_ context.Context, input int) (int, error) {
+			*calls = append(*calls, input)
+			return input, nil
+		})
+	}
+
+	t.Run("runs in place of termination", func(t *testing.T) {
+		var calls []int
+		collatz := NewCollatz("Collatz")
+		assert.NoError(t, collatz.SetTerminateAction(newFinalizer(&calls)))
+
+		output, direction, err := collatz.run(ctx, 5)
+
+		assert.NoError(t, err)
+		assert.Equal(t, Success, direction)
+		assert.Equal(t, 16, output)
+		assert.Equal(t, []int{16}, calls)
+	})
+
+	t.Run("keeps direction that led to termination", func(t *testing.T) {
+		var calls []int
+		pipeline := NewPipeline("Pipeline", &ErrorMaker{message: "error1"}, &SetTen{})
+		assert.NoError(t, pipeline.SetTerminateAction(newFinalizer(&calls)))
+
+		output, direction, err := pipeline.run(ctx, 5)
+
+		assert.EqualError(t, err, "error1")
+		assert.Equal(t, Error, direction)
+		assert.Equal(t, 5, output)
+		assert.Equal(t, []int{5}, calls)
+	})
+
+	t.Run("reports errors of terminate action", func(t *testing.T) {
+		pipeline := NewPipeline("Pipeline", &SetTen{})
+		assert.NoError(t, pipeline.SetTerminateAction(&ErrorMaker{message: "not closed"}))
+
+		_, direction, err := pipeline.run(ctx, 5)
+
+		assert.EqualError(t, err, "not closed")
+		assert.Equal(t, Error, direction)
+	})
+
+	t.Run("is not called on cancellation", func(t *testing.T) {
+		var calls []int
+		collatz := NewCollatz("Collatz")
+		assert.NoError(t, collatz.SetTerminateAction(newFinalizer(&calls)))
+		canceled, cancel := context.WithCancel(ctx)
+		cancel()
+
+		_, direction, err := collatz.run(canceled, 5)
+
+		assert.ErrorIs(t, err, context.Canceled)
+		assert.Equal(t, Abort, direction)
+		assert.Empty(t, calls)
+	})
+
+	t.Run("restores default termination", func(t *testing.T) {
+		var calls []int
+		collatz := NewCollatz("Collatz")
+		assert.NoError(t, collatz.SetTerminateAction(newFinalizer(&calls)))
+		assert.NoError(t, collatz.SetTerminateAction(Terminate[int]()))
+
+		_, _, err := collatz.run(ctx, 5)
+
+		assert.NoError(t, err)
+		assert.Empty(t, calls)
+	})
+
+	t.Run("rejects member", func(t *testing.T) {
+		setTen := &SetTen{}
+		pipeline := NewPipeline("Pipeline", setTen)
+
+		assert.EqualError(t, pipeline.SetTerminateAction(setTen), "`SetTen` is a member of this pipeline")
+		assert.Error(t, pipeline.SetTerminateAction(pipeline))
+	})
+}
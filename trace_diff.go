@@ -0,0 +1,154 @@
+package chain
+
+import (
+	"fmt"
+	"strings"
+)
+
+// StepDiffKind tells how a step of a trace compares to the other trace in TraceDiff.
+type StepDiffKind int
+
+const (
+	// StepUnchanged marks a step taken by both traces with the same direction and error.
+	StepUnchanged StepDiffKind = iota
+	// StepChanged marks a step taken by both traces, but with a different direction or error.
+	StepChanged
+	// StepRemoved marks a step only taken by the first trace.
+	StepRemoved
+	// StepAdded marks a step only taken by the second trace.
+	StepAdded
+)
+
+// StepDiff is a single entry of TraceDiff, pairing up the steps of both traces.
+// For StepRemoved, only A is set and IndexB is -1, and for StepAdded, only B is set and IndexA is -1.
+type StepDiff struct {
+	Kind   StepDiffKind
+	A      StepOutcome
+	B      StepOutcome
+	IndexA int
+	IndexB int
+}
+
+// DirectionChanged tells whether both traces took the step, but selected different directions.
+func (d StepDiff) DirectionChanged() bool {
+	return d.Kind == StepChanged && d.A.Direction != d.B.Direction
+}
+
+// ErrorChanged tells whether both traces took the step, but ended with different errors.
+func (d StepDiff) ErrorChanged() bool {
+	return d.Kind == StepChanged && errorMessage(d.A.Err) != errorMessage(d.B.Err)
+}
+
+// TraceDiff explains how two traces of runs diverge, step by step.
+type TraceDiff struct {
+	A     string
+	B     string
+	Steps []StepDiff
+}
+
+// DiffTraces compares two traces, such as a trace recorded in production and its local replay.
+// The steps are aligned by the longest common subsequence of their action names,
+// so the diff reports the steps only one of the traces took, and the steps both traces took
+// but with different directions or errors.
+// Errors are compared by their messages.
+func DiffTraces(a, b RunTrace) TraceDiff {
+	n, m := len(a.Steps), len(b.Steps)
+
+	// lcs[i][j] holds the length of the longest common subsequence of a.Steps[i:] and b.Steps[j:]
+	lcs := make([][]int, n+1)
+	for i := range lcs {
+		lcs[i] = make([]int, m+1)
+	}
+	for i := n - 1; i >= 0; i-- {
+		for j := m - 1; j >= 0; j-- {
+			if a.Steps[i].Action == b.Steps[j].Action {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else if lcs[i+1][j] >= lcs[i][j+1] {
+				lcs[i][j] = lcs[i+1][j]
+			} else {
+				lcs[i][j] = lcs[i][j+1]
+			}
+		}
+	}
+
+	diff := TraceDiff{A: a.Pipeline, B: b.Pipeline}
+	i, j := 0, 0
+	for i < n || j < m {
+		switch {
+		case i < n && j < m && a.Steps[i].Action == b.Steps[j].Action:
+			step := StepDiff{Kind: StepUnchanged, A: a.Steps[i], B: b.Steps[j], IndexA: i, IndexB: j}
+			if a.Steps[i].Direction != b.Steps[j].Direction || errorMessage(a.Steps[i].Err) != errorMessage(b.Steps[j].Err) {
+				step.Kind = StepChanged
+			}
+			diff.Steps = append(diff.Steps, step)
+			i, j = i+1, j+1
+		case j == m || (i < n && lcs[i+1][j] >= lcs[i][j+1]):
+			diff.Steps = append(diff.Steps, StepDiff{Kind: StepRemoved, A: a.Steps[i], IndexA: i, IndexB: -1})
+			i++
+		default:
+			diff.Steps = append(diff.Steps, StepDiff{Kind: StepAdded, B: b.Steps[j], IndexA: -1, IndexB: j})
+			j++
+		}
+	}
+
+	return diff
+}
+
+// Diverged tells whether the traces took any different step.
+func (d TraceDiff) Diverged() bool {
+	for _, step := range d.Steps {
+		if step.Kind != StepUnchanged {
+			return true
+		}
+	}
+	return false
+}
+
+// String renders the diff line by line, in the manner of a unified diff:
+// steps only in the first trace are prefixed with `-`, steps only in the second with `+`,
+// and steps taken differently with `~`.
+func (d TraceDiff) String() string {
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("--- %s\n+++ %s\n", d.A, d.B))
+	for _, step := range d.Steps {
+		switch step.Kind {
+		case StepUnchanged:
+			sb.WriteString("  " + describeStep(step.A) + "\n")
+		case StepRemoved:
+			sb.WriteString("- " + describeStep(step.A) + "\n")
+		case StepAdded:
+			sb.WriteString("+ " + describeStep(step.B) + "\n")
+		case StepChanged:
+			var changes []string
+			if step.DirectionChanged() {
+				changes = append(changes, fmt.Sprintf("direction `%s` -> `%s`", step.A.Direction, step.B.Direction))
+			}
+			if step.ErrorChanged() {
+				changes = append(changes, fmt.Sprintf("error %s -> %s", quoteError(step.A.Err), quoteError(step.B.Err)))
+			}
+			sb.WriteString(fmt.Sprintf("~ `%s`: %s\n", step.A.Action, strings.Join(changes, ", ")))
+		}
+	}
+	return sb.String()
+}
+
+func describeStep(step StepOutcome) string {
+	if step.Err != nil {
+		return fmt.Sprintf("`%s` directs `%s` with error %s", step.Action, step.Direction, quoteError(step.Err))
+	}
+	return fmt.Sprintf("`%s` directs `%s`", step.Action, step.Direction)
+}
+
+func errorMessage(err error) string {
+	if err == nil {
+		return ""
+	}
+	return err.Error()
+}
+
+func quoteError(err error) string {
+	if err == nil {
+		return "<nil>"
+	}
+	return fmt.Sprintf("%q", err.Error())
+}
@@ -0,0 +1,84 @@
+package chain
+
+import (
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"strings"
+	"testing"
+)
+
+func TestDiffTraces(t *testing.T) {
+	t.Run("identical traces", func(t *testing.T) {
+		trace := RunTrace{Pipeline: "Collatz", Steps: []StepOutcome{
+			{Action: "CheckNext", Direction: "odd"},
+			{Action: "OnOdd", Direction: Success},
+		}}
+
+		diff := DiffTraces(trace, trace)
+
+		assert.False(t, diff.Diverged())
+		assert.Len(t, diff.Steps, 2)
+	})
+
+	t.Run("divergent branches", func(t *testing.T) {
+		yesterday := RunTrace{Pipeline: "Checkout", Steps: []StepOutcome{
+			{Action: "Validate", Direction: Success},
+			{Action: "ChargeCard", Direction: Error, Err: errors.New("card declined")},
+			{Action: "Refund", Direction: Success},
+			{Action: "Notify", Direction: Success},
+		}}
+		today := RunTrace{Pipeline: "Checkout", Steps: []StepOutcome{
+			{Action: "Validate", Direction: Success},
+			{Action: "ChargeCard", Direction: Success},
+			{Action: "Ship", Direction: Success},
+			{Action: "Notify", Direction: Success},
+		}}
+
+		diff := DiffTraces(yesterday, today)
+
+		assert.True(t, diff.Diverged())
+		kinds := make([]StepDiffKind, len(diff.Steps))
+		for i, step := range diff.Steps {
+			kinds[i] = step.Kind
+		}
+		assert.Equal(t, []StepDiffKind{StepUnchanged, StepChanged, StepRemoved, StepAdded, StepUnchanged}, kinds)
+		assert.True(t, diff.Steps[1].DirectionChanged())
+		assert.True(t, diff.Steps[1].ErrorChanged())
+		assert.Equal(t, 2, diff.Steps[2].IndexA)
+		assert.Equal(t, -1, diff.Steps[2].IndexB)
+		assert.Equal(t, -1, diff.Steps[3].IndexA)
+		assert.Equal(t, 2, diff.Steps[3].IndexB)
+
+		expected := strings.Join([]string{
+			"--- Checkout",
+			"+++ Checkout",
+			"  `Validate` directs `success`",
+			"~ `ChargeCard`: direction `error` -> `success`, error \"card declined\" -> <nil>",
+			"- `Refund` directs `success`",
+			"+ `Ship` directs `success`",
+			"  `Notify` directs `success`",
+			"",
+		}, "\n")
+		assert.Equal(t, expected, diff.String())
+	})
+
+	t.Run("only errors differ", func(t *testing.T) {
+		a := RunTrace{Steps: []StepOutcome{{Action: "Fetch", Direction: Error, Err: errors.New("timeout")}}}
+		b := RunTrace{Steps: []StepOutcome{{Action: "Fetch", Direction: Error, Err: errors.New("connection refused")}}}
+
+		diff := DiffTraces(a, b)
+
+		assert.Equal(t, StepChanged, diff.Steps[0].Kind)
+		assert.False(t, diff.Steps[0].DirectionChanged())
+		assert.True(t, diff.Steps[0].ErrorChanged())
+	})
+
+	t.Run("missing tail and empty trace", func(t *testing.T) {
+		a := RunTrace{Steps: []StepOutcome{{Action: "A", Direction: Success}, {Action: "B", Direction: Success}}}
+
+		diff := DiffTraces(a, RunTrace{})
+
+		assert.Equal(t, StepRemoved, diff.Steps[0].Kind)
+		assert.Equal(t, StepRemoved, diff.Steps[1].Kind)
+	})
+}
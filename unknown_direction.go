@@ -0,0 +1,60 @@
+package chain
+
+import "fmt"
+
+// ErrUnknownDirection is the error of a run, when an action directs a direction
+// which the plan of the action has no entry for.
+// Pipeline is the name of the pipeline, prefixed by the names of its parents when nested.
+type ErrUnknownDirection struct {
+	Pipeline  string
+	Action    string
+	Direction string
+}
+
+func (e *ErrUnknownDirection) Error() string {
+	return fmt.Sprintf("no action plan from `%s` directing `%s`", e.Action, e.Direction)
+}
+
+// UnknownDirectionPolicy decides how a run handles an action directing
+// a direction which its plan has no entry for.
+type UnknownDirectionPolicy int
+
+const (
+	// UnknownDirectionAbort aborts the run with an *ErrUnknownDirection. This is the default policy.
+	UnknownDirectionAbort UnknownDirectionPolicy = iota
+	// UnknownDirectionTerminate terminates the run gracefully, as if the direction was planned
+	// to terminate, so the run ends with the direction. The *ErrUnknownDirection is only logged.
+	UnknownDirectionTerminate
+	// UnknownDirectionPanic panics with the *ErrUnknownDirection, to make tests fail loudly.
+	// Panics of member pipelines are recovered by their parents, aborting the parents.
+	UnknownDirectionPanic
+)
+
+func (u UnknownDirectionPolicy) String() string {
+	switch u {
+	case UnknownDirectionAbort:
+		return "UnknownDirectionAbort"
+	case UnknownDirectionTerminate:
+		return "UnknownDirectionTerminate"
+	case UnknownDirectionPanic:
+		return "UnknownDirectionPanic"
+	default:
+		return fmt.Sprintf("UnknownDirectionPolicy(%d)", int(u))
+	}
+}
+
+// SetUnknownDirectionPolicy changes how runs handle directions without plans,
+// which is UnknownDirectionAbort by default. The change takes effect from the next run.
+//
+// An error is returned for an unknown policy.
+func (p *Pipeline[T]) SetUnknownDirectionPolicy(policy UnknownDirectionPolicy) error {
+	if policy < UnknownDirectionAbort || policy > UnknownDirectionPanic {
+		return fmt.Errorf("unknown direction policy %s", policy)
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.unknownDirectionPolicy = policy
+
+	return nil
+}
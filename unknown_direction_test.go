@@ -0,0 +1,81 @@
+package chain
+
+import (
+	"context"
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestPipeline_SetUnknownDirectionPolicy(t *testing.T) {
+	ctx := context.Background()
+	newPipeline := func() *Pipeline[int] {
+		return NewPipeline("Pipeline", &StrayAction{name: "Stray", direction: "sideways"}, &SetTen{})
+	}
+
+	t.Run("aborts by default", func(t *testing.T) {
+		pipeline := newPipeline()
+
+		result := pipeline.RunWithTrace(ctx, 1)
+
+		var unknownErr *ErrUnknownDirection
+		assert.True(t, errors.As(result.Err, &unknownErr))
+		assert.Equal(t, ErrUnknownDirection{Pipeline: "Pipeline", Action: "Stray", Direction: "sideways"}, *unknownErr)
+		assert.EqualError(t, result.Err, "no action plan from `Stray` directing `sideways`")
+		assert.Equal(t, Abort, result.Direction)
+		assert.Equal(t, 1, result.Output)
+		assert.Equal(t, []StepOutcome{{Action: "Stray", Direction: "sideways"}}, result.Trace.Steps)
+	})
+
+	t.Run("terminates keeping direction", func(t *testing.T) {
+		pipeline := newPipeline()
+		assert.NoError(t, pipeline.SetUnknownDirectionPolicy(UnknownDirectionTerminate))
+
+		result := pipeline.RunWithTrace(ctx, 1)
+
+		assert.NoError(t, result.Err)
+		assert.Equal(t, "sideways", result.Direction)
+		assert.Equal(t, 1, result.Output)
+		assert.Equal(t, []StepOutcome{{Action: "Stray", Direction: "sideways"}}, result.Trace.Steps)
+	})
+
+	t.Run("panics", func(t *testing.T) {
+		pipeline := newPipeline()
+		assert.NoError(t, pipeline.SetUnknownDirectionPolicy(UnknownDirectionPanic))
+
+		assert.PanicsWithError(t, "no action plan from `Stray` directing `sideways`", func() {
+			_, _ = pipeline.Run(ctx, 1)
+		})
+	})
+
+	t.Run("nested pipeline reports full name", func(t *testing.T) {
+		outer := NewPipeline("Outer", newPipeline())
+
+		_, err := outer.Run(ctx, 1)
+
+		var unknownErr *ErrUnknownDirection
+		assert.True(t, errors.As(err, &unknownErr))
+		assert.Equal(t, "Outer/Pipeline", unknownErr.Pipeline)
+	})
+
+	t.Run("rejects unknown policy", func(t *testing.T) {
+		pipeline := newPipeline()
+
+		assert.EqualError(t, pipeline.SetUnknownDirectionPolicy(UnknownDirectionPolicy(7)), "unknown direction policy UnknownDirectionPolicy(7)")
+	})
+}
+
+// StrayAction directs a direction it does not declare, so no plan can have an entry for it.
+type StrayAction struct {
+	name      string
+	direction string
+}
+
+func (s StrayAction) Name() string       { return s.name }
+func (StrayAction) Directions() []string { return nil }
+func (StrayAction) Run(_ context.Context, input int) (int, error) {
+	return input, nil
+}
+func (s StrayAction) NextDirection(_ context.Context, _ int) (string, error) {
+	return s.direction, nil
+}
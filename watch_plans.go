@@ -0,0 +1,158 @@
+package chain
+
+import (
+	"context"
+	"github.com/sirupsen/logrus"
+	"os"
+	"time"
+)
+
+// WatchOption customizes how WatchPlans watches the plan spec.
+type WatchOption func(*watchConfig)
+
+type watchConfig struct {
+	interval time.Duration
+	onReload func(version string)
+	onError  func(err error)
+}
+
+// WatchInterval sets how often the plan spec file is checked for changes, which is a second by default.
+func WatchInterval(interval time.Duration) WatchOption {
+	return func(c *watchConfig) { c.interval = interval }
+}
+
+// OnPlansReloaded registers a callback called with the version of the spec, whenever it is applied.
+func OnPlansReloaded(callback func(version string)) WatchOption {
+	return func(c *watchConfig) { c.onReload = callback }
+}
+
+// OnReloadError registers a callback called with the error, whenever a changed spec is rejected.
+func OnReloadError(callback func(err error)) WatchOption {
+	return func(c *watchConfig) { c.onError = callback }
+}
+
+// WatchPlans applies the PlanSpec in the YAML file at path to the pipeline,
+// and keeps applying it whenever the file changes, until ctx is done.
+// Changes are detected by polling the modification time and the size of the file.
+//
+// An invalid spec on a change is rejected with a logged error, and the pipeline keeps running
+// with its current plans until a valid spec replaces it. The spec on the start must be valid,
+// otherwise the error is returned without watching.
+func WatchPlans[T any](ctx context.Context, p *Pipeline[T], path string, opts ...WatchOption) error {
+	config := watchConfig{interval: time.Second}
+	for _, opt := range opts {
+		opt(&config)
+	}
+
+	info, err := os.Stat(path)
+	if err != nil {
+		return err
+	}
+	if err = reloadPlans(p, path, config); err != nil {
+		return err
+	}
+
+	go func() {
+		ticker := time.NewTicker(config.interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+
+			changed, statErr := os.Stat(path)
+			if statErr != nil {
+				logrus.Errorf("%s: cannot check plan spec %s, caused by %s", p.Name(), path, statErr)
+				continue
+			}
+			if changed.ModTime().Equal(info.ModTime()) && changed.Size() == info.Size() {
+				continue
+			}
+			info = changed
+
+			if reloadErr := reloadPlans(p, path, config); reloadErr != nil {
+				logrus.Errorf("%s: rejected plan spec %s, caused by %s", p.Name(), path, reloadErr)
+				if config.onError != nil {
+					config.onError(reloadErr)
+				}
+			}
+		}
+	}()
+
+	return nil
+}
+
+func reloadPlans[T any](p *Pipeline[T], path string, config watchConfig) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	spec, err := ParsePlanSpec(data)
+	if err != nil {
+		return err
+	}
+	if err = ApplyPlanSpec(p, spec); err != nil {
+		return err
+	}
+
+	logrus.Debugf("%s: Reloaded plans of version `%s` from %s", p.Name(), spec.Version, path)
+	if config.onReload != nil {
+		config.onReload(spec.Version)
+	}
+	return nil
+}
+
+// ConfigSource delivers PlanSpecs to LiveReload, such as from a configuration service.
+// Watch delivers a spec whenever the configuration changes, until ctx is done or the channel is closed.
+type ConfigSource interface {
+	Watch(ctx context.Context) (<-chan PlanSpec, error)
+}
+
+// LiveReload applies the PlanSpecs delivered by source to the pipeline on a new goroutine,
+// until ctx is done or source closes the channel. Each spec is applied at once as ApplyPlanSpec does,
+// and an invalid spec is rejected with a logged error, keeping the current plans.
+// WatchInterval does not apply, as source decides when the specs are delivered.
+//
+// An error is returned without watching when source fails to start watching.
+func (p *Pipeline[T]) LiveReload(ctx context.Context, source ConfigSource, opts ...WatchOption) error {
+	config := watchConfig{}
+	for _, opt := range opts {
+		opt(&config)
+	}
+
+	specs, err := source.Watch(ctx)
+	if err != nil {
+		return err
+	}
+
+	go func() {
+		for {
+			var spec PlanSpec
+			var open bool
+			select {
+			case <-ctx.Done():
+				return
+			case spec, open = <-specs:
+				if !open {
+					return
+				}
+			}
+
+			if applyErr := ApplyPlanSpec(p, spec); applyErr != nil {
+				logrus.Errorf("%s: rejected plan spec of version `%s`, caused by %s", p.Name(), spec.Version, applyErr)
+				if config.onError != nil {
+					config.onError(applyErr)
+				}
+				continue
+			}
+			logrus.Debugf("%s: Reloaded plans of version `%s`", p.Name(), spec.Version)
+			if config.onReload != nil {
+				config.onReload(spec.Version)
+			}
+		}
+	}()
+
+	return nil
+}
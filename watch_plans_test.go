@@ -0,0 +1,173 @@
+package chain
+
+import (
+	"context"
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestParsePlanSpec(t *testing.T) {
+	spec, err := ParsePlanSpec([]byte("version: \"2\"\nplans:\n  CheckNext:\n    even: OnEven\n    odd: \"\"\n"))
+	assert.NoError(t, err)
+	assert.Equal(t, PlanSpec{
+		Version: "2",
+		Plans:   map[string]map[string]string{"CheckNext": {"even": "OnEven", "odd": ""}},
+	}, spec)
+
+	_, err = ParsePlanSpec([]byte("version: 1\nplan: {}\n"))
+	assert.ErrorContains(t, err, "field plan not found")
+
+	_, err = ParsePlanSpec([]byte("version: 1\n"))
+	assert.EqualError(t, err, "invalid plan spec: no plans were described")
+}
+
+func TestPipeline_SetPlanMatrix(t *testing.T) {
+	t.Run("applies plans of rows", func(t *testing.T) {
+		collatz := NewCollatz("Collatz")
+
+		err := collatz.SetPlanMatrix([][]interface{}{
+			{"", "even", "odd", Success},
+			{"CheckNext", "OnOdd", nil, ""},
+			{"OnOdd", nil, nil, "OnEven"},
+		})
+
+		assert.NoError(t, err)
+		plans := collatz.Snapshot().Plans
+		assert.Equal(t, map[string]string{Success: "", Error: "", Abort: "", "even": "OnOdd", "odd": ""}, plans["CheckNext"])
+		assert.Equal(t, map[string]string{Success: "OnEven", Error: "", Abort: ""}, plans["OnOdd"])
+		assert.Equal(t, map[string]string{Success: "", Error: "", Abort: ""}, plans["OnEven"])
+	})
+
+	t.Run("rejects invalid matrices", func(t *testing.T) {
+		collatz := NewCollatz("Collatz")
+		before := collatz.Snapshot()
+
+		testCases := map[string]struct {
+			matrix   [][]interface{}
+			expected string
+		}{
+			"no rows":          {matrix: nil, expected: "plan matrix must have a row of directions"},
+			"empty direction":  {matrix: [][]interface{}{{"", ""}}, expected: "direction of column 1 must be a non-empty string"},
+			"long row":         {matrix: [][]interface{}{{"", "even"}, {"CheckNext", "OnEven", "OnOdd"}}, expected: "row 1 has more cells than directions"},
+			"unnamed row":      {matrix: [][]interface{}{{"", "even"}, {1, "OnEven"}}, expected: "row 1 must start with the name of an action"},
+			"duplicate row":    {matrix: [][]interface{}{{"", "even"}, {"CheckNext"}, {"CheckNext"}}, expected: "plan of `CheckNext` is described multiple times"},
+			"non-string cell":  {matrix: [][]interface{}{{"", "even"}, {"CheckNext", 1}}, expected: "cell at row 1, column 1 must be the name of an action"},
+			"unknown action":   {matrix: [][]interface{}{{"", "even"}, {"Unknown"}}, expected: "`Unknown` is not a member of this pipeline"},
+			"unsupported cell": {matrix: [][]interface{}{{"", "even"}, {"OnOdd", ""}}, expected: "`OnOdd` does not support direction `even`"},
+		}
+		for name, tc := range testCases {
+			t.Run(name, func(t *testing.T) {
+				assert.EqualError(t, collatz.SetPlanMatrix(tc.matrix), tc.expected)
+				assert.Equal(t, before, collatz.Snapshot())
+			})
+		}
+	})
+}
+
+func TestWatchPlans(t *testing.T) {
+	collatz := NewCollatz("Collatz")
+	path := filepath.Join(t.TempDir(), "plans.yaml")
+	modified := time.Now()
+	writeSpec := func(content string) {
+		// Replace the file at once, so the watcher never reads it partially written
+		modified = modified.Add(time.Second)
+		assert.NoError(t, os.WriteFile(path+".tmp", []byte(content), 0o644))
+		assert.NoError(t, os.Chtimes(path+".tmp", modified, modified))
+		assert.NoError(t, os.Rename(path+".tmp", path))
+	}
+	writeSpec("version: v1\nplans:\n  CheckNext:\n    even: OnEven\n    odd: OnOdd\n")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	reloaded, rejected := make(chan string, 3), make(chan error, 3)
+	err := WatchPlans(ctx, collatz.Pipeline, path,
+		WatchInterval(time.Millisecond),
+		OnPlansReloaded(func(version string) { reloaded <- version }),
+		OnReloadError(func(err error) { rejected <- err }))
+	assert.NoError(t, err)
+	assert.Equal(t, "v1", <-reloaded)
+
+	// A bad intermediate file keeps the current plans
+	writeSpec("version: v2\nplans:\n  CheckNext:\n    even: Unknown\n")
+	assert.EqualError(t, <-rejected, "setting plan from `CheckNext` directing `even` to non-member `Unknown`")
+	output, err := collatz.Run(ctx, 4)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, output)
+
+	writeSpec("version: v3\nplans:\n  CheckNext:\n    even: OnOdd\n    odd: OnOdd\n")
+	assert.Equal(t, "v3", <-reloaded)
+	output, err = collatz.Run(ctx, 4)
+	assert.NoError(t, err)
+	assert.Equal(t, 13, output)
+	assert.Empty(t, rejected)
+
+	t.Run("invalid spec on start", func(t *testing.T) {
+		invalid := filepath.Join(t.TempDir(), "plans.yaml")
+		assert.NoError(t, os.WriteFile(invalid, []byte("plans: [\n"), 0o644))
+
+		err := WatchPlans(context.Background(), collatz.Pipeline, invalid)
+
+		assert.ErrorContains(t, err, "invalid plan spec")
+	})
+}
+
+func TestPipeline_LiveReload(t *testing.T) {
+	t.Run("applies delivered specs", func(t *testing.T) {
+		collatz := NewCollatz("Collatz")
+		source := channelSource(make(chan PlanSpec))
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		reloaded, rejected := make(chan string, 2), make(chan error, 2)
+
+		err := collatz.LiveReload(ctx, source,
+			OnPlansReloaded(func(version string) { reloaded <- version }),
+			OnReloadError(func(err error) { rejected <- err }))
+		assert.NoError(t, err)
+
+		source <- PlanSpec{Version: "v1", Plans: map[string]map[string]string{"CheckNext": {"even": "Unknown"}}}
+		assert.EqualError(t, <-rejected, "setting plan from `CheckNext` directing `even` to non-member `Unknown`")
+
+		source <- PlanSpec{Version: "v2", Plans: map[string]map[string]string{"CheckNext": {"even": "OnOdd", "odd": "OnOdd"}}}
+		assert.Equal(t, "v2", <-reloaded)
+		output, err := collatz.Run(ctx, 4)
+		assert.NoError(t, err)
+		assert.Equal(t, 13, output)
+	})
+
+	t.Run("stops on cancellation", func(t *testing.T) {
+		collatz := NewCollatz("Collatz")
+		source := channelSource(make(chan PlanSpec))
+		ctx, cancel := context.WithCancel(context.Background())
+		assert.NoError(t, collatz.LiveReload(ctx, source))
+
+		cancel()
+		time.Sleep(10 * time.Millisecond)
+
+		select {
+		case source <- PlanSpec{Version: "v1"}:
+			t.Fatal("spec was received after cancellation")
+		case <-time.After(50 * time.Millisecond):
+		}
+	})
+
+	t.Run("fails on watching", func(t *testing.T) {
+		collatz := NewCollatz("Collatz")
+
+		err := collatz.LiveReload(context.Background(), channelSource(nil))
+
+		assert.EqualError(t, err, "source is not available")
+	})
+}
+
+type channelSource chan PlanSpec
+
+func (s channelSource) Watch(context.Context) (<-chan PlanSpec, error) {
+	if s == nil {
+		return nil, errors.New("source is not available")
+	}
+	return s, nil
+}